@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/bhyago/crud-products-go/configs"
 	_ "github.com/bhyago/crud-products-go/docs"
 	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/analytics"
 	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/bhyago/crud-products-go/internal/infra/jobs"
+	"github.com/bhyago/crud-products-go/internal/infra/notify"
+	"github.com/bhyago/crud-products-go/internal/infra/recommend"
+	"github.com/bhyago/crud-products-go/internal/infra/revocation"
 	"github.com/bhyago/crud-products-go/internal/infra/webserver/handlers"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -43,16 +52,115 @@ func main() {
 		panic(err)
 	}
 
-	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open("test.db?_busy_timeout=5000&_txlock=immediate"), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	if err := database.NewTax(db).RemoveDuplicateRates(); err != nil {
+		panic(err)
+	}
+	if err := database.NewUser(db).CheckDuplicateEmails(); err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(&entity.Product{}, &entity.User{}, &entity.ProductTranslation{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.Promotion{}, &entity.Coupon{}, &entity.CouponRedemption{}, &entity.TaxClass{}, &entity.TaxRate{}, &entity.Cart{}, &entity.CartLine{}, &entity.Order{}, &entity.OrderLine{}, &entity.Wishlist{}, &entity.WishlistItem{}, &entity.Review{}, &entity.Supplier{}, &entity.ProductSupplier{}, &entity.PurchaseOrder{}, &entity.PurchaseOrderLine{}, &entity.Warehouse{}, &entity.StockLevel{}, &entity.StockTransfer{}, &entity.StockAlert{}, &entity.ProductViewDaily{}, &entity.ProductMerge{}, &entity.ProductRedirect{}, &entity.ProductTemplate{}, &entity.ShippingZone{}, &entity.ShippingMethod{}, &entity.ShippingRate{}, &entity.RefreshToken{}, &entity.RevokedToken{}, &entity.SessionRevocation{})
 	if err != nil {
 		panic(err)
 	}
-	db.AutoMigrate(&entity.Product{}, &entity.User{})
 	productDB := database.NewProduct(db)
-	ProductHandle := handlers.NewProductHandle(productDB)
+	if configs.BundleDeletePolicy != "" {
+		productDB.BundlePolicy = configs.BundleDeletePolicy
+	}
+	if err := productDB.Backfill(); err != nil {
+		panic(err)
+	}
+	products, err := productDB.FindAll(0, 0, "asc")
+	if err != nil {
+		panic(err)
+	}
+	similarIndex := recommend.NewSimilarIndex()
+	similarIndex.Load(products)
+	productDB.Index = similarIndex
+	translationDB := database.NewProductTranslation(db)
+	promotionDB := database.NewPromotion(db)
+	ProductHandle := handlers.NewProductHandle(productDB, translationDB, promotionDB, configs.DefaultLocale)
+	templateDB := database.NewProductTemplate(db)
+	ProductHandle.TemplateDB = templateDB
+	ProductHandle.Similar = similarIndex
+	productTemplateHandle := handlers.NewProductTemplateHandle(templateDB)
+	if configs.DuplicateThreshold > 0 {
+		ProductHandle.DuplicateThreshold = configs.DuplicateThreshold
+	}
+	viewDB := database.NewProductView(db)
+	ProductHandle.Views = analytics.NewViewCounter(viewDB, configs.ViewFlushInterval, configs.ViewDedupWindow)
+	go ProductHandle.Views.Run(context.Background())
+	productViewHandle := handlers.NewProductViewHandle(viewDB, productDB)
+	translationHandle := handlers.NewTranslationHandle(productDB, translationDB, configs.SupportedLocales, configs.DefaultLocale)
+	promotionHandle := handlers.NewPromotionHandle(promotionDB)
+	couponHandle := handlers.NewCouponHandle(database.NewCoupon(db), productDB, promotionDB)
+	taxDB := database.NewTax(db)
+	if configs.TaxRatesFile != "" {
+		file, err := os.Open(configs.TaxRatesFile)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := taxDB.ImportRatesCSV(file); err != nil {
+			panic(err)
+		}
+		file.Close()
+	}
+	taxHandle := handlers.NewTaxHandle(taxDB, productDB, promotionDB, configs.TaxPricesIncludeTax)
+
+	cartDB := database.NewCart(db)
+	cartHandle := handlers.NewCartHandle(cartDB, productDB, promotionDB, configs.CartTTL)
+	go jobs.NewCartExpiry(cartDB, configs.CartTTL, configs.CartSweepInterval).Run(context.Background())
 
 	userDB := database.NewUser(db)
-	userHandle := handlers.NewUserHandle(userDB, configs.JWTExpiresIn)
+	userHandle := handlers.NewUserHandle(userDB, cartDB, configs.JWTExpiresIn)
+	promoted, err := userDB.PromoteFirstAdmin(configs.BootstrapAdminEmail)
+	if err != nil {
+		panic(err)
+	}
+	if promoted {
+		log.Printf("promoted %s to admin", configs.BootstrapAdminEmail)
+	}
+	userHandle.RefreshTokenDB = database.NewRefreshToken(db)
+	if configs.RefreshTokenTTL > 0 {
+		userHandle.RefreshTokenTTL = configs.RefreshTokenTTL
+	}
+	if configs.RefreshTokenIdleTTL > 0 {
+		userHandle.RefreshTokenIdleTTL = configs.RefreshTokenIdleTTL
+	}
+	denylist := revocation.NewDenylist(database.NewTokenRevocation(db), time.Duration(configs.JWTExpiresIn)*time.Second, configs.DenylistInterval)
+	if err := denylist.Refresh(); err != nil {
+		panic(err)
+	}
+	go denylist.Run(context.Background())
+	userHandle.Denylist = denylist
+	orderHandle := handlers.NewOrderHandle(database.NewOrder(db), promotionDB)
+	wishlistHandle := handlers.NewWishlistHandle(database.NewWishlist(db), productDB)
+	reviewHandle := handlers.NewReviewHandle(database.NewReview(db), productDB)
+	supplierDB := database.NewSupplier(db)
+	supplierHandle := handlers.NewSupplierHandle(supplierDB, productDB)
+	shippingHandle := handlers.NewShippingHandle(database.NewShipping(db), productDB)
+	purchaseOrderHandle := handlers.NewPurchaseOrderHandle(database.NewPurchaseOrder(db), supplierDB, productDB)
+	warehouseHandle := handlers.NewWarehouseHandle(database.NewWarehouse(db), productDB)
+
+	alertDB := database.NewStockAlert(db)
+	alertHandle := handlers.NewStockAlertHandle(alertDB, productDB)
+	var alertLog io.Writer
+	if configs.AlertLogFile != "" {
+		file, err := os.OpenFile(configs.AlertLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+		alertLog = file
+	}
+	notifiers := []notify.Notifier{notify.NewLog(alertLog)}
+	if configs.AlertWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewWebhook(configs.AlertWebhookURL))
+	}
+	go jobs.NewStockAlerts(alertDB, configs.AlertInterval, notifiers...).Run(context.Background())
 
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
@@ -61,19 +169,252 @@ func main() {
 	router.Use(middleware.WithValue("jwt", configs.TokenAuthKey))
 	router.Use(middleware.WithValue("jwtExpiresIn", configs.JWTExpiresIn))
 
+	// Viewers may only read the catalog and editors change it. Purchasing and
+	// warehouse data (suppliers, purchase orders, margins, stock alerts,
+	// transfers and stock levels) is for editors only. The routes of customers,
+	// such as reviews, carts and orders, are open to every role.
+	editor := handlers.RequireRole(entity.RoleEditor)
+
 	router.Route("/products", func(r chi.Router) {
 		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
 		r.Use(jwtauth.Authenticator)
 
-		r.Post("/", ProductHandle.CreateProduct)
+		r.With(editor).Post("/", ProductHandle.CreateProduct)
 		r.Get("/{id}", ProductHandle.GetProduct)
+		r.Get("/by-slug/{slug}", ProductHandle.GetProductBySlug)
+		r.Get("/by-gtin/{code}", ProductHandle.GetProductByGTIN)
+		r.Get("/{id}/barcode.png", ProductHandle.GetProductBarcodePNG)
+		r.Get("/{id}/barcode.svg", ProductHandle.GetProductBarcodeSVG)
+		r.Get("/popular", productViewHandle.GetPopularProducts)
+		r.Get("/duplicates", ProductHandle.GetDuplicates)
+		r.Get("/{id}/similar", ProductHandle.GetSimilarProducts)
+		r.With(editor).Post("/{id}/clone", ProductHandle.CloneProduct)
+		r.With(editor).Post("/{id}/merge", ProductHandle.MergeProduct)
+		r.Get("/{id}/merges", ProductHandle.GetProductMerges)
+		r.Get("/{id}/stats", productViewHandle.GetProductStats)
 		r.Get("/", ProductHandle.GetProducts)
-		r.Put("/{id}", ProductHandle.UpdateProduct)
-		r.Delete("/{id}", ProductHandle.DeleteProduct)
+		r.With(editor).Put("/{id}", ProductHandle.UpdateProduct)
+		r.With(editor).Delete("/{id}", ProductHandle.DeleteProduct)
+
+		r.Get("/translations/missing", translationHandle.GetMissingTranslations)
+		r.Get("/{id}/translations", translationHandle.GetTranslations)
+		r.With(editor).Put("/{id}/translations/{locale}", translationHandle.PutTranslation)
+		r.With(editor).Delete("/{id}/translations/{locale}", translationHandle.DeleteTranslation)
+
+		r.With(editor).Put("/{id}/tax_class", taxHandle.AssignTaxClass)
+		r.Get("/{id}/price", taxHandle.GetProductPrice)
+
+		r.Post("/{id}/reviews", reviewHandle.CreateReview)
+		r.Get("/{id}/reviews", reviewHandle.GetReviews)
+		r.Put("/{id}/reviews/{review_id}", reviewHandle.UpdateReview)
+		r.Delete("/{id}/reviews/{review_id}", reviewHandle.DeleteReview)
+
+		r.With(editor).Get("/{id}/suppliers", supplierHandle.GetProductSuppliers)
+		r.With(editor).Put("/{id}/suppliers/{supplier_id}", supplierHandle.PutProductSupplier)
+		r.With(editor).Delete("/{id}/suppliers/{supplier_id}", supplierHandle.DeleteProductSupplier)
+
+		r.Get("/{id}/availability", warehouseHandle.GetProductAvailability)
+		r.With(editor).Put("/{id}/reorder_point", alertHandle.SetReorderPoint)
+	})
+
+	router.Route("/alerts", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Get("/", alertHandle.GetAlerts)
+		r.With(editor).Get("/{id}", alertHandle.GetAlert)
+		r.With(editor).Post("/{id}/acknowledge", alertHandle.AcknowledgeAlert)
+	})
+
+	router.Route("/warehouses", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/", warehouseHandle.CreateWarehouse)
+		r.Get("/", warehouseHandle.GetWarehouses)
+		r.Get("/{id}", warehouseHandle.GetWarehouse)
+		r.With(editor).Put("/{id}", warehouseHandle.UpdateWarehouse)
+		r.With(editor).Delete("/{id}", warehouseHandle.DeleteWarehouse)
+		r.With(editor).Get("/{id}/stock", warehouseHandle.GetWarehouseStock)
+		r.With(editor).Post("/{id}/stock", warehouseHandle.AdjustWarehouseStock)
+	})
+
+	router.Route("/transfers", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/", warehouseHandle.CreateTransfer)
+		r.With(editor).Get("/", warehouseHandle.GetTransfers)
+		r.With(editor).Get("/{id}", warehouseHandle.GetTransfer)
+		r.With(editor).Post("/{id}/receive", warehouseHandle.ReceiveTransfer)
+		r.With(editor).Post("/{id}/cancel", warehouseHandle.CancelTransfer)
+	})
+
+	router.Route("/product-templates", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/", productTemplateHandle.CreateProductTemplate)
+		r.Get("/", productTemplateHandle.GetProductTemplates)
+		r.Get("/{id}", productTemplateHandle.GetProductTemplate)
+		r.With(editor).Put("/{id}", productTemplateHandle.UpdateProductTemplate)
+		r.With(editor).Delete("/{id}", productTemplateHandle.DeleteProductTemplate)
 	})
 
+	router.Route("/shipping", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/zones", shippingHandle.CreateShippingZone)
+		r.Get("/zones", shippingHandle.GetShippingZones)
+		r.Get("/zones/{id}", shippingHandle.GetShippingZone)
+		r.With(editor).Put("/zones/{id}", shippingHandle.UpdateShippingZone)
+		r.With(editor).Delete("/zones/{id}", shippingHandle.DeleteShippingZone)
+		r.Post("/quote", shippingHandle.QuoteShipping)
+	})
+
+	router.Route("/suppliers", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/", supplierHandle.CreateSupplier)
+		r.With(editor).Get("/", supplierHandle.GetSuppliers)
+		r.With(editor).Get("/{id}", supplierHandle.GetSupplier)
+		r.With(editor).Put("/{id}", supplierHandle.UpdateSupplier)
+		r.With(editor).Delete("/{id}", supplierHandle.DeleteSupplier)
+		r.With(editor).Get("/{id}/products", supplierHandle.GetSupplierProducts)
+	})
+
+	router.Route("/purchase-orders", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/", purchaseOrderHandle.CreatePurchaseOrder)
+		r.With(editor).Get("/", purchaseOrderHandle.GetPurchaseOrders)
+		r.With(editor).Get("/{id}", purchaseOrderHandle.GetPurchaseOrder)
+		r.With(editor).Put("/{id}", purchaseOrderHandle.UpdatePurchaseOrder)
+		r.With(editor).Post("/{id}/send", purchaseOrderHandle.SendPurchaseOrder)
+		r.With(editor).Post("/{id}/receive", purchaseOrderHandle.ReceivePurchaseOrder)
+	})
+
+	router.Route("/reports", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Get("/margins", supplierHandle.GetMargins)
+		r.Get("/catalog", ProductHandle.GetCatalogReport)
+	})
+
+	router.Route("/tax", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/classes", taxHandle.CreateTaxClass)
+		r.Get("/classes", taxHandle.GetTaxClasses)
+		r.With(editor).Post("/rates", taxHandle.CreateTaxRate)
+		r.Get("/rates", taxHandle.GetTaxRates)
+		r.With(editor).Post("/rates/import", taxHandle.ImportTaxRates)
+	})
+
+	router.Route("/promotions", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/", promotionHandle.CreatePromotion)
+		r.Get("/", promotionHandle.GetPromotions)
+		r.Get("/{id}", promotionHandle.GetPromotion)
+		r.With(editor).Put("/{id}", promotionHandle.UpdatePromotion)
+		r.With(editor).Delete("/{id}", promotionHandle.DeletePromotion)
+	})
+
+	router.Route("/coupons", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.With(editor).Post("/", couponHandle.GenerateCoupons)
+		r.With(editor).Get("/", couponHandle.GetCoupons)
+		r.Post("/{code}/validate", couponHandle.ValidateCoupon)
+		r.Post("/{code}/redeem", couponHandle.RedeemCoupon)
+	})
+
+	router.Route("/cart", func(r chi.Router) {
+		// The cart is open to anonymous visitors, so the token is verified
+		// when present but not required.
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+
+		r.Get("/", cartHandle.GetCart)
+		r.Post("/lines", cartHandle.AddCartLine)
+		r.Put("/lines/{product_id}", cartHandle.UpdateCartLine)
+		r.Delete("/lines/{product_id}", cartHandle.RemoveCartLine)
+		r.Post("/accept_prices", cartHandle.AcceptCartPrices)
+	})
+
+	router.Route("/orders", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.Post("/", orderHandle.CreateOrder)
+		r.Get("/", orderHandle.GetOrders)
+		r.Get("/{id}", orderHandle.GetOrder)
+		r.Put("/{id}/status", orderHandle.UpdateOrderStatus)
+	})
+
+	router.Route("/admin", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+		r.Use(handlers.RequireRole(entity.RoleAdmin))
+
+		r.Get("/orders", orderHandle.GetAllOrders)
+		r.Put("/reviews/{review_id}/moderation", reviewHandle.ModerateReview)
+		r.Get("/users", userHandle.GetUsers)
+		r.Put("/users/{id}/role", userHandle.UpdateUserRole)
+		r.Post("/users/{id}/revoke_sessions", userHandle.RevokeUserSessions)
+	})
+
+	router.Route("/users/me/wishlists", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.Post("/", wishlistHandle.CreateWishlist)
+		r.Get("/", wishlistHandle.GetWishlists)
+		r.Get("/{id}", wishlistHandle.GetWishlist)
+		r.Put("/{id}", wishlistHandle.UpdateWishlist)
+		r.Delete("/{id}", wishlistHandle.DeleteWishlist)
+		r.Post("/{id}/items", wishlistHandle.AddWishlistItem)
+		r.Put("/{id}/items/{product_id}", wishlistHandle.UpdateWishlistItem)
+		r.Delete("/{id}/items/{product_id}", wishlistHandle.RemoveWishlistItem)
+		r.Post("/{id}/share", wishlistHandle.ShareWishlist)
+		r.Delete("/{id}/share", wishlistHandle.UnshareWishlist)
+	})
+	router.Get("/wishlists/shared/{token}", wishlistHandle.GetSharedWishlist)
+
 	router.Post("/users", userHandle.CreateUser)
 	router.Post("/users/generate_token", userHandle.GetJWT)
+	router.Post("/users/refresh_token", userHandle.RefreshToken)
+	router.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verifier(configs.TokenAuthKey))
+		r.Use(handlers.RejectRevoked(denylist))
+		r.Use(jwtauth.Authenticator)
+
+		r.Post("/users/logout", userHandle.Logout)
+		r.Post("/users/logout_all", userHandle.LogoutAll)
+	})
 
 	router.Get("/docs/*", httpSwagger.Handler(httpSwagger.URL("http://localhost:3333/docs/doc.json")))
 	http.ListenAndServe(":3333", router)
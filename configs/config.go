@@ -1,21 +1,40 @@
 package configs
 
 import (
+	"time"
+
 	"github.com/go-chi/jwtauth"
 	"github.com/spf13/viper"
 )
 
 type conf struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBHost        string `mapstructure:"DB_HOST"`
-	DBPort        string `mapstructure:"DB_PORT"`
-	DBUser        string `mapstructure:"DB_USER"`
-	DBPass        string `mapstructure:"DB_PASS"`
-	DBName        string `mapstructure:"DB_NAME"`
-	WebServerPort string `mapstructure:"WEB_SERVER_PORT"`
-	JWTSecret     string `mapstructure:"JWT_SECRET"`
-	JWTExpiresIn  int    `mapstructure:"JWT_EXPIRESIN"`
-	TokenAuthKey  *jwtauth.JWTAuth
+	DBDriver            string        `mapstructure:"DB_DRIVER"`
+	DBHost              string        `mapstructure:"DB_HOST"`
+	DBPort              string        `mapstructure:"DB_PORT"`
+	DBUser              string        `mapstructure:"DB_USER"`
+	DBPass              string        `mapstructure:"DB_PASS"`
+	DBName              string        `mapstructure:"DB_NAME"`
+	WebServerPort       string        `mapstructure:"WEB_SERVER_PORT"`
+	JWTSecret           string        `mapstructure:"JWT_SECRET"`
+	JWTExpiresIn        int           `mapstructure:"JWT_EXPIRESIN"`
+	DefaultLocale       string        `mapstructure:"DEFAULT_LOCALE"`
+	SupportedLocales    []string      `mapstructure:"SUPPORTED_LOCALES"`
+	BundleDeletePolicy  string        `mapstructure:"BUNDLE_DELETE_POLICY"`
+	TaxPricesIncludeTax bool          `mapstructure:"TAX_PRICES_INCLUDE_TAX"`
+	TaxRatesFile        string        `mapstructure:"TAX_RATES_FILE"`
+	CartTTL             time.Duration `mapstructure:"CART_TTL"`
+	CartSweepInterval   time.Duration `mapstructure:"CART_SWEEP_INTERVAL"`
+	BootstrapAdminEmail string        `mapstructure:"BOOTSTRAP_ADMIN_EMAIL"`
+	AlertInterval       time.Duration `mapstructure:"ALERT_INTERVAL"`
+	AlertWebhookURL     string        `mapstructure:"ALERT_WEBHOOK_URL"`
+	AlertLogFile        string        `mapstructure:"ALERT_LOG_FILE"`
+	ViewFlushInterval   time.Duration `mapstructure:"VIEW_FLUSH_INTERVAL"`
+	ViewDedupWindow     time.Duration `mapstructure:"VIEW_DEDUP_WINDOW"`
+	DuplicateThreshold  float64       `mapstructure:"DUPLICATE_THRESHOLD"`
+	RefreshTokenTTL     time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+	RefreshTokenIdleTTL time.Duration `mapstructure:"REFRESH_TOKEN_IDLE_TTL"`
+	DenylistInterval    time.Duration `mapstructure:"DENYLIST_INTERVAL"`
+	TokenAuthKey        *jwtauth.JWTAuth
 }
 
 func LoadConfig(path string) (*conf, error) {
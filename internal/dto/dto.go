@@ -1,10 +1,293 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+)
+
 type CreateProductInput struct {
-	Name  string  `json:"name"`
+	Name        string                 `json:"name"`
+	Price       float64                `json:"price"`
+	Description string                 `json:"description"`
+	Type        string                 `json:"type,omitempty"`
+	Available   *bool                  `json:"available,omitempty"`
+	Pricing     string                 `json:"pricing,omitempty"`
+	Discount    float64                `json:"discount,omitempty"`
+	Components  []BundleComponentInput `json:"components,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	GTIN        string                 `json:"gtin,omitempty"`
+	Weight      *entity.Weight         `json:"weight,omitempty"`
+	Dimensions  *entity.Dimensions     `json:"dimensions,omitempty"`
+}
+
+type CloneProductInput struct {
+	Name         *string   `json:"name"`
+	Price        *float64  `json:"price"`
+	Description  *string   `json:"description"`
+	Available    *bool     `json:"available"`
+	Tags         *[]string `json:"tags"`
+	ReorderPoint *int      `json:"reorder_point"`
+}
+
+type ProductTemplateInput struct {
+	Name        string   `json:"name"`
+	Price       float64  `json:"price"`
+	Description string   `json:"description"`
+	Available   *bool    `json:"available"`
+	Tags        []string `json:"tags"`
+}
+
+type BundleComponentInput struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type CreatePromotionInput struct {
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	Value      float64    `json:"value"`
+	ProductIDs []string   `json:"product_ids"`
+	Tags       []string   `json:"tags"`
+	MinPrice   float64    `json:"min_price"`
+	MaxPrice   float64    `json:"max_price"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+	Priority   int        `json:"priority"`
+	Stackable  bool       `json:"stackable"`
+}
+
+type GenerateCouponsInput struct {
+	Count          int        `json:"count"`
+	Prefix         string     `json:"prefix"`
+	Length         int        `json:"length"`
+	Kind           string     `json:"kind"`
+	Value          float64    `json:"value"`
+	MinPrice       float64    `json:"min_price"`
+	ProductIDs     []string   `json:"product_ids"`
+	Tags           []string   `json:"tags"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	MaxPerUser     int        `json:"max_per_user"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+type ProductQuantityInput struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type ValidateCouponInput struct {
+	Lines []ProductQuantityInput `json:"lines"`
+}
+
+type RedeemCouponOutput struct {
+	RedemptionID string `json:"redemption_id"`
+	entity.CouponResult
+}
+
+type AddCartLineInput struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type UpdateCartLineInput struct {
+	Quantity int `json:"quantity"`
+}
+
+type OrderLineInput struct {
+	ProductID string   `json:"product_id"`
+	Quantity  int      `json:"quantity"`
+	UnitPrice *float64 `json:"unit_price"`
+}
+
+type CreateOrderInput struct {
+	Lines []OrderLineInput `json:"lines"`
+}
+
+type UpdateOrderStatusInput struct {
+	Status string `json:"status"`
+}
+
+type WishlistInput struct {
+	Name string `json:"name"`
+}
+
+type AddWishlistItemInput struct {
+	ProductID string `json:"product_id"`
+	Note      string `json:"note"`
+}
+
+type UpdateWishlistItemInput struct {
+	Note string `json:"note"`
+}
+
+type SharedWishlistOutput struct {
+	Name      string                     `json:"name"`
+	Items     []SharedWishlistItemOutput `json:"items"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
+
+type SharedWishlistItemOutput struct {
+	ProductID      string               `json:"product_id"`
+	ProductName    string               `json:"product_name"`
+	Note           string               `json:"note"`
+	AddedAt        time.Time            `json:"added_at"`
+	Product        *SharedProductOutput `json:"product,omitempty"`
+	ProductDeleted bool                 `json:"product_deleted"`
+}
+
+type SharedProductOutput struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug"`
+	Price     float64 `json:"price"`
+	Available bool    `json:"available"`
+}
+
+type ReviewInput struct {
+	Rating int    `json:"rating"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+type ModerateReviewInput struct {
+	Hidden bool   `json:"hidden"`
+	Reason string `json:"reason"`
+}
+
+type SupplierInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+type ProductSupplierInput struct {
+	SupplierSKU  string  `json:"supplier_sku"`
+	CostPrice    float64 `json:"cost_price"`
+	LeadTimeDays int     `json:"lead_time_days"`
+}
+
+type PurchaseOrderLineInput struct {
+	ProductID string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	UnitCost  float64 `json:"unit_cost"`
+}
+
+type PurchaseOrderInput struct {
+	SupplierID string                   `json:"supplier_id"`
+	Notes      string                   `json:"notes"`
+	Lines      []PurchaseOrderLineInput `json:"lines"`
+}
+
+type ReceivedLineInput struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type ReceivePurchaseOrderInput struct {
+	Lines []ReceivedLineInput `json:"lines"`
+}
+
+type WarehouseInput struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type AdjustStockInput struct {
+	ProductID string `json:"product_id"`
+	Delta     int    `json:"delta"`
+}
+
+type CreateTransferInput struct {
+	ProductID       string `json:"product_id"`
+	FromWarehouseID string `json:"from_warehouse_id"`
+	ToWarehouseID   string `json:"to_warehouse_id"`
+	Quantity        int    `json:"quantity"`
+}
+
+type ReorderPointInput struct {
+	ReorderPoint int `json:"reorder_point"`
+}
+
+type DuplicateProductsOutput struct {
+	Message    string                      `json:"message"`
+	Candidates []entity.DuplicateCandidate `json:"candidates"`
+}
+
+type MergeProductsInput struct {
+	SourceIDs []string          `json:"source_ids"`
+	Rules     map[string]string `json:"rules"`
+}
+
+type MergeProductsOutput struct {
+	Product *entity.Product      `json:"product"`
+	Merge   *entity.ProductMerge `json:"merge"`
+}
+
+type ShippingRateInput struct {
+	UpTo  float64 `json:"up_to"`
 	Price float64 `json:"price"`
 }
 
+type ShippingMethodInput struct {
+	Name              string              `json:"name"`
+	DeliveryDays      int                 `json:"delivery_days"`
+	WeightUnit        string              `json:"weight_unit"`
+	VolumetricDivisor float64             `json:"volumetric_divisor"`
+	Rates             []ShippingRateInput `json:"rates"`
+}
+
+type ShippingZoneInput struct {
+	Name    string                `json:"name"`
+	Methods []ShippingMethodInput `json:"methods"`
+}
+
+type ShippingQuoteInput struct {
+	ZoneID string                 `json:"zone_id"`
+	Lines  []ProductQuantityInput `json:"lines"`
+}
+
+type CreateTaxClassInput struct {
+	Name string `json:"name"`
+}
+
+type CreateTaxRateInput struct {
+	TaxClassID    string     `json:"tax_class_id"`
+	Jurisdiction  string     `json:"jurisdiction"`
+	Name          string     `json:"name"`
+	Rate          float64    `json:"rate"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+type ImportTaxRatesOutput struct {
+	Imported int `json:"imported"`
+}
+
+type AssignTaxClassInput struct {
+	TaxClassID *string `json:"tax_class_id"`
+}
+
+type ProductPriceOutput struct {
+	ProductID      string  `json:"product_id"`
+	TaxClassID     string  `json:"tax_class_id"`
+	Price          float64 `json:"price"`
+	EffectivePrice float64 `json:"effective_price"`
+	entity.TaxBreakdown
+}
+
+type UpsertProductTranslationInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type MissingTranslationsOutput struct {
+	ProductID      string   `json:"product_id"`
+	Name           string   `json:"name"`
+	MissingLocales []string `json:"missing_locales"`
+}
+
 type CreateUserInput struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
@@ -14,8 +297,19 @@ type CreateUserInput struct {
 type GetJWTInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	CartID   string `json:"cart_id,omitempty"`
 }
 
 type GetJWrOutput struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	CartID       string `json:"cart_id,omitempty"`
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type UpdateRoleInput struct {
+	Role string `json:"role"`
 }
@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/bhyago/crud-products-go/pkg/slug"
+)
+
+const (
+	ProductTypeSimple = "simple"
+	ProductTypeBundle = "bundle"
+
+	// PricingFixed bundles keep the price they were given.
+	PricingFixed = "fixed"
+	// PricingDerived bundles cost the sum of their components minus Discount percent.
+	PricingDerived = "derived"
+)
+
+var (
+	ErrTypeInvalid          = errors.New("type is invalid")
+	ErrPricingInvalid       = errors.New("pricing is invalid")
+	ErrDiscountInvalid      = errors.New("discount must be between 0 and 100")
+	ErrComponentsRequired   = errors.New("bundle requires at least one component")
+	ErrComponentsNotAllowed = errors.New("only bundles can have components")
+	ErrComponentQuantity    = errors.New("component quantity must be positive")
+	ErrComponentDuplicated  = errors.New("component is duplicated")
+	ErrComponentInvalid     = errors.New("component must be an existing simple product")
+	ErrProductInBundle      = errors.New("product is a component of a bundle")
+)
+
+// BundleComponent links a bundle to one of the products it is made of.
+type BundleComponent struct {
+	BundleID  entity.ID `json:"-" gorm:"primaryKey"`
+	ProductID entity.ID `json:"product_id" gorm:"primaryKey;index"`
+	Quantity  int       `json:"quantity"`
+}
+
+func NewBundle(name string, price float64, pricing string, discount float64, components []BundleComponent) (*Product, error) {
+	bundle := &Product{
+		ID:        entity.NewID(),
+		Name:      name,
+		Slug:      slug.Make(name),
+		Type:      ProductTypeBundle,
+		Available: true,
+		Price:     price,
+		Pricing:   pricing,
+		Discount:  discount,
+		Tags:      StringList{},
+		CreatedAt: time.Now(),
+	}
+	bundle.SetComponents(components)
+
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (p *Product) IsBundle() bool {
+	return p.Type == ProductTypeBundle
+}
+
+// SetComponents replaces the components of the bundle.
+func (p *Product) SetComponents(components []BundleComponent) {
+	p.Components = make([]BundleComponent, len(components))
+	for i, c := range components {
+		c.BundleID = p.ID
+		p.Components[i] = c
+	}
+}
+
+// Refresh recomputes the fields a bundle derives from its components: the
+// price when pricing is derived, and availability. products must hold every
+// component, keyed by ID.
+func (p *Product) Refresh(products map[string]Product) error {
+	if !p.IsBundle() {
+		return nil
+	}
+
+	total := 0.0
+	available := true
+	for _, c := range p.Components {
+		component, ok := products[c.ProductID.String()]
+		if !ok || component.IsBundle() {
+			return ErrComponentInvalid
+		}
+		total += component.Price * float64(c.Quantity)
+		available = available && component.Available
+	}
+
+	p.Available = available
+	if p.Pricing == PricingDerived {
+		p.Price = roundCents(total * (100 - p.Discount) / 100)
+	}
+	return nil
+}
+
+func (p *Product) validateBundle() error {
+	switch p.Type {
+	case ProductTypeSimple:
+		if len(p.Components) > 0 {
+			return ErrComponentsNotAllowed
+		}
+		return nil
+	case ProductTypeBundle:
+	default:
+		return ErrTypeInvalid
+	}
+
+	if p.Pricing != PricingFixed && p.Pricing != PricingDerived {
+		return ErrPricingInvalid
+	}
+	if p.Discount < 0 || p.Discount >= 100 {
+		return ErrDiscountInvalid
+	}
+	if len(p.Components) == 0 {
+		return ErrComponentsRequired
+	}
+
+	seen := make(map[entity.ID]bool, len(p.Components))
+	for _, c := range p.Components {
+		if c.Quantity <= 0 {
+			return ErrComponentQuantity
+		}
+		if c.ProductID == p.ID {
+			return ErrComponentInvalid
+		}
+		if seen[c.ProductID] {
+			return ErrComponentDuplicated
+		}
+		seen[c.ProductID] = true
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBundle(t *testing.T) {
+	component, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	bundle, err := NewBundle("Kit", 0, PricingDerived, 10, []BundleComponent{{ProductID: component.ID, Quantity: 2}})
+	assert.Nil(t, err)
+	assert.True(t, bundle.IsBundle())
+	assert.Equal(t, bundle.ID, bundle.Components[0].BundleID)
+}
+
+func TestBundleWhenComponentsAreRequired(t *testing.T) {
+	bundle, err := NewBundle("Kit", 10, PricingFixed, 0, nil)
+	assert.Nil(t, bundle)
+	assert.Equal(t, ErrComponentsRequired, err)
+}
+
+func TestBundleWhenComponentIsDuplicated(t *testing.T) {
+	component, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	bundle, err := NewBundle("Kit", 10, PricingFixed, 0, []BundleComponent{
+		{ProductID: component.ID, Quantity: 1},
+		{ProductID: component.ID, Quantity: 2},
+	})
+	assert.Nil(t, bundle)
+	assert.Equal(t, ErrComponentDuplicated, err)
+}
+
+func TestBundleWhenPricingIsInvalid(t *testing.T) {
+	component, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	bundle, err := NewBundle("Kit", 10, "free", 0, []BundleComponent{{ProductID: component.ID, Quantity: 1}})
+	assert.Nil(t, bundle)
+	assert.Equal(t, ErrPricingInvalid, err)
+}
+
+func TestBundleRefreshDerivesPriceAndAvailability(t *testing.T) {
+	first, _ := NewProduct("Product 1", 10)
+	second, _ := NewProduct("Product 2", 5.5)
+	second.Available = false
+
+	bundle, err := NewBundle("Kit", 0, PricingDerived, 10, []BundleComponent{
+		{ProductID: first.ID, Quantity: 2},
+		{ProductID: second.ID, Quantity: 1},
+	})
+	assert.Nil(t, err)
+
+	err = bundle.Refresh(map[string]Product{
+		first.ID.String():  *first,
+		second.ID.String(): *second,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 22.95, bundle.Price)
+	assert.False(t, bundle.Available)
+}
+
+func TestBundleRefreshKeepsFixedPrice(t *testing.T) {
+	component, _ := NewProduct("Product 1", 10)
+	bundle, err := NewBundle("Kit", 15, PricingFixed, 0, []BundleComponent{{ProductID: component.ID, Quantity: 2}})
+	assert.Nil(t, err)
+
+	err = bundle.Refresh(map[string]Product{component.ID.String(): *component})
+	assert.Nil(t, err)
+	assert.Equal(t, 15.0, bundle.Price)
+	assert.True(t, bundle.Available)
+}
+
+func TestBundleRefreshWhenComponentIsMissing(t *testing.T) {
+	component, _ := NewProduct("Product 1", 10)
+	bundle, err := NewBundle("Kit", 15, PricingFixed, 0, []BundleComponent{{ProductID: component.ID, Quantity: 2}})
+	assert.Nil(t, err)
+
+	err = bundle.Refresh(map[string]Product{})
+	assert.Equal(t, ErrComponentInvalid, err)
+}
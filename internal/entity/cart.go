@@ -0,0 +1,174 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+var (
+	ErrProductUnavailable = errors.New("product is not available")
+	ErrCartLineNotFound   = errors.New("product is not in the cart")
+	ErrCartPriceChanged   = errors.New("prices changed since the products were added to the cart")
+)
+
+// Cart holds the products a customer intends to buy. Carts without a UserID
+// belong to anonymous visitors and are merged into the user cart at login.
+type Cart struct {
+	ID        entity.ID  `json:"id"`
+	UserID    *entity.ID `json:"user_id" gorm:"uniqueIndex"`
+	Lines     []CartLine `json:"lines" gorm:"foreignKey:CartID"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"index"`
+
+	Subtotal     float64 `json:"subtotal" gorm:"-"`
+	PriceChanged bool    `json:"price_changed" gorm:"-"`
+}
+
+// CartLine keeps the price of the product when it was put in the cart, so
+// the customer is told when the catalog price moves afterwards.
+type CartLine struct {
+	CartID    entity.ID `json:"-" gorm:"primaryKey"`
+	ProductID entity.ID `json:"product_id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice float64   `json:"unit_price"`
+	AddedAt   time.Time `json:"added_at"`
+
+	CurrentPrice float64 `json:"current_price" gorm:"-"`
+	PriceChanged bool    `json:"price_changed" gorm:"-"`
+	Available    bool    `json:"available" gorm:"-"`
+}
+
+func NewCart(userID *entity.ID) *Cart {
+	now := time.Now()
+	return &Cart{
+		ID:        entity.NewID(),
+		UserID:    userID,
+		Lines:     []CartLine{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// AddProduct puts quantity units of the product in the cart, adding to the
+// line when the product is already there. The line price is snapshotted
+// again, as the customer is looking at the current one.
+func (c *Cart) AddProduct(product *Product, quantity int) error {
+	if line := c.line(product.ID); line != nil {
+		return c.SetProduct(product, line.Quantity+quantity)
+	}
+	if quantity <= 0 {
+		return ErrQuantityInvalid
+	}
+	if !product.Available {
+		return ErrProductUnavailable
+	}
+
+	c.Lines = append(c.Lines, CartLine{
+		CartID:    c.ID,
+		ProductID: product.ID,
+		Name:      product.Name,
+		Quantity:  quantity,
+		UnitPrice: currentPrice(product),
+		AddedAt:   time.Now(),
+	})
+	return nil
+}
+
+// SetProduct replaces the quantity of a product already in the cart.
+func (c *Cart) SetProduct(product *Product, quantity int) error {
+	if quantity <= 0 {
+		return ErrQuantityInvalid
+	}
+	line := c.line(product.ID)
+	if line == nil {
+		return ErrCartLineNotFound
+	}
+	if !product.Available {
+		return ErrProductUnavailable
+	}
+
+	line.Name = product.Name
+	line.Quantity = quantity
+	line.UnitPrice = currentPrice(product)
+	return nil
+}
+
+func (c *Cart) RemoveProduct(productID entity.ID) error {
+	for i, line := range c.Lines {
+		if line.ProductID == productID {
+			c.Lines = append(c.Lines[:i], c.Lines[i+1:]...)
+			return nil
+		}
+	}
+	return ErrCartLineNotFound
+}
+
+// Merge moves the lines of other into the cart. Quantities of products in
+// both carts are added up and keep the price of the line already here.
+func (c *Cart) Merge(other *Cart) {
+	for _, incoming := range other.Lines {
+		if line := c.line(incoming.ProductID); line != nil {
+			line.Quantity += incoming.Quantity
+			continue
+		}
+		incoming.CartID = c.ID
+		c.Lines = append(c.Lines, incoming)
+	}
+}
+
+// Reconcile compares each line with the current state of its product and
+// computes the subtotal from the snapshotted prices. Products missing from
+// products are reported as unavailable.
+func (c *Cart) Reconcile(products map[string]Product) {
+	c.Subtotal = 0
+	c.PriceChanged = false
+	for i := range c.Lines {
+		line := &c.Lines[i]
+		product, ok := products[line.ProductID.String()]
+		line.Available = ok && product.Available
+		line.CurrentPrice = line.UnitPrice
+		if ok {
+			line.CurrentPrice = currentPrice(&product)
+		}
+		line.PriceChanged = line.CurrentPrice != line.UnitPrice
+		if line.PriceChanged {
+			c.PriceChanged = true
+		}
+		c.Subtotal += line.UnitPrice * float64(line.Quantity)
+	}
+	c.Subtotal = roundCents(c.Subtotal)
+}
+
+// AcceptPrices takes the prices computed by Reconcile as the new snapshots.
+func (c *Cart) AcceptPrices() {
+	for i := range c.Lines {
+		c.Lines[i].UnitPrice = c.Lines[i].CurrentPrice
+		c.Lines[i].PriceChanged = false
+	}
+	c.PriceChanged = false
+}
+
+func (c *Cart) IsExpired(ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && now.Sub(c.UpdatedAt) > ttl
+}
+
+func (c *Cart) line(productID entity.ID) *CartLine {
+	for i := range c.Lines {
+		if c.Lines[i].ProductID == productID {
+			return &c.Lines[i]
+		}
+	}
+	return nil
+}
+
+// currentPrice is what the product costs right now: the price after
+// promotions when they were applied, the catalog price otherwise.
+func currentPrice(p *Product) float64 {
+	if p.AppliedPromotionIDs == nil {
+		return p.Price
+	}
+	return p.EffectivePrice
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCartAddProduct(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	cart := NewCart(nil)
+
+	assert.Nil(t, cart.AddProduct(product, 2))
+	assert.Nil(t, cart.AddProduct(product, 1))
+	assert.Len(t, cart.Lines, 1)
+	assert.Equal(t, 3, cart.Lines[0].Quantity)
+	assert.Equal(t, 10.0, cart.Lines[0].UnitPrice)
+	assert.Equal(t, cart.ID, cart.Lines[0].CartID)
+}
+
+func TestCartAddProductWhenUnavailable(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	product.Available = false
+	cart := NewCart(nil)
+
+	assert.Equal(t, ErrProductUnavailable, cart.AddProduct(product, 1))
+	assert.Equal(t, ErrQuantityInvalid, cart.AddProduct(product, 0))
+}
+
+func TestCartSnapshotsPromotionalPrice(t *testing.T) {
+	product, _ := NewProduct("Product 1", 100)
+	promotion, _ := NewPromotion("Sale", DiscountPercentage, 10, time.Now().Add(-time.Hour), nil)
+	product.ApplyPromotions([]Promotion{*promotion}, time.Now())
+	cart := NewCart(nil)
+
+	assert.Nil(t, cart.AddProduct(product, 1))
+	assert.Equal(t, 90.0, cart.Lines[0].UnitPrice)
+}
+
+func TestCartRemoveProduct(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	cart := NewCart(nil)
+	assert.Nil(t, cart.AddProduct(product, 1))
+
+	assert.Nil(t, cart.RemoveProduct(product.ID))
+	assert.Empty(t, cart.Lines)
+	assert.Equal(t, ErrCartLineNotFound, cart.RemoveProduct(product.ID))
+}
+
+func TestCartReconcileDetectsPriceChanges(t *testing.T) {
+	first, _ := NewProduct("Product 1", 10)
+	second, _ := NewProduct("Product 2", 20)
+	cart := NewCart(nil)
+	assert.Nil(t, cart.AddProduct(first, 2))
+	assert.Nil(t, cart.AddProduct(second, 1))
+
+	first.Price = 12
+	cart.Reconcile(map[string]Product{first.ID.String(): *first})
+	assert.True(t, cart.PriceChanged)
+	assert.Equal(t, 40.0, cart.Subtotal)
+	assert.True(t, cart.Lines[0].PriceChanged)
+	assert.Equal(t, 12.0, cart.Lines[0].CurrentPrice)
+	assert.False(t, cart.Lines[1].Available)
+
+	cart.AcceptPrices()
+	cart.Reconcile(map[string]Product{first.ID.String(): *first, second.ID.String(): *second})
+	assert.False(t, cart.PriceChanged)
+	assert.Equal(t, 44.0, cart.Subtotal)
+}
+
+func TestCartMerge(t *testing.T) {
+	first, _ := NewProduct("Product 1", 10)
+	second, _ := NewProduct("Product 2", 20)
+	userID := entity.NewID()
+	cart := NewCart(&userID)
+	assert.Nil(t, cart.AddProduct(first, 1))
+	anonymous := NewCart(nil)
+	assert.Nil(t, anonymous.AddProduct(first, 2))
+	assert.Nil(t, anonymous.AddProduct(second, 1))
+
+	cart.Merge(anonymous)
+	assert.Len(t, cart.Lines, 2)
+	assert.Equal(t, 3, cart.Lines[0].Quantity)
+	assert.Equal(t, cart.ID, cart.Lines[1].CartID)
+}
+
+func TestCartIsExpired(t *testing.T) {
+	cart := NewCart(nil)
+	assert.False(t, cart.IsExpired(time.Hour, time.Now()))
+	assert.True(t, cart.IsExpired(time.Hour, time.Now().Add(2*time.Hour)))
+	assert.False(t, cart.IsExpired(0, time.Now().Add(2*time.Hour)))
+}
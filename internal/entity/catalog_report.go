@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+// DefaultPriceBuckets are the lower bounds of the price histogram buckets
+// used when none are given.
+var DefaultPriceBuckets = []float64{0, 10, 50, 100, 500}
+
+var (
+	ErrBucketsInvalid  = errors.New("buckets must be distinct non-negative numbers")
+	ErrIntervalInvalid = errors.New("interval must be day, week or month")
+)
+
+// PriceBucket counts the products priced from From, included, to To,
+// excluded. The last bucket has no upper bound.
+type PriceBucket struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to"`
+	Count int64    `json:"count"`
+}
+
+// CreatedCount is the number of products created in a period: a day
+// (2006-01-02), an ISO 8601 week (2006-W01) or a month (2006-01).
+type CreatedCount struct {
+	Period string `json:"period"`
+	Count  int64  `json:"count"`
+}
+
+type CatalogReport struct {
+	ProductCount int64          `json:"product_count"`
+	MinPrice     float64        `json:"min_price"`
+	MaxPrice     float64        `json:"max_price"`
+	AvgPrice     float64        `json:"avg_price"`
+	MedianPrice  float64        `json:"median_price"`
+	Histogram    []PriceBucket  `json:"histogram"`
+	Interval     string         `json:"interval"`
+	Created      []CreatedCount `json:"created"`
+}
+
+// ParsePriceBuckets parses comma-separated lower bounds, such as
+// "0,10,50", into sorted buckets.
+func ParsePriceBuckets(value string) ([]float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultPriceBuckets, nil
+	}
+
+	bounds := []float64{}
+	seen := map[float64]bool{}
+	for _, part := range strings.Split(value, ",") {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || bound < 0 || seen[bound] {
+			return nil, ErrBucketsInvalid
+		}
+		seen[bound] = true
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+	return bounds, nil
+}
+
+func IsReportInterval(interval string) bool {
+	switch interval {
+	case ReportIntervalDay, ReportIntervalWeek, ReportIntervalMonth:
+		return true
+	}
+	return false
+}
+
+// Rows flattens the report into section, label and value rows for CSV
+// exports.
+func (r CatalogReport) Rows() [][]string {
+	rows := [][]string{
+		{"section", "label", "value"},
+		{"summary", "product_count", strconv.FormatInt(r.ProductCount, 10)},
+		{"summary", "min_price", formatPrice(r.MinPrice)},
+		{"summary", "max_price", formatPrice(r.MaxPrice)},
+		{"summary", "avg_price", formatPrice(r.AvgPrice)},
+		{"summary", "median_price", formatPrice(r.MedianPrice)},
+	}
+	for _, bucket := range r.Histogram {
+		label := formatPrice(bucket.From) + "+"
+		if bucket.To != nil {
+			label = formatPrice(bucket.From) + "-" + formatPrice(*bucket.To)
+		}
+		rows = append(rows, []string{"histogram", label, strconv.FormatInt(bucket.Count, 10)})
+	}
+	for _, created := range r.Created {
+		rows = append(rows, []string{"created_per_" + r.Interval, created.Period, strconv.FormatInt(created.Count, 10)})
+	}
+	return rows
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePriceBuckets(t *testing.T) {
+	buckets, err := ParsePriceBuckets("")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultPriceBuckets, buckets)
+
+	buckets, err = ParsePriceBuckets("50, 0,10.5")
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0, 10.5, 50}, buckets)
+
+	for _, value := range []string{"a", "-1", "1,1", "1,"} {
+		_, err = ParsePriceBuckets(value)
+		assert.Equal(t, ErrBucketsInvalid, err, value)
+	}
+}
+
+func TestCatalogReportRows(t *testing.T) {
+	ten := 10.0
+	report := CatalogReport{
+		ProductCount: 3,
+		MinPrice:     5,
+		MaxPrice:     20,
+		AvgPrice:     11.67,
+		MedianPrice:  10,
+		Histogram:    []PriceBucket{{From: 0, To: &ten, Count: 1}, {From: 10, Count: 2}},
+		Interval:     ReportIntervalMonth,
+		Created:      []CreatedCount{{Period: "2024-03", Count: 3}},
+	}
+
+	rows := report.Rows()
+	assert.Equal(t, []string{"section", "label", "value"}, rows[0])
+	assert.Equal(t, []string{"summary", "avg_price", "11.67"}, rows[4])
+	assert.Equal(t, []string{"histogram", "0-10", "1"}, rows[6])
+	assert.Equal(t, []string{"histogram", "10+", "2"}, rows[7])
+	assert.Equal(t, []string{"created_per_month", "2024-03", "3"}, rows[8])
+}
@@ -0,0 +1,245 @@
+package entity
+
+import (
+	"crypto/rand"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// couponAlphabet leaves out characters that are easy to misread (0/O, 1/I/L).
+const couponAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const (
+	MinCouponCodeLength = 6
+	MaxCouponCodeLength = 32
+	MaxCouponBatchSize  = 10000
+)
+
+var (
+	ErrCouponCodeLength        = errors.New("code length must be between 6 and 32")
+	ErrCouponBatchSize         = errors.New("count must be between 1 and 10000")
+	ErrCouponExpired           = errors.New("coupon is expired")
+	ErrCouponLimitReached      = errors.New("coupon redemption limit reached")
+	ErrCouponUserLimitReached  = errors.New("coupon redemption limit reached for this user")
+	ErrCouponMinimumNotReached = errors.New("order does not reach the coupon minimum")
+	ErrCouponNotApplicable     = errors.New("coupon does not apply to any of the products")
+	ErrQuantityInvalid         = errors.New("quantity must be positive")
+)
+
+// Coupon is a redeemable discount code. Coupons generated together share a
+// BatchID and the same rules.
+type Coupon struct {
+	Code           string     `json:"code" gorm:"primaryKey"`
+	BatchID        entity.ID  `json:"batch_id" gorm:"index"`
+	Kind           string     `json:"kind"`
+	Value          float64    `json:"value"`
+	MinPrice       float64    `json:"min_price"`
+	ProductIDs     StringList `json:"product_ids"`
+	Tags           StringList `json:"tags"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	MaxPerUser     int        `json:"max_per_user"`
+	Redemptions    int        `json:"redemptions"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type CouponRedemption struct {
+	ID        entity.ID `json:"id"`
+	Code      string    `json:"code" gorm:"index"`
+	UserID    entity.ID `json:"user_id" gorm:"index"`
+	Discount  float64   `json:"discount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CouponLine is a product and quantity a coupon is checked against.
+type CouponLine struct {
+	Product  Product
+	Quantity int
+}
+
+type CouponLineResult struct {
+	ProductID string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Total     float64 `json:"total"`
+	Eligible  bool    `json:"eligible"`
+	Discount  float64 `json:"discount"`
+	Final     float64 `json:"final"`
+}
+
+type CouponResult struct {
+	Code     string             `json:"code"`
+	Lines    []CouponLineResult `json:"lines"`
+	Subtotal float64            `json:"subtotal"`
+	Discount float64            `json:"discount"`
+	Total    float64            `json:"total"`
+}
+
+// NewCouponBatch generates count coupons sharing the rules of template, each
+// with a random code of the given length after prefix.
+func NewCouponBatch(template Coupon, count int, prefix string, length int) ([]Coupon, error) {
+	if count < 1 || count > MaxCouponBatchSize {
+		return nil, ErrCouponBatchSize
+	}
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+
+	batchID := entity.NewID()
+	coupons := make([]Coupon, count)
+	for i := range coupons {
+		code, err := GenerateCouponCode(prefix, length)
+		if err != nil {
+			return nil, err
+		}
+		coupons[i] = template
+		coupons[i].Code = code
+		coupons[i].BatchID = batchID
+		coupons[i].Redemptions = 0
+		coupons[i].CreatedAt = time.Now()
+	}
+	return coupons, nil
+}
+
+// GenerateCouponCode returns prefix followed by length random characters
+// drawn from a cryptographically secure source.
+func GenerateCouponCode(prefix string, length int) (string, error) {
+	if length < MinCouponCodeLength || length > MaxCouponCodeLength {
+		return "", ErrCouponCodeLength
+	}
+
+	var b strings.Builder
+	b.WriteString(NormalizeCouponCode(prefix))
+	max := big.NewInt(int64(len(couponAlphabet)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(couponAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// NormalizeCouponCode makes code lookups case and whitespace insensitive.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (c *Coupon) Validate() error {
+	switch c.Kind {
+	case DiscountPercentage:
+		if c.Value <= 0 || c.Value > 100 {
+			return ErrDiscountValueInvalid
+		}
+	case DiscountFixed:
+		if c.Value <= 0 {
+			return ErrDiscountValueInvalid
+		}
+	default:
+		return ErrDiscountKindInvalid
+	}
+
+	if c.MinPrice < 0 || c.MaxRedemptions < 0 || c.MaxPerUser < 0 {
+		return ErrDiscountValueInvalid
+	}
+
+	return nil
+}
+
+func (c *Coupon) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// Eligible reports whether the coupon covers the product, using the same
+// targeting rules as promotions.
+func (c *Coupon) Eligible(product *Product) bool {
+	if len(c.ProductIDs) > 0 && !c.ProductIDs.Contains(product.ID.String()) {
+		return false
+	}
+	if len(c.Tags) == 0 {
+		return true
+	}
+	for _, tag := range c.Tags {
+		if product.Tags.Contains(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply computes the discount of the coupon on lines, priced at the
+// products' effective price, or their list price when no promotion was
+// evaluated. Only eligible lines count towards the minimum
+// and receive a discount; a fixed discount is split between them in
+// proportion to their totals. Redemption limits are not checked here.
+func (c *Coupon) Apply(lines []CouponLine, now time.Time) (*CouponResult, error) {
+	if c.IsExpired(now) {
+		return nil, ErrCouponExpired
+	}
+
+	result := &CouponResult{Code: c.Code, Lines: make([]CouponLineResult, len(lines))}
+	eligibleTotal := 0.0
+	lastEligible := -1
+	for i, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, ErrQuantityInvalid
+		}
+		unitPrice := line.Product.EffectivePrice
+		if unitPrice == 0 && len(line.Product.AppliedPromotionIDs) == 0 {
+			unitPrice = line.Product.Price
+		}
+		total := roundCents(unitPrice * float64(line.Quantity))
+		eligible := c.Eligible(&line.Product)
+		result.Lines[i] = CouponLineResult{
+			ProductID: line.Product.ID.String(),
+			Quantity:  line.Quantity,
+			UnitPrice: unitPrice,
+			Total:     total,
+			Eligible:  eligible,
+			Final:     total,
+		}
+		result.Subtotal = roundCents(result.Subtotal + total)
+		if eligible {
+			eligibleTotal = roundCents(eligibleTotal + total)
+			lastEligible = i
+		}
+	}
+
+	if lastEligible < 0 {
+		return nil, ErrCouponNotApplicable
+	}
+	if eligibleTotal < c.MinPrice {
+		return nil, ErrCouponMinimumNotReached
+	}
+
+	discount := c.Value
+	if c.Kind == DiscountPercentage {
+		discount = eligibleTotal * c.Value / 100
+	}
+	discount = roundCents(math.Min(discount, eligibleTotal))
+
+	remaining := discount
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		if !line.Eligible {
+			continue
+		}
+		share := remaining
+		if i != lastEligible {
+			share = roundCents(discount * line.Total / eligibleTotal)
+		}
+		remaining = roundCents(remaining - share)
+		line.Discount = share
+		line.Final = roundCents(line.Total - share)
+	}
+
+	result.Discount = discount
+	result.Total = roundCents(result.Subtotal - discount)
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCouponCode(t *testing.T) {
+	code, err := GenerateCouponCode("bf-", 8)
+	assert.Nil(t, err)
+	assert.Len(t, code, 11)
+	assert.True(t, strings.HasPrefix(code, "BF-"))
+	for _, r := range code[3:] {
+		assert.Contains(t, couponAlphabet, string(r))
+	}
+}
+
+func TestGenerateCouponCodeWhenLengthIsInvalid(t *testing.T) {
+	_, err := GenerateCouponCode("", 4)
+	assert.Equal(t, ErrCouponCodeLength, err)
+}
+
+func TestNewCouponBatch(t *testing.T) {
+	coupons, err := NewCouponBatch(Coupon{Kind: DiscountPercentage, Value: 10, MaxPerUser: 1}, 50, "", 10)
+	assert.Nil(t, err)
+	assert.Len(t, coupons, 50)
+
+	codes := map[string]bool{}
+	for _, c := range coupons {
+		assert.Equal(t, coupons[0].BatchID, c.BatchID)
+		assert.Equal(t, 1, c.MaxPerUser)
+		codes[c.Code] = true
+	}
+	assert.Len(t, codes, 50)
+}
+
+func TestNewCouponBatchWhenKindIsInvalid(t *testing.T) {
+	coupons, err := NewCouponBatch(Coupon{Kind: "free", Value: 10}, 1, "", 10)
+	assert.Nil(t, coupons)
+	assert.Equal(t, ErrDiscountKindInvalid, err)
+}
+
+func TestCouponApplyPercentageToEligibleLines(t *testing.T) {
+	shoe, _ := NewProduct("Shoe", 50)
+	shoe.Tags = StringList{"shoes"}
+	shirt, _ := NewProduct("Shirt", 20)
+	coupon := Coupon{Code: "SAVE", Kind: DiscountPercentage, Value: 10, Tags: StringList{"shoes"}}
+
+	result, err := coupon.Apply([]CouponLine{{Product: *shoe, Quantity: 2}, {Product: *shirt, Quantity: 1}}, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 120.0, result.Subtotal)
+	assert.Equal(t, 10.0, result.Discount)
+	assert.Equal(t, 110.0, result.Total)
+	assert.Equal(t, 90.0, result.Lines[0].Final)
+	assert.False(t, result.Lines[1].Eligible)
+	assert.Equal(t, 20.0, result.Lines[1].Final)
+}
+
+func TestCouponApplyFixedSplitsBetweenLines(t *testing.T) {
+	a, _ := NewProduct("A", 10)
+	b, _ := NewProduct("B", 20)
+	coupon := Coupon{Code: "TEN", Kind: DiscountFixed, Value: 10}
+
+	result, err := coupon.Apply([]CouponLine{{Product: *a, Quantity: 1}, {Product: *b, Quantity: 1}}, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 3.33, result.Lines[0].Discount)
+	assert.Equal(t, 6.67, result.Lines[1].Discount)
+	assert.Equal(t, 20.0, result.Total)
+}
+
+func TestCouponApplyUsesEffectivePrice(t *testing.T) {
+	a, _ := NewProduct("A", 10)
+	a.EffectivePrice = 8
+	a.AppliedPromotionIDs = []string{"promo"}
+	coupon := Coupon{Code: "ONE", Kind: DiscountFixed, Value: 1}
+
+	result, err := coupon.Apply([]CouponLine{{Product: *a, Quantity: 1}}, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 7.0, result.Total)
+}
+
+func TestCouponApplyWhenMinimumIsNotReached(t *testing.T) {
+	a, _ := NewProduct("A", 10)
+	coupon := Coupon{Code: "MIN", Kind: DiscountFixed, Value: 5, MinPrice: 50}
+
+	_, err := coupon.Apply([]CouponLine{{Product: *a, Quantity: 2}}, time.Now())
+	assert.Equal(t, ErrCouponMinimumNotReached, err)
+}
+
+func TestCouponApplyWhenExpired(t *testing.T) {
+	a, _ := NewProduct("A", 10)
+	expired := time.Now().Add(-time.Minute)
+	coupon := Coupon{Code: "OLD", Kind: DiscountFixed, Value: 5, ExpiresAt: &expired}
+
+	_, err := coupon.Apply([]CouponLine{{Product: *a, Quantity: 1}}, time.Now())
+	assert.Equal(t, ErrCouponExpired, err)
+}
+
+func TestCouponApplyWhenNotApplicable(t *testing.T) {
+	a, _ := NewProduct("A", 10)
+	coupon := Coupon{Code: "TAG", Kind: DiscountFixed, Value: 5, Tags: StringList{"shoes"}}
+
+	_, err := coupon.Apply([]CouponLine{{Product: *a, Quantity: 1}}, time.Now())
+	assert.Equal(t, ErrCouponNotApplicable, err)
+}
@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/bhyago/crud-products-go/pkg/similarity"
+)
+
+// DefaultDuplicateThreshold is the trigram similarity from which two product
+// names are considered duplicates. "iPhone 15" and "iPhone 15 Pro" stay below
+// it.
+const DefaultDuplicateThreshold = 0.7
+
+var ErrThresholdInvalid = errors.New("threshold must be greater than 0 and at most 1")
+
+type ProductName struct {
+	ID   entity.ID `json:"product_id"`
+	Name string    `json:"name"`
+}
+
+type DuplicateCandidate struct {
+	ProductID  string  `json:"product_id"`
+	Name       string  `json:"name"`
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateCluster groups products whose names are similar, directly or
+// through another product of the cluster. Similarity is the one of the
+// closest pair.
+type DuplicateCluster struct {
+	Products   []ProductName `json:"products"`
+	Similarity float64       `json:"similarity"`
+}
+
+func IsValidThreshold(threshold float64) bool {
+	return threshold > 0 && threshold <= 1
+}
+
+// FindDuplicates returns the products whose name is at least threshold
+// similar to name, the most similar first.
+func FindDuplicates(name string, products []ProductName, threshold float64) []DuplicateCandidate {
+	trigrams := similarity.Trigrams(name)
+	candidates := []DuplicateCandidate{}
+	for _, product := range products {
+		score := similarity.Jaccard(trigrams, similarity.Trigrams(product.Name))
+		if score >= threshold {
+			candidates = append(candidates, DuplicateCandidate{ProductID: product.ID.String(), Name: product.Name, Similarity: roundCents(score)})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+	return candidates
+}
+
+// ClusterDuplicates groups the products whose names are at least threshold
+// similar. Only the pairs sharing a trigram are compared.
+func ClusterDuplicates(products []ProductName, threshold float64) []DuplicateCluster {
+	sets := make([]map[string]struct{}, len(products))
+	index := map[string][]int{}
+	for i, product := range products {
+		sets[i] = similarity.Trigrams(product.Name)
+		for trigram := range sets[i] {
+			index[trigram] = append(index[trigram], i)
+		}
+	}
+
+	parent := make([]int, len(products))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	best := map[int]float64{}
+
+	for i := range products {
+		shared := map[int]int{}
+		for trigram := range sets[i] {
+			for _, j := range index[trigram] {
+				if j > i {
+					shared[j]++
+				}
+			}
+		}
+		for j, count := range shared {
+			score := float64(count) / float64(len(sets[i])+len(sets[j])-count)
+			if score < threshold {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parent[rj] = ri
+				best[ri] = max(best[ri], best[rj])
+			}
+			best[ri] = max(best[ri], score)
+		}
+	}
+
+	members := map[int][]ProductName{}
+	for i, product := range products {
+		root := find(i)
+		members[root] = append(members[root], product)
+	}
+	clusters := []DuplicateCluster{}
+	for root, names := range members {
+		if len(names) > 1 {
+			clusters = append(clusters, DuplicateCluster{Products: names, Similarity: roundCents(best[root])})
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Similarity != clusters[j].Similarity {
+			return clusters[i].Similarity > clusters[j].Similarity
+		}
+		return clusters[i].Products[0].Name < clusters[j].Products[0].Name
+	})
+	return clusters
+}
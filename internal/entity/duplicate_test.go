@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func productNames(names ...string) []ProductName {
+	products := make([]ProductName, len(names))
+	for i, name := range names {
+		products[i] = ProductName{ID: entity.NewID(), Name: name}
+	}
+	return products
+}
+
+func TestFindDuplicates(t *testing.T) {
+	products := productNames("iPhone 15", "iPhone 15 Pro", "Kindle", "IPHONE-15")
+
+	candidates := FindDuplicates("Iphone15 ", products, DefaultDuplicateThreshold)
+	assert.Len(t, candidates, 2)
+	assert.Equal(t, "iPhone 15", candidates[0].Name)
+	assert.Equal(t, 1.0, candidates[0].Similarity)
+	assert.Equal(t, "IPHONE-15", candidates[1].Name)
+
+	assert.Empty(t, FindDuplicates("Echo Dot", products, DefaultDuplicateThreshold))
+}
+
+func TestClusterDuplicates(t *testing.T) {
+	products := productNames("iPhone 15", "Kindle Paperwhite", "Iphone15 ", "Kindle Paperwhite 2", "Echo Dot", "iphone 15!")
+
+	clusters := ClusterDuplicates(products, DefaultDuplicateThreshold)
+	assert.Len(t, clusters, 2)
+	assert.Equal(t, 1.0, clusters[0].Similarity)
+	assert.Len(t, clusters[0].Products, 3)
+	assert.Equal(t, "Kindle Paperwhite", clusters[1].Products[0].Name)
+	assert.Len(t, clusters[1].Products, 2)
+}
+
+func TestIsValidThreshold(t *testing.T) {
+	assert.True(t, IsValidThreshold(0.5))
+	assert.True(t, IsValidThreshold(1))
+	assert.False(t, IsValidThreshold(0))
+	assert.False(t, IsValidThreshold(1.1))
+}
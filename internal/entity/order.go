@@ -0,0 +1,184 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderCancelled = "cancelled"
+)
+
+var (
+	ErrOrderEmpty             = errors.New("order requires at least one line")
+	ErrOrderStatusInvalid     = errors.New("status must be pending, paid, shipped or cancelled")
+	ErrOrderStatusTransition  = errors.New("order cannot move to this status")
+	ErrOrderNotCancellable    = errors.New("only pending orders can be cancelled")
+	ErrOrderPriceChanged      = errors.New("product price changed")
+	ErrIdempotencyKeyRequired = errors.New("idempotency key is required")
+	ErrIdempotencyKeyTooLong  = errors.New("idempotency key is too long")
+)
+
+// MaxIdempotencyKeyLength bounds the client-supplied key of an order.
+const MaxIdempotencyKeyLength = 255
+
+// orderTransitions lists the statuses each status can move to.
+var orderTransitions = map[string][]string{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderShipped, OrderCancelled},
+}
+
+// Order is a purchase. Its lines keep the product names and prices of the
+// moment it was placed, whatever happens to the catalog afterwards.
+type Order struct {
+	ID             entity.ID   `json:"id"`
+	UserID         entity.ID   `json:"user_id" gorm:"uniqueIndex:idx_order_user_key;index"`
+	IdempotencyKey string      `json:"idempotency_key" gorm:"uniqueIndex:idx_order_user_key"`
+	Status         string      `json:"status" gorm:"index"`
+	Lines          []OrderLine `json:"lines" gorm:"foreignKey:OrderID"`
+	Total          float64     `json:"total"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+type OrderLine struct {
+	OrderID   entity.ID `json:"-" gorm:"primaryKey"`
+	ProductID entity.ID `json:"product_id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice float64   `json:"unit_price"`
+	Total     float64   `json:"total"`
+}
+
+// OrderItem is a line requested at checkout. ExpectedPrice is the unit price
+// the customer was shown; when set, checkout fails if the price moved.
+type OrderItem struct {
+	ProductID     string
+	Quantity      int
+	ExpectedPrice *float64
+}
+
+// OrderPriceError reports the product whose price no longer matches the
+// price the customer was shown.
+type OrderPriceError struct {
+	ProductID string
+	Expected  float64
+	Current   float64
+}
+
+func (e *OrderPriceError) Error() string {
+	return ErrOrderPriceChanged.Error() + ": " + e.ProductID
+}
+
+func (e *OrderPriceError) Unwrap() error {
+	return ErrOrderPriceChanged
+}
+
+func NewOrder(userID entity.ID, idempotencyKey string) (*Order, error) {
+	if idempotencyKey == "" {
+		return nil, ErrIdempotencyKeyRequired
+	}
+	if len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, ErrIdempotencyKeyTooLong
+	}
+
+	now := time.Now()
+	return &Order{
+		ID:             entity.NewID(),
+		UserID:         userID,
+		IdempotencyKey: idempotencyKey,
+		Status:         OrderPending,
+		Lines:          []OrderLine{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// AddLine snapshots the current price of the product into a new line. The
+// promotions running at checkout must already be applied to product.
+func (o *Order) AddLine(product *Product, quantity int, expectedPrice *float64) error {
+	if quantity <= 0 {
+		return ErrQuantityInvalid
+	}
+	if !product.Available {
+		return ErrProductUnavailable
+	}
+
+	price := currentPrice(product)
+	if expectedPrice != nil && *expectedPrice != price {
+		return &OrderPriceError{ProductID: product.ID.String(), Expected: *expectedPrice, Current: price}
+	}
+
+	for i := range o.Lines {
+		if o.Lines[i].ProductID == product.ID {
+			o.Lines[i].Quantity += quantity
+			o.Lines[i].Total = roundCents(o.Lines[i].UnitPrice * float64(o.Lines[i].Quantity))
+			o.computeTotal()
+			return nil
+		}
+	}
+
+	o.Lines = append(o.Lines, OrderLine{
+		OrderID:   o.ID,
+		ProductID: product.ID,
+		Name:      product.Name,
+		Quantity:  quantity,
+		UnitPrice: price,
+		Total:     roundCents(price * float64(quantity)),
+	})
+	o.computeTotal()
+	return nil
+}
+
+func (o *Order) Validate() error {
+	if len(o.Lines) == 0 {
+		return ErrOrderEmpty
+	}
+	if !IsOrderStatus(o.Status) {
+		return ErrOrderStatusInvalid
+	}
+	return nil
+}
+
+// CanTransitionTo reports whether the order can move to status.
+func (o *Order) CanTransitionTo(status string) bool {
+	for _, next := range orderTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Order) TransitionTo(status string) error {
+	if !IsOrderStatus(status) {
+		return ErrOrderStatusInvalid
+	}
+	if !o.CanTransitionTo(status) {
+		return ErrOrderStatusTransition
+	}
+	o.Status = status
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+func IsOrderStatus(status string) bool {
+	switch status {
+	case OrderPending, OrderPaid, OrderShipped, OrderCancelled:
+		return true
+	}
+	return false
+}
+
+func (o *Order) computeTotal() {
+	total := 0.0
+	for _, line := range o.Lines {
+		total += line.Total
+	}
+	o.Total = roundCents(total)
+}
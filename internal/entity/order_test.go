@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOrder(t *testing.T) {
+	order, err := NewOrder(entity.NewID(), "key-1")
+	assert.Nil(t, err)
+	assert.Equal(t, OrderPending, order.Status)
+	assert.Equal(t, ErrOrderEmpty, order.Validate())
+
+	_, err = NewOrder(entity.NewID(), "")
+	assert.Equal(t, ErrIdempotencyKeyRequired, err)
+}
+
+func TestOrderAddLine(t *testing.T) {
+	first, _ := NewProduct("Product 1", 10.5)
+	second, _ := NewProduct("Product 2", 20)
+	order, _ := NewOrder(entity.NewID(), "key-1")
+
+	assert.Nil(t, order.AddLine(first, 2, nil))
+	assert.Nil(t, order.AddLine(second, 1, nil))
+	assert.Nil(t, order.AddLine(first, 1, nil))
+	assert.Len(t, order.Lines, 2)
+	assert.Equal(t, 3, order.Lines[0].Quantity)
+	assert.Equal(t, 31.5, order.Lines[0].Total)
+	assert.Equal(t, 51.5, order.Total)
+	assert.Nil(t, order.Validate())
+}
+
+func TestOrderAddLineWhenPriceChanged(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	order, _ := NewOrder(entity.NewID(), "key-1")
+	expected := 9.0
+
+	err := order.AddLine(product, 1, &expected)
+	assert.True(t, errors.Is(err, ErrOrderPriceChanged))
+	var priceErr *OrderPriceError
+	assert.True(t, errors.As(err, &priceErr))
+	assert.Equal(t, 10.0, priceErr.Current)
+	assert.Empty(t, order.Lines)
+}
+
+func TestOrderAddLineWhenUnavailable(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	product.Available = false
+	order, _ := NewOrder(entity.NewID(), "key-1")
+
+	assert.Equal(t, ErrProductUnavailable, order.AddLine(product, 1, nil))
+	assert.Equal(t, ErrQuantityInvalid, order.AddLine(product, 0, nil))
+}
+
+func TestOrderTransitions(t *testing.T) {
+	order, _ := NewOrder(entity.NewID(), "key-1")
+
+	assert.Equal(t, ErrOrderStatusTransition, order.TransitionTo(OrderShipped))
+	assert.Nil(t, order.TransitionTo(OrderPaid))
+	assert.Nil(t, order.TransitionTo(OrderShipped))
+	assert.Equal(t, ErrOrderStatusTransition, order.TransitionTo(OrderCancelled))
+	assert.Equal(t, ErrOrderStatusInvalid, order.TransitionTo("lost"))
+}
@@ -3,30 +3,64 @@ package entity
 import (
 	"errors"
 	"time"
+	"unicode/utf8"
 
+	"github.com/bhyago/crud-products-go/pkg/barcode"
 	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/bhyago/crud-products-go/pkg/markdown"
+	"github.com/bhyago/crud-products-go/pkg/slug"
 )
 
+// MaxDescriptionLength is the maximum number of characters of a Markdown description.
+const MaxDescriptionLength = 5000
+
 var (
-	ErrIDIsRequired    = errors.New("ID is required")
-	ErrInvalidID       = errors.New("ID is invalid")
-	ErrNameRequired    = errors.New("name is required")
-	ErrPriceInvalid    = errors.New("price is invali")
-	ErrPriceIsRequired = errors.New("price is required")
+	ErrIDIsRequired        = errors.New("ID is required")
+	ErrInvalidID           = errors.New("ID is invalid")
+	ErrNameRequired        = errors.New("name is required")
+	ErrPriceInvalid        = errors.New("price is invali")
+	ErrPriceIsRequired     = errors.New("price is required")
+	ErrDescriptionTooLong  = errors.New("description is too long")
+	ErrReorderPointInvalid = errors.New("reorder_point must not be negative")
+	ErrGTINTaken           = errors.New("gtin is already used by another product")
 )
 
 type Product struct {
-	ID        entity.ID `json:"id"`
-	Name      string    `json:"name"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              entity.ID         `json:"id"`
+	Name            string            `json:"name"`
+	Slug            string            `json:"slug" gorm:"uniqueIndex"`
+	GTIN            *string           `json:"gtin,omitempty" gorm:"column:gtin;uniqueIndex"`
+	Type            string            `json:"type"`
+	Available       bool              `json:"available"`
+	Price           float64           `json:"price"`
+	Pricing         string            `json:"pricing,omitempty"`
+	Discount        float64           `json:"discount,omitempty"`
+	Components      []BundleComponent `json:"components,omitempty" gorm:"foreignKey:BundleID"`
+	Tags            StringList        `json:"tags"`
+	TaxClassID      *entity.ID        `json:"tax_class_id,omitempty"`
+	Description     string            `json:"description"`
+	DescriptionHTML string            `json:"description_html"`
+	Rating          RatingSummary     `json:"rating" gorm:"embedded;embeddedPrefix:rating_"`
+	CostPrice       float64           `json:"-" gorm:"not null;default:0"`
+	ReorderPoint    int               `json:"reorder_point" gorm:"not null;default:0"`
+	Weight          Weight            `json:"weight" gorm:"embedded;embeddedPrefix:weight_"`
+	Dimensions      Dimensions        `json:"dimensions" gorm:"embedded;embeddedPrefix:dimension_"`
+	CreatedAt       time.Time         `json:"created_at"`
+	Locale          string            `json:"locale,omitempty" gorm:"-"`
+
+	EffectivePrice      float64  `json:"effective_price" gorm:"-"`
+	AppliedPromotionIDs []string `json:"applied_promotion_ids" gorm:"-"`
 }
 
 func NewProduct(name string, price float64) (*Product, error) {
 	product := &Product{
 		ID:        entity.NewID(),
 		Name:      name,
+		Slug:      slug.Make(name),
+		Type:      ProductTypeSimple,
+		Available: true,
 		Price:     price,
+		Tags:      StringList{},
 		CreatedAt: time.Now(),
 	}
 
@@ -50,7 +84,7 @@ func (p *Product) Validate() error {
 		return ErrNameRequired
 	}
 
-	if p.Price == 0 {
+	if p.Price == 0 && !(p.IsBundle() && p.Pricing == PricingDerived) {
 		return ErrPriceIsRequired
 	}
 
@@ -58,5 +92,88 @@ func (p *Product) Validate() error {
 		return ErrPriceInvalid
 	}
 
+	if utf8.RuneCountInString(p.Description) > MaxDescriptionLength {
+		return ErrDescriptionTooLong
+	}
+
+	if p.GTIN != nil && !barcode.Valid(*p.GTIN) {
+		return barcode.ErrInvalid
+	}
+
+	if err := p.Weight.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.Dimensions.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.validateBundle(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Clone returns a copy of the product under a new ID, to be saved as another
+// product. The slug is derived again from the name; the GTIN, rating and
+// cost, which belong to the original product, are not copied.
+func (p *Product) Clone() *Product {
+	clone := *p
+	clone.ID = entity.NewID()
+	clone.Slug = slug.Make(p.Name)
+	clone.GTIN = nil
+	clone.Tags = append(StringList{}, p.Tags...)
+	clone.SetComponents(p.Components)
+	clone.Rating = RatingSummary{}
+	clone.CostPrice = 0
+	clone.CreatedAt = time.Now()
+	clone.Locale = ""
+	clone.EffectivePrice = 0
+	clone.AppliedPromotionIDs = nil
+	return &clone
+}
+
+// SetGTIN sets the barcode of the product, ignoring the spaces and hyphens
+// grouping its digits. An empty code removes it.
+func (p *Product) SetGTIN(code string) error {
+	code = barcode.Normalize(code)
+	if code == "" {
+		p.GTIN = nil
+		return nil
+	}
+	if !barcode.Valid(code) {
+		return barcode.ErrInvalid
+	}
+	p.GTIN = &code
+	return nil
+}
+
+// SetReorderPoint sets the stock level under which the product is reported
+// as low on stock. Zero disables low-stock alerts for the product.
+func (p *Product) SetReorderPoint(point int) error {
+	if point < 0 {
+		return ErrReorderPointInvalid
+	}
+	p.ReorderPoint = point
 	return nil
 }
+
+// SetDescription stores the Markdown description along with its sanitized
+// HTML rendering, so readers never have to render it again.
+func (p *Product) SetDescription(description string) error {
+	html, err := renderDescription(description)
+	if err != nil {
+		return err
+	}
+	p.Description = description
+	p.DescriptionHTML = html
+	return nil
+}
+
+func renderDescription(description string) (string, error) {
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return "", ErrDescriptionTooLong
+	}
+	return markdown.Render(description)
+}
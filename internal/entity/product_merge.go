@@ -0,0 +1,203 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// Merge rules tell which value a field of the merged product takes. Besides
+// these, a rule can be the ID of one of the merged products to take its value.
+const (
+	MergeRuleTarget  = "target"
+	MergeRuleUnion   = "union"
+	MergeRuleLongest = "longest"
+	MergeRuleMin     = "min"
+	MergeRuleMax     = "max"
+	MergeRuleAny     = "any"
+)
+
+var (
+	ErrMergeSourcesRequired = errors.New("at least one source product is required")
+	ErrMergeSourceInvalid   = errors.New("sources must be other products of the same type as the target")
+	ErrMergeRuleInvalid     = errors.New("merge rule is invalid")
+)
+
+// mergeRules lists the fields that can be resolved and the rules each one
+// accepts on top of target and product IDs.
+var mergeRules = map[string]StringList{
+	"name":          {MergeRuleLongest},
+	"description":   {MergeRuleLongest},
+	"price":         {MergeRuleMin, MergeRuleMax},
+	"available":     {MergeRuleAny},
+	"tags":          {MergeRuleUnion},
+	"reorder_point": {MergeRuleMin, MergeRuleMax},
+	"tax_class_id":  {},
+	"gtin":          {},
+}
+
+// defaultMergeRules apply to the fields without a rule; the others keep the
+// value of the target.
+var defaultMergeRules = map[string]string{
+	"tags": MergeRuleUnion,
+}
+
+// MergeRules maps product fields to the rule resolving them. It is stored as
+// a JSON object in a text column.
+type MergeRules map[string]string
+
+func (m MergeRules) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]string(m))
+	return string(b), err
+}
+
+func (m *MergeRules) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return errors.New("unsupported type for MergeRules")
+	}
+	return json.Unmarshal(data, (*map[string]string)(m))
+}
+
+func (MergeRules) GormDataType() string {
+	return "text"
+}
+
+// ProductMerge records that the source products were merged into the target,
+// with the rules used to resolve its fields.
+type ProductMerge struct {
+	ID        entity.ID  `json:"id"`
+	TargetID  entity.ID  `json:"target_id" gorm:"index"`
+	SourceIDs StringList `json:"source_ids"`
+	Rules     MergeRules `json:"rules"`
+	UserID    *entity.ID `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ProductRedirect points the ID of a product merged away to the product it
+// was merged into.
+type ProductRedirect struct {
+	FromID    entity.ID `json:"from_id" gorm:"primaryKey"`
+	ToID      entity.ID `json:"to_id" gorm:"index"`
+	MergeID   entity.ID `json:"merge_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MergeProducts resolves the fields of target from itself and the sources
+// according to rules, and returns the merge to record. Sources must be other
+// products of the same type; bundles keep the components of the target.
+func MergeProducts(target *Product, sources []Product, rules map[string]string, userID *entity.ID) (*ProductMerge, error) {
+	if len(sources) == 0 {
+		return nil, ErrMergeSourcesRequired
+	}
+	products := map[string]*Product{target.ID.String(): target}
+	sourceIDs := make(StringList, len(sources))
+	for i := range sources {
+		id := sources[i].ID.String()
+		if _, ok := products[id]; ok || sources[i].Type != target.Type {
+			return nil, ErrMergeSourceInvalid
+		}
+		products[id] = &sources[i]
+		sourceIDs[i] = id
+	}
+
+	resolved := MergeRules{}
+	for field, rule := range defaultMergeRules {
+		resolved[field] = rule
+	}
+	for field, rule := range rules {
+		allowed, ok := mergeRules[field]
+		if !ok || !(rule == MergeRuleTarget || products[rule] != nil || allowed.Contains(rule)) {
+			return nil, ErrMergeRuleInvalid
+		}
+		resolved[field] = rule
+	}
+
+	merged := *target
+	all := append([]Product{*target}, sources...)
+	for field, rule := range resolved {
+		if rule == MergeRuleTarget {
+			continue
+		}
+		if from := products[rule]; from != nil {
+			copyField(&merged, from, field)
+			continue
+		}
+		for i := range all {
+			mergeField(&merged, &all[i], field, rule)
+		}
+	}
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+	*target = merged
+
+	return &ProductMerge{
+		ID:        entity.NewID(),
+		TargetID:  target.ID,
+		SourceIDs: sourceIDs,
+		Rules:     resolved,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func copyField(to, from *Product, field string) {
+	switch field {
+	case "name":
+		to.Name = from.Name
+	case "description":
+		to.Description = from.Description
+		to.DescriptionHTML = from.DescriptionHTML
+	case "price":
+		to.Price = from.Price
+	case "available":
+		to.Available = from.Available
+	case "tags":
+		to.Tags = from.Tags
+	case "reorder_point":
+		to.ReorderPoint = from.ReorderPoint
+	case "tax_class_id":
+		to.TaxClassID = from.TaxClassID
+	case "gtin":
+		to.GTIN = from.GTIN
+	}
+}
+
+// mergeField folds the field of from into to.
+func mergeField(to, from *Product, field, rule string) {
+	switch rule {
+	case MergeRuleLongest:
+		if field == "name" && utf8.RuneCountInString(from.Name) > utf8.RuneCountInString(to.Name) ||
+			field == "description" && utf8.RuneCountInString(from.Description) > utf8.RuneCountInString(to.Description) {
+			copyField(to, from, field)
+		}
+	case MergeRuleMin:
+		if field == "price" && from.Price < to.Price || field == "reorder_point" && from.ReorderPoint < to.ReorderPoint {
+			copyField(to, from, field)
+		}
+	case MergeRuleMax:
+		if field == "price" && from.Price > to.Price || field == "reorder_point" && from.ReorderPoint > to.ReorderPoint {
+			copyField(to, from, field)
+		}
+	case MergeRuleAny:
+		to.Available = to.Available || from.Available
+	case MergeRuleUnion:
+		to.Tags = NormalizeTags(append(append([]string{}, to.Tags...), from.Tags...))
+	}
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeProducts(t *testing.T) {
+	target, _ := NewProduct("Kindle", 100)
+	target.Tags = StringList{"ebook"}
+	first, _ := NewProduct("Kindle Paperwhite", 120)
+	first.Available = false
+	second, _ := NewProduct("kindle", 90)
+	second.Tags = StringList{"amazon", "ebook"}
+	second.ReorderPoint = 5
+
+	_, err := MergeProducts(target, nil, nil, nil)
+	assert.Equal(t, ErrMergeSourcesRequired, err)
+	_, err = MergeProducts(target, []Product{*target}, nil, nil)
+	assert.Equal(t, ErrMergeSourceInvalid, err)
+	_, err = MergeProducts(target, []Product{*first}, map[string]string{"slug": MergeRuleTarget}, nil)
+	assert.Equal(t, ErrMergeRuleInvalid, err)
+	_, err = MergeProducts(target, []Product{*first}, map[string]string{"price": MergeRuleUnion}, nil)
+	assert.Equal(t, ErrMergeRuleInvalid, err)
+
+	merge, err := MergeProducts(target, []Product{*first, *second}, map[string]string{
+		"name":          MergeRuleLongest,
+		"price":         MergeRuleMax,
+		"available":     first.ID.String(),
+		"reorder_point": MergeRuleMax,
+	}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "Kindle Paperwhite", target.Name)
+	assert.Equal(t, 120.0, target.Price)
+	assert.False(t, target.Available)
+	assert.Equal(t, 5, target.ReorderPoint)
+	assert.Equal(t, StringList{"amazon", "ebook"}, target.Tags)
+	assert.Equal(t, StringList{first.ID.String(), second.ID.String()}, merge.SourceIDs)
+	assert.Equal(t, MergeRules{
+		"name": MergeRuleLongest, "price": MergeRuleMax, "available": first.ID.String(),
+		"reorder_point": MergeRuleMax, "tags": MergeRuleUnion,
+	}, merge.Rules)
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// ProductSlug records every slug a product has ever used. The current one is
+// mirrored on Product.Slug; the others are kept so old URLs can redirect.
+type ProductSlug struct {
+	Slug      string    `json:"slug" gorm:"primaryKey"`
+	ProductID entity.ID `json:"product_id" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
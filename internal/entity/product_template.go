@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// ProductTemplate holds the defaults of a kind of product. Products created
+// from a template start from its price, description, availability and tags;
+// the fields sent with the product override them.
+type ProductTemplate struct {
+	ID          entity.ID  `json:"id"`
+	Name        string     `json:"name" gorm:"uniqueIndex"`
+	Price       float64    `json:"price"`
+	Description string     `json:"description"`
+	Available   *bool      `json:"available"`
+	Tags        StringList `json:"tags"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func NewProductTemplate(name string, price float64, description string, available *bool, tags []string) (*ProductTemplate, error) {
+	template := &ProductTemplate{
+		ID:          entity.NewID(),
+		Name:        name,
+		Price:       price,
+		Description: description,
+		Available:   available,
+		Tags:        NormalizeTags(tags),
+		CreatedAt:   time.Now(),
+	}
+
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (t *ProductTemplate) Validate() error {
+	if t.Name == "" {
+		return ErrNameRequired
+	}
+	if t.Price < 0 {
+		return ErrPriceInvalid
+	}
+	if utf8.RuneCountInString(t.Description) > MaxDescriptionLength {
+		return ErrDescriptionTooLong
+	}
+	return nil
+}
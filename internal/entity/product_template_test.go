@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProductTemplate(t *testing.T) {
+	template, err := NewProductTemplate("T-shirt", 19.9, "Cotton", nil, []string{"Clothes", "clothes"})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, template.ID)
+	assert.Equal(t, StringList{"clothes"}, template.Tags)
+
+	_, err = NewProductTemplate("", 10, "", nil, nil)
+	assert.Equal(t, ErrNameRequired, err)
+	_, err = NewProductTemplate("T-shirt", -1, "", nil, nil)
+	assert.Equal(t, ErrPriceInvalid, err)
+}
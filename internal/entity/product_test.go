@@ -1,8 +1,11 @@
 package entity
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/bhyago/crud-products-go/pkg/barcode"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,3 +46,73 @@ func TestProductValidate(t *testing.T) {
 	assert.NotNil(t, p)
 	assert.Nil(t, p.Validate())
 }
+
+func TestProductSetDescription(t *testing.T) {
+	p, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	err = p.SetDescription("**Bold** text")
+	assert.Nil(t, err)
+	assert.Equal(t, "**Bold** text", p.Description)
+	assert.Equal(t, "<p><strong>Bold</strong> text</p>\n", p.DescriptionHTML)
+}
+
+func TestProductWhenDescriptionIsTooLong(t *testing.T) {
+	p, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	err = p.SetDescription(strings.Repeat("a", MaxDescriptionLength+1))
+	assert.Equal(t, ErrDescriptionTooLong, err)
+	assert.Empty(t, p.Description)
+}
+
+func TestProductSetReorderPoint(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	assert.Nil(t, product.SetReorderPoint(5))
+	assert.Equal(t, 5, product.ReorderPoint)
+	assert.Equal(t, ErrReorderPointInvalid, product.SetReorderPoint(-1))
+}
+
+func TestProductClone(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	product.Tags = StringList{"sale"}
+	product.Rating = RatingSummary{Average: 4, Count: 1, Sum: 4}
+	product.CostPrice = 6
+
+	clone := product.Clone()
+	assert.NotEqual(t, product.ID, clone.ID)
+	assert.Equal(t, product.Name, clone.Name)
+	assert.Equal(t, product.Price, clone.Price)
+	assert.Equal(t, RatingSummary{}, clone.Rating)
+	assert.Zero(t, clone.CostPrice)
+	assert.Nil(t, clone.Validate())
+
+	clone.Tags[0] = "new"
+	assert.Equal(t, StringList{"sale"}, product.Tags)
+}
+
+func TestProductSetGTIN(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	assert.Nil(t, product.SetGTIN("400-6381 333931"))
+	assert.Equal(t, "4006381333931", *product.GTIN)
+	assert.Nil(t, product.Validate())
+
+	assert.Equal(t, barcode.ErrInvalid, product.SetGTIN("4006381333932"))
+	assert.Equal(t, "4006381333931", *product.GTIN)
+
+	invalid := "12345678"
+	product.GTIN = &invalid
+	assert.Equal(t, barcode.ErrInvalid, product.Validate())
+
+	assert.Nil(t, product.SetGTIN(""))
+	assert.Nil(t, product.GTIN)
+}
+
+func TestProductJSONHidesCostPrice(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	product.CostPrice = 6.5
+
+	body, err := json.Marshal(product)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(body), "cost_price")
+}
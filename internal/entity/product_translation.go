@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/bhyago/crud-products-go/pkg/locale"
+)
+
+var (
+	ErrLocaleInvalid = errors.New("locale is invalid")
+)
+
+type ProductTranslation struct {
+	ID              entity.ID `json:"-"`
+	ProductID       entity.ID `json:"product_id" gorm:"uniqueIndex:idx_product_locale"`
+	Locale          string    `json:"locale" gorm:"uniqueIndex:idx_product_locale"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	DescriptionHTML string    `json:"description_html"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func NewProductTranslation(productID entity.ID, tag, name, description string) (*ProductTranslation, error) {
+	translation := &ProductTranslation{
+		ID:          entity.NewID(),
+		ProductID:   productID,
+		Locale:      locale.Normalize(tag),
+		Name:        name,
+		Description: description,
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := translation.Validate(); err != nil {
+		return nil, err
+	}
+
+	html, err := renderDescription(description)
+	if err != nil {
+		return nil, err
+	}
+	translation.DescriptionHTML = html
+
+	return translation, nil
+}
+
+func (t *ProductTranslation) Validate() error {
+	if !locale.IsValid(t.Locale) {
+		return ErrLocaleInvalid
+	}
+
+	if t.Name == "" {
+		return ErrNameRequired
+	}
+
+	if utf8.RuneCountInString(t.Description) > MaxDescriptionLength {
+		return ErrDescriptionTooLong
+	}
+
+	return nil
+}
+
+// Localize picks the translation matching the Accept-Language header and
+// applies it to the product. Products without a matching translation keep
+// their default texts and are tagged with defaultLocale.
+func (p *Product) Localize(acceptLanguage, defaultLocale string, translations []ProductTranslation) {
+	p.Locale = defaultLocale
+
+	available := make([]string, 0, len(translations)+1)
+	available = append(available, defaultLocale)
+	for _, t := range translations {
+		available = append(available, t.Locale)
+	}
+
+	tag, ok := locale.Negotiate(acceptLanguage, available)
+	if !ok || tag == defaultLocale {
+		return
+	}
+
+	for _, t := range translations {
+		if t.Locale == tag {
+			p.Name = t.Name
+			if t.Description != "" {
+				p.Description = t.Description
+				p.DescriptionHTML = t.DescriptionHTML
+			}
+			p.Locale = t.Locale
+			return
+		}
+	}
+}
+
+// MissingLocales returns the locales in supported that have no translation.
+// The default locale is never reported because it lives on the product itself.
+func MissingLocales(supported []string, defaultLocale string, translations []ProductTranslation) []string {
+	present := make(map[string]bool, len(translations))
+	for _, t := range translations {
+		present[t.Locale] = true
+	}
+
+	missing := []string{}
+	for _, tag := range supported {
+		tag = locale.Normalize(tag)
+		if tag == "" || tag == locale.Normalize(defaultLocale) || present[tag] {
+			continue
+		}
+		missing = append(missing, tag)
+	}
+	return missing
+}
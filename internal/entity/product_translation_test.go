@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProductTranslation(t *testing.T) {
+	product, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	translation, err := NewProductTranslation(product.ID, "pt_br", "Produto 1", "")
+	assert.Nil(t, err)
+	assert.Equal(t, product.ID, translation.ProductID)
+	assert.Equal(t, "pt-BR", translation.Locale)
+	assert.Equal(t, "Produto 1", translation.Name)
+}
+
+func TestProductTranslationWhenLocaleIsInvalid(t *testing.T) {
+	product, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	translation, err := NewProductTranslation(product.ID, "", "Produto 1", "")
+	assert.Nil(t, translation)
+	assert.Equal(t, ErrLocaleInvalid, err)
+}
+
+func TestProductTranslationWhenNameIsRequired(t *testing.T) {
+	product, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+
+	translation, err := NewProductTranslation(product.ID, "pt-BR", "", "")
+	assert.Nil(t, translation)
+	assert.Equal(t, ErrNameRequired, err)
+}
+
+func TestProductLocalizeFallsBackToLanguage(t *testing.T) {
+	product, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+	pt, err := NewProductTranslation(product.ID, "pt", "Produto 1", "")
+	assert.Nil(t, err)
+
+	product.Localize("pt-BR,en;q=0.8", "en-US", []ProductTranslation{*pt})
+	assert.Equal(t, "Produto 1", product.Name)
+	assert.Equal(t, "pt", product.Locale)
+}
+
+func TestProductLocalizeFallsBackToDefault(t *testing.T) {
+	product, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+	pt, err := NewProductTranslation(product.ID, "pt-BR", "Produto 1", "")
+	assert.Nil(t, err)
+
+	product.Localize("fr-FR,fr;q=0.9", "en-US", []ProductTranslation{*pt})
+	assert.Equal(t, "Product 1", product.Name)
+	assert.Equal(t, "en-US", product.Locale)
+}
+
+func TestMissingLocales(t *testing.T) {
+	product, err := NewProduct("Product 1", 10)
+	assert.Nil(t, err)
+	pt, err := NewProductTranslation(product.ID, "pt-BR", "Produto 1", "")
+	assert.Nil(t, err)
+
+	missing := MissingLocales([]string{"en-US", "pt-BR", "es"}, "en-US", []ProductTranslation{*pt})
+	assert.Equal(t, []string{"es"}, missing)
+}
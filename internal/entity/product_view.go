@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// DayLayout is the layout of the days views are rolled up by, in UTC.
+const DayLayout = "2006-01-02"
+
+// MaxViewWindow is the longest window, in days, view statistics cover.
+const MaxViewWindow = 365
+
+var ErrViewWindowInvalid = errors.New("window must be a number of days between 1d and 365d")
+
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|headless|preview|monitor`)
+
+// ProductViewDaily is the number of views of a product on a day.
+type ProductViewDaily struct {
+	ProductID entity.ID `json:"-" gorm:"primaryKey"`
+	Day       string    `json:"day" gorm:"primaryKey;size:10;index"`
+	Views     int64     `json:"views"`
+}
+
+// ViewKey identifies the counter of a product on a day.
+type ViewKey struct {
+	ProductID entity.ID
+	Day       string
+}
+
+type PopularProduct struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+	Views     int64  `json:"views"`
+}
+
+type ViewStats struct {
+	ProductID string             `json:"product_id"`
+	From      string             `json:"from"`
+	To        string             `json:"to"`
+	Total     int64              `json:"total"`
+	Days      []ProductViewDaily `json:"days"`
+}
+
+func ViewDay(t time.Time) string {
+	return t.UTC().Format(DayLayout)
+}
+
+// IsBot reports whether the user agent belongs to a crawler or another
+// automated client. Requests without a user agent are treated as bots.
+func IsBot(userAgent string) bool {
+	return strings.TrimSpace(userAgent) == "" || botUserAgent.MatchString(userAgent)
+}
+
+// ParseViewWindow parses a window such as "7d" into a number of days.
+func ParseViewWindow(window string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(window, "d"))
+	if err != nil || !strings.HasSuffix(window, "d") || days < 1 || days > MaxViewWindow {
+		return 0, ErrViewWindowInvalid
+	}
+	return days, nil
+}
+
+// WindowStart returns the first day of a window of days ending on now.
+func WindowStart(now time.Time, days int) string {
+	return ViewDay(now.AddDate(0, 0, -(days - 1)))
+}
+
+// NewViewStats builds the time series of a product over the days of the
+// window ending on now, with the days without views set to zero.
+func NewViewStats(productID entity.ID, daily []ProductViewDaily, now time.Time, days int) ViewStats {
+	views := make(map[string]int64, len(daily))
+	for _, day := range daily {
+		views[day.Day] += day.Views
+	}
+
+	stats := ViewStats{
+		ProductID: productID.String(),
+		From:      WindowStart(now, days),
+		To:        ViewDay(now),
+		Days:      make([]ProductViewDaily, 0, days),
+	}
+	for i := days - 1; i >= 0; i-- {
+		day := ViewDay(now.AddDate(0, 0, -i))
+		stats.Days = append(stats.Days, ProductViewDaily{ProductID: productID, Day: day, Views: views[day]})
+		stats.Total += views[day]
+	}
+	return stats
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBot(t *testing.T) {
+	assert.True(t, IsBot(""))
+	assert.True(t, IsBot("Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"))
+	assert.True(t, IsBot("Mozilla/5.0 HeadlessChrome/120.0"))
+	assert.False(t, IsBot("Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"))
+}
+
+func TestParseViewWindow(t *testing.T) {
+	days, err := ParseViewWindow("7d")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, days)
+
+	for _, window := range []string{"", "7", "0d", "366d", "1w", "-1d"} {
+		_, err = ParseViewWindow(window)
+		assert.Equal(t, ErrViewWindowInvalid, err, window)
+	}
+}
+
+func TestNewViewStats(t *testing.T) {
+	productID := entity.NewID()
+	now := time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC)
+	daily := []ProductViewDaily{
+		{ProductID: productID, Day: "2024-02-29", Views: 4},
+		{ProductID: productID, Day: "2024-03-02", Views: 1},
+	}
+
+	stats := NewViewStats(productID, daily, now, 3)
+	assert.Equal(t, "2024-02-29", stats.From)
+	assert.Equal(t, "2024-03-02", stats.To)
+	assert.Equal(t, int64(5), stats.Total)
+	assert.Len(t, stats.Days, 3)
+	assert.Equal(t, "2024-03-01", stats.Days[1].Day)
+	assert.Equal(t, int64(0), stats.Days[1].Views)
+}
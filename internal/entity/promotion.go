@@ -0,0 +1,190 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+)
+
+var (
+	ErrDiscountKindInvalid  = errors.New("kind must be percentage or fixed")
+	ErrDiscountValueInvalid = errors.New("value is invalid")
+	ErrWindowInvalid        = errors.New("ends_at must be after starts_at")
+	ErrPriceRangeInvalid    = errors.New("max_price must not be below min_price")
+)
+
+// Promotion is an automatic discount applied to every product matching its
+// targets while it is running. Empty targets match every product; the ones
+// set must all match.
+type Promotion struct {
+	ID         entity.ID  `json:"id"`
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	Value      float64    `json:"value"`
+	ProductIDs StringList `json:"product_ids"`
+	Tags       StringList `json:"tags"`
+	MinPrice   float64    `json:"min_price,omitempty"`
+	MaxPrice   float64    `json:"max_price,omitempty"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	Priority   int        `json:"priority"`
+	Stackable  bool       `json:"stackable"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewPromotion(name, kind string, value float64, startsAt time.Time, endsAt *time.Time) (*Promotion, error) {
+	promotion := &Promotion{
+		ID:         entity.NewID(),
+		Name:       name,
+		Kind:       kind,
+		Value:      value,
+		ProductIDs: StringList{},
+		Tags:       StringList{},
+		CreatedAt:  time.Now(),
+	}
+	promotion.SetWindow(startsAt, endsAt)
+
+	if err := promotion.Validate(); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+func (p *Promotion) Validate() error {
+	if p.Name == "" {
+		return ErrNameRequired
+	}
+
+	switch p.Kind {
+	case DiscountPercentage:
+		if p.Value <= 0 || p.Value > 100 {
+			return ErrDiscountValueInvalid
+		}
+	case DiscountFixed:
+		if p.Value <= 0 {
+			return ErrDiscountValueInvalid
+		}
+	default:
+		return ErrDiscountKindInvalid
+	}
+
+	if p.EndsAt != nil && !p.EndsAt.After(p.StartsAt) {
+		return ErrWindowInvalid
+	}
+
+	if p.MinPrice < 0 || p.MaxPrice < 0 || (p.MaxPrice > 0 && p.MaxPrice < p.MinPrice) {
+		return ErrPriceRangeInvalid
+	}
+
+	return nil
+}
+
+// SetWindow sets the date window, stored in UTC so it compares correctly in
+// the database.
+func (p *Promotion) SetWindow(startsAt time.Time, endsAt *time.Time) {
+	p.StartsAt = startsAt.UTC()
+	p.EndsAt = nil
+	if endsAt != nil {
+		end := endsAt.UTC()
+		p.EndsAt = &end
+	}
+}
+
+// IsRunning reports whether now falls inside [StartsAt, EndsAt).
+func (p *Promotion) IsRunning(now time.Time) bool {
+	if now.Before(p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || now.Before(*p.EndsAt)
+}
+
+// Matches reports whether the promotion targets the product.
+func (p *Promotion) Matches(product *Product) bool {
+	if len(p.ProductIDs) > 0 && !p.ProductIDs.Contains(product.ID.String()) {
+		return false
+	}
+
+	if len(p.Tags) > 0 {
+		tagged := false
+		for _, tag := range p.Tags {
+			if product.Tags.Contains(tag) {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+
+	if p.MinPrice > 0 && product.Price < p.MinPrice {
+		return false
+	}
+	if p.MaxPrice > 0 && product.Price > p.MaxPrice {
+		return false
+	}
+
+	return true
+}
+
+// Apply returns price after the discount, never below zero.
+func (p *Promotion) Apply(price float64) float64 {
+	switch p.Kind {
+	case DiscountPercentage:
+		price -= price * p.Value / 100
+	case DiscountFixed:
+		price -= p.Value
+	}
+	return math.Max(0, roundCents(price))
+}
+
+// ApplyPromotions sets EffectivePrice and AppliedPromotionIDs from the
+// promotions running at now. Promotions are tried by descending priority, ties
+// broken by ID so the result never depends on input order. A non-stackable
+// promotion only applies when it comes first and then ends the evaluation;
+// stackable ones apply one after another on the discounted price.
+func (p *Product) ApplyPromotions(promotions []Promotion, now time.Time) {
+	candidates := make([]Promotion, 0, len(promotions))
+	for _, promotion := range promotions {
+		if promotion.IsRunning(now) && promotion.Matches(p) {
+			candidates = append(candidates, promotion)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority > candidates[j].Priority
+		}
+		return candidates[i].ID.String() < candidates[j].ID.String()
+	})
+
+	price := p.Price
+	applied := []string{}
+	for _, promotion := range candidates {
+		if !promotion.Stackable {
+			if len(applied) > 0 {
+				continue
+			}
+			price = promotion.Apply(price)
+			applied = append(applied, promotion.ID.String())
+			break
+		}
+		price = promotion.Apply(price)
+		applied = append(applied, promotion.ID.String())
+	}
+
+	p.EffectivePrice = price
+	p.AppliedPromotionIDs = applied
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var promotionNow = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestPromotion(t *testing.T, kind string, value float64, priority int, stackable bool) Promotion {
+	promotion, err := NewPromotion("Promo", kind, value, promotionNow.Add(-time.Hour), nil)
+	assert.Nil(t, err)
+	promotion.Priority = priority
+	promotion.Stackable = stackable
+	return *promotion
+}
+
+func TestNewPromotion(t *testing.T) {
+	end := promotionNow.Add(time.Hour)
+	promotion, err := NewPromotion("Summer", DiscountPercentage, 10, promotionNow, &end)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, promotion.ID)
+	assert.Equal(t, "Summer", promotion.Name)
+}
+
+func TestPromotionWhenKindIsInvalid(t *testing.T) {
+	promotion, err := NewPromotion("Summer", "bogo", 10, promotionNow, nil)
+	assert.Nil(t, promotion)
+	assert.Equal(t, ErrDiscountKindInvalid, err)
+}
+
+func TestPromotionWhenPercentageIsInvalid(t *testing.T) {
+	promotion, err := NewPromotion("Summer", DiscountPercentage, 120, promotionNow, nil)
+	assert.Nil(t, promotion)
+	assert.Equal(t, ErrDiscountValueInvalid, err)
+}
+
+func TestPromotionWhenWindowIsInvalid(t *testing.T) {
+	end := promotionNow.Add(-time.Hour)
+	promotion, err := NewPromotion("Summer", DiscountFixed, 10, promotionNow, &end)
+	assert.Nil(t, promotion)
+	assert.Equal(t, ErrWindowInvalid, err)
+}
+
+func TestPromotionIsRunning(t *testing.T) {
+	end := promotionNow.Add(time.Hour)
+	promotion, err := NewPromotion("Summer", DiscountFixed, 10, promotionNow, &end)
+	assert.Nil(t, err)
+
+	assert.False(t, promotion.IsRunning(promotionNow.Add(-time.Second)))
+	assert.True(t, promotion.IsRunning(promotionNow))
+	assert.False(t, promotion.IsRunning(end))
+}
+
+func TestPromotionMatches(t *testing.T) {
+	product, _ := NewProduct("Product 1", 50)
+	product.Tags = NormalizeTags([]string{"Shoes"})
+
+	promotion := newTestPromotion(t, DiscountFixed, 5, 0, false)
+	assert.True(t, promotion.Matches(product))
+
+	promotion.Tags = StringList{"shirts"}
+	assert.False(t, promotion.Matches(product))
+
+	promotion.Tags = StringList{"shoes"}
+	promotion.MinPrice = 60
+	assert.False(t, promotion.Matches(product))
+
+	promotion.MinPrice = 10
+	promotion.ProductIDs = StringList{product.ID.String()}
+	assert.True(t, promotion.Matches(product))
+}
+
+func TestApplyPromotionsUsesHighestPriorityExclusive(t *testing.T) {
+	product, _ := NewProduct("Product 1", 100)
+	low := newTestPromotion(t, DiscountPercentage, 50, 1, false)
+	high := newTestPromotion(t, DiscountFixed, 10, 5, false)
+
+	product.ApplyPromotions([]Promotion{low, high}, promotionNow)
+	assert.Equal(t, 100.0, product.Price)
+	assert.Equal(t, 90.0, product.EffectivePrice)
+	assert.Equal(t, []string{high.ID.String()}, product.AppliedPromotionIDs)
+}
+
+func TestApplyPromotionsStacks(t *testing.T) {
+	product, _ := NewProduct("Product 1", 100)
+	first := newTestPromotion(t, DiscountPercentage, 10, 5, true)
+	second := newTestPromotion(t, DiscountFixed, 5, 3, true)
+	exclusive := newTestPromotion(t, DiscountPercentage, 50, 1, false)
+
+	product.ApplyPromotions([]Promotion{exclusive, second, first}, promotionNow)
+	assert.Equal(t, 85.0, product.EffectivePrice)
+	assert.Equal(t, []string{first.ID.String(), second.ID.String()}, product.AppliedPromotionIDs)
+}
+
+func TestApplyPromotionsIsDeterministic(t *testing.T) {
+	product, _ := NewProduct("Product 1", 100)
+	a := newTestPromotion(t, DiscountPercentage, 10, 1, false)
+	b := newTestPromotion(t, DiscountPercentage, 20, 1, false)
+
+	product.ApplyPromotions([]Promotion{a, b}, promotionNow)
+	first := product.AppliedPromotionIDs
+	product.ApplyPromotions([]Promotion{b, a}, promotionNow)
+	assert.Equal(t, first, product.AppliedPromotionIDs)
+}
+
+func TestApplyPromotionsIgnoresExpired(t *testing.T) {
+	product, _ := NewProduct("Product 1", 100)
+	promotion := newTestPromotion(t, DiscountFixed, 200, 1, false)
+
+	product.ApplyPromotions([]Promotion{promotion}, promotionNow.Add(-2*time.Hour))
+	assert.Equal(t, 100.0, product.EffectivePrice)
+	assert.Empty(t, product.AppliedPromotionIDs)
+
+	product.ApplyPromotions([]Promotion{promotion}, promotionNow)
+	assert.Equal(t, 0.0, product.EffectivePrice)
+}
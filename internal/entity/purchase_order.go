@@ -0,0 +1,187 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+)
+
+var (
+	ErrPurchaseOrderEmpty        = errors.New("purchase order requires at least one line")
+	ErrPurchaseOrderNotDraft     = errors.New("only draft purchase orders can be changed")
+	ErrPurchaseOrderNotSent      = errors.New("only sent purchase orders can be received")
+	ErrPurchaseOrderStatus       = errors.New("purchase order cannot move to this status")
+	ErrPurchaseOrderLineNotFound = errors.New("product is not in the purchase order")
+	ErrReceiveExceedsOrdered     = errors.New("received quantity exceeds the quantity ordered")
+)
+
+// PurchaseOrder is an order placed with a supplier. It moves from draft to
+// sent, then to partially_received and received as goods arrive.
+type PurchaseOrder struct {
+	ID         entity.ID           `json:"id"`
+	SupplierID entity.ID           `json:"supplier_id" gorm:"index"`
+	Status     string              `json:"status" gorm:"index"`
+	Notes      string              `json:"notes"`
+	Lines      []PurchaseOrderLine `json:"lines" gorm:"foreignKey:PurchaseOrderID"`
+	Total      float64             `json:"total"`
+	SentAt     *time.Time          `json:"sent_at"`
+	ReceivedAt *time.Time          `json:"received_at"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+type PurchaseOrderLine struct {
+	PurchaseOrderID  entity.ID `json:"-" gorm:"primaryKey"`
+	ProductID        entity.ID `json:"product_id" gorm:"primaryKey"`
+	Quantity         int       `json:"quantity"`
+	ReceivedQuantity int       `json:"received_quantity"`
+	UnitCost         float64   `json:"unit_cost"`
+}
+
+// Receipt is a quantity of a product delivered for a purchase order.
+type Receipt struct {
+	ProductID entity.ID
+	Quantity  int
+	UnitCost  float64
+}
+
+func NewPurchaseOrder(supplierID entity.ID, notes string) *PurchaseOrder {
+	now := time.Now()
+	return &PurchaseOrder{
+		ID:         entity.NewID(),
+		SupplierID: supplierID,
+		Status:     PurchaseOrderDraft,
+		Notes:      notes,
+		Lines:      []PurchaseOrderLine{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// SetLine orders quantity units of the product at unitCost, replacing the
+// line of the product if there is one.
+func (p *PurchaseOrder) SetLine(productID entity.ID, quantity int, unitCost float64) error {
+	if p.Status != PurchaseOrderDraft {
+		return ErrPurchaseOrderNotDraft
+	}
+	if quantity <= 0 {
+		return ErrQuantityInvalid
+	}
+	if unitCost < 0 {
+		return ErrCostInvalid
+	}
+
+	line := PurchaseOrderLine{PurchaseOrderID: p.ID, ProductID: productID, Quantity: quantity, UnitCost: unitCost}
+	for i := range p.Lines {
+		if p.Lines[i].ProductID == productID {
+			p.Lines[i] = line
+			p.computeTotal()
+			return nil
+		}
+	}
+	p.Lines = append(p.Lines, line)
+	p.computeTotal()
+	return nil
+}
+
+// ClearLines removes every line of a draft purchase order.
+func (p *PurchaseOrder) ClearLines() error {
+	if p.Status != PurchaseOrderDraft {
+		return ErrPurchaseOrderNotDraft
+	}
+	p.Lines = []PurchaseOrderLine{}
+	p.computeTotal()
+	return nil
+}
+
+func (p *PurchaseOrder) Send(now time.Time) error {
+	if p.Status != PurchaseOrderDraft {
+		return ErrPurchaseOrderStatus
+	}
+	if len(p.Lines) == 0 {
+		return ErrPurchaseOrderEmpty
+	}
+
+	p.Status = PurchaseOrderSent
+	p.SentAt = &now
+	p.UpdatedAt = now
+	return nil
+}
+
+// Receive records the quantities delivered and returns them with their unit
+// cost. Nothing is recorded when any quantity is invalid.
+func (p *PurchaseOrder) Receive(quantities map[entity.ID]int, now time.Time) ([]Receipt, error) {
+	if p.Status != PurchaseOrderSent && p.Status != PurchaseOrderPartiallyReceived {
+		return nil, ErrPurchaseOrderNotSent
+	}
+	if len(quantities) == 0 {
+		return nil, ErrPurchaseOrderEmpty
+	}
+
+	receipts := make([]Receipt, 0, len(quantities))
+	indexes := make([]int, 0, len(quantities))
+	for productID, quantity := range quantities {
+		index := p.line(productID)
+		if index < 0 {
+			return nil, ErrPurchaseOrderLineNotFound
+		}
+		line := p.Lines[index]
+		if quantity <= 0 {
+			return nil, ErrQuantityInvalid
+		}
+		if line.ReceivedQuantity+quantity > line.Quantity {
+			return nil, ErrReceiveExceedsOrdered
+		}
+		receipts = append(receipts, Receipt{ProductID: productID, Quantity: quantity, UnitCost: line.UnitCost})
+		indexes = append(indexes, index)
+	}
+
+	for i, index := range indexes {
+		p.Lines[index].ReceivedQuantity += receipts[i].Quantity
+	}
+	p.Status = PurchaseOrderReceived
+	for _, line := range p.Lines {
+		if line.ReceivedQuantity < line.Quantity {
+			p.Status = PurchaseOrderPartiallyReceived
+			break
+		}
+	}
+	if p.Status == PurchaseOrderReceived {
+		p.ReceivedAt = &now
+	}
+	p.UpdatedAt = now
+	return receipts, nil
+}
+
+func IsPurchaseOrderStatus(status string) bool {
+	switch status {
+	case PurchaseOrderDraft, PurchaseOrderSent, PurchaseOrderPartiallyReceived, PurchaseOrderReceived:
+		return true
+	}
+	return false
+}
+
+func (p *PurchaseOrder) line(productID entity.ID) int {
+	for i := range p.Lines {
+		if p.Lines[i].ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *PurchaseOrder) computeTotal() {
+	total := 0.0
+	for _, line := range p.Lines {
+		total += line.UnitCost * float64(line.Quantity)
+	}
+	p.Total = roundCents(total)
+}
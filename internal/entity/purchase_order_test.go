@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurchaseOrderLines(t *testing.T) {
+	order := NewPurchaseOrder(entity.NewID(), "")
+	productID := entity.NewID()
+
+	assert.Nil(t, order.SetLine(productID, 10, 2.5))
+	assert.Nil(t, order.SetLine(productID, 4, 3))
+	assert.Len(t, order.Lines, 1)
+	assert.Equal(t, 12.0, order.Total)
+	assert.Equal(t, ErrQuantityInvalid, order.SetLine(productID, 0, 3))
+	assert.Equal(t, ErrCostInvalid, order.SetLine(productID, 1, -3))
+}
+
+func TestPurchaseOrderSend(t *testing.T) {
+	order := NewPurchaseOrder(entity.NewID(), "")
+	assert.Equal(t, ErrPurchaseOrderEmpty, order.Send(time.Now()))
+
+	assert.Nil(t, order.SetLine(entity.NewID(), 1, 1))
+	assert.Nil(t, order.Send(time.Now()))
+	assert.Equal(t, PurchaseOrderSent, order.Status)
+	assert.NotNil(t, order.SentAt)
+	assert.Equal(t, ErrPurchaseOrderNotDraft, order.SetLine(entity.NewID(), 1, 1))
+	assert.Equal(t, ErrPurchaseOrderStatus, order.Send(time.Now()))
+}
+
+func TestPurchaseOrderReceive(t *testing.T) {
+	order := NewPurchaseOrder(entity.NewID(), "")
+	first, second := entity.NewID(), entity.NewID()
+	assert.Nil(t, order.SetLine(first, 10, 2))
+	assert.Nil(t, order.SetLine(second, 5, 4))
+
+	_, err := order.Receive(map[entity.ID]int{first: 1}, time.Now())
+	assert.Equal(t, ErrPurchaseOrderNotSent, err)
+	assert.Nil(t, order.Send(time.Now()))
+
+	receipts, err := order.Receive(map[entity.ID]int{first: 4}, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, []Receipt{{ProductID: first, Quantity: 4, UnitCost: 2}}, receipts)
+	assert.Equal(t, PurchaseOrderPartiallyReceived, order.Status)
+
+	_, err = order.Receive(map[entity.ID]int{first: 6, second: 6}, time.Now())
+	assert.Equal(t, ErrReceiveExceedsOrdered, err)
+	assert.Equal(t, 4, order.Lines[0].ReceivedQuantity)
+	_, err = order.Receive(map[entity.ID]int{entity.NewID(): 1}, time.Now())
+	assert.Equal(t, ErrPurchaseOrderLineNotFound, err)
+
+	_, err = order.Receive(map[entity.ID]int{first: 6, second: 5}, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, PurchaseOrderReceived, order.Status)
+	assert.NotNil(t, order.ReceivedAt)
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// The default lifetimes of a refresh token: it expires when unused for
+// DefaultRefreshTokenIdleTTL, and its family DefaultRefreshTokenTTL after
+// the login, however often it is rotated.
+const (
+	DefaultRefreshTokenTTL     = 30 * 24 * time.Hour
+	DefaultRefreshTokenIdleTTL = 7 * 24 * time.Hour
+)
+
+// refreshTokenBytes is the number of random bytes of a refresh token.
+const refreshTokenBytes = 32
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used, its session has been revoked")
+)
+
+// RefreshToken is an opaque token exchanged for a new access token and a new
+// refresh token. Only its hash is stored. The tokens rotated from the same
+// login form a family: the reuse of a rotated token, a sign that it leaked,
+// revokes the whole family.
+type RefreshToken struct {
+	ID            entity.ID  `json:"id"`
+	FamilyID      entity.ID  `json:"family_id" gorm:"index"`
+	UserID        entity.ID  `json:"user_id" gorm:"index"`
+	Hash          string     `json:"-" gorm:"uniqueIndex"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	IdleExpiresAt time.Time  `json:"idle_expires_at"`
+	RotatedAt     *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// NewRefreshToken starts a family of refresh tokens for the user and returns
+// its first token with the plain text to hand to the client.
+func NewRefreshToken(userID entity.ID, ttl, idleTTL time.Duration, now time.Time) (*RefreshToken, string, error) {
+	familyID := entity.NewID()
+	return newRefreshToken(familyID, userID, now.Add(ttl), idleTTL, now)
+}
+
+func newRefreshToken(familyID, userID entity.ID, expiresAt time.Time, idleTTL time.Duration, now time.Time) (*RefreshToken, string, error) {
+	secret := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(secret)
+
+	idleExpiresAt := now.Add(idleTTL)
+	if idleExpiresAt.After(expiresAt) {
+		idleExpiresAt = expiresAt
+	}
+	return &RefreshToken{
+		ID:            entity.NewID(),
+		FamilyID:      familyID,
+		UserID:        userID,
+		Hash:          HashRefreshToken(plain),
+		ExpiresAt:     expiresAt,
+		IdleExpiresAt: idleExpiresAt,
+		CreatedAt:     now,
+	}, plain, nil
+}
+
+// HashRefreshToken returns the hash under which a refresh token is stored. A
+// fast hash is enough: the tokens are random, not guessable passwords.
+func HashRefreshToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// Check tells whether the token can be rotated at now. A token already
+// rotated is reported as reused.
+func (t *RefreshToken) Check(now time.Time) error {
+	switch {
+	case t.RevokedAt != nil:
+		return ErrRefreshTokenRevoked
+	case t.RotatedAt != nil:
+		return ErrRefreshTokenReused
+	case !now.Before(t.ExpiresAt) || !now.Before(t.IdleExpiresAt):
+		return ErrRefreshTokenExpired
+	}
+	return nil
+}
+
+// Rotate marks the token as rotated and returns its successor in the family,
+// with a fresh idle lifetime but the same absolute expiry.
+func (t *RefreshToken) Rotate(idleTTL time.Duration, now time.Time) (*RefreshToken, string, error) {
+	if err := t.Check(now); err != nil {
+		return nil, "", err
+	}
+	next, plain, err := newRefreshToken(t.FamilyID, t.UserID, t.ExpiresAt, idleTTL, now)
+	if err != nil {
+		return nil, "", err
+	}
+	t.RotatedAt = &now
+	return next, plain, nil
+}
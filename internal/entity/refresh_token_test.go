@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshTokenRotation(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	token, plain, err := NewRefreshToken(entity.NewID(), 10*24*time.Hour, 7*24*time.Hour, now)
+	assert.Nil(t, err)
+	assert.Len(t, plain, 43)
+	assert.Equal(t, HashRefreshToken(plain), token.Hash)
+	assert.Equal(t, now.Add(7*24*time.Hour), token.IdleExpiresAt)
+
+	later := now.Add(6 * 24 * time.Hour)
+	next, nextPlain, err := token.Rotate(7*24*time.Hour, later)
+	assert.Nil(t, err)
+	assert.NotEqual(t, plain, nextPlain)
+	assert.Equal(t, token.FamilyID, next.FamilyID)
+	assert.Equal(t, token.ExpiresAt, next.ExpiresAt)
+	// The idle expiry never goes past the absolute one.
+	assert.Equal(t, token.ExpiresAt, next.IdleExpiresAt)
+	assert.Equal(t, ErrRefreshTokenReused, token.Check(later))
+
+	assert.Equal(t, ErrRefreshTokenExpired, next.Check(now.Add(10*24*time.Hour)))
+	next.RevokedAt = &later
+	assert.Equal(t, ErrRefreshTokenRevoked, next.Check(later))
+}
+
+func TestRefreshTokenIdleExpiry(t *testing.T) {
+	now := time.Now()
+	token, _, _ := NewRefreshToken(entity.NewID(), DefaultRefreshTokenTTL, time.Hour, now)
+	assert.Nil(t, token.Check(now.Add(59*time.Minute)))
+	_, _, err := token.Rotate(time.Hour, now.Add(time.Hour))
+	assert.Equal(t, ErrRefreshTokenExpired, err)
+}
@@ -0,0 +1,153 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	MinRating                 = 1
+	MaxRating                 = 5
+	MaxReviewTitleLength      = 120
+	MaxReviewBodyLength       = 5000
+	MaxModerationReasonLength = 500
+)
+
+var (
+	ErrRatingInvalid           = errors.New("rating must be between 1 and 5")
+	ErrReviewTitleTooLong      = errors.New("title is too long")
+	ErrReviewBodyTooLong       = errors.New("body is too long")
+	ErrReviewDuplicated        = errors.New("user already reviewed this product")
+	ErrModerationReasonTooLong = errors.New("reason is too long")
+)
+
+// Review is the opinion of a user about a product. A user reviews a product
+// at most once. Hidden reviews were moderated out: they are not listed to
+// customers and do not count in the rating of the product.
+type Review struct {
+	ID           entity.ID `json:"id"`
+	ProductID    entity.ID `json:"product_id" gorm:"uniqueIndex:idx_review_product_user"`
+	UserID       entity.ID `json:"user_id" gorm:"uniqueIndex:idx_review_product_user"`
+	Rating       int       `json:"rating"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	Hidden       bool      `json:"hidden"`
+	HiddenReason string    `json:"hidden_reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RatingSummary aggregates the visible reviews of a product. It is kept up to
+// date by the review repository as reviews change, never recomputed from
+// scratch on reads.
+type RatingSummary struct {
+	Average   float64         `json:"average" gorm:"not null;default:0;index"`
+	Count     int             `json:"count" gorm:"not null;default:0"`
+	Sum       int             `json:"-" gorm:"not null;default:0"`
+	Histogram RatingHistogram `json:"histogram" gorm:"embedded;embeddedPrefix:stars_"`
+}
+
+// RatingHistogram counts the visible reviews per number of stars.
+type RatingHistogram struct {
+	One   int `json:"1" gorm:"not null;default:0"`
+	Two   int `json:"2" gorm:"not null;default:0"`
+	Three int `json:"3" gorm:"not null;default:0"`
+	Four  int `json:"4" gorm:"not null;default:0"`
+	Five  int `json:"5" gorm:"not null;default:0"`
+}
+
+// RatingColumns are the columns of the rating summary of a product.
+var RatingColumns = []string{
+	"rating_average", "rating_count", "rating_sum",
+	"rating_stars_one", "rating_stars_two", "rating_stars_three", "rating_stars_four", "rating_stars_five",
+}
+
+// StarsColumn returns the histogram column counting reviews with rating stars.
+func StarsColumn(rating int) string {
+	return RatingColumns[2+rating]
+}
+
+func NewReview(productID, userID entity.ID, rating int, title, body string) (*Review, error) {
+	now := time.Now()
+	review := &Review{
+		ID:        entity.NewID(),
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    rating,
+		Title:     title,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := review.Validate(); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (r *Review) Validate() error {
+	if r.Rating < MinRating || r.Rating > MaxRating {
+		return ErrRatingInvalid
+	}
+	if utf8.RuneCountInString(r.Title) > MaxReviewTitleLength {
+		return ErrReviewTitleTooLong
+	}
+	if utf8.RuneCountInString(r.Body) > MaxReviewBodyLength {
+		return ErrReviewBodyTooLong
+	}
+	if utf8.RuneCountInString(r.HiddenReason) > MaxModerationReasonLength {
+		return ErrModerationReasonTooLong
+	}
+	return nil
+}
+
+// Edit replaces the content of the review.
+func (r *Review) Edit(rating int, title, body string) error {
+	edited := *r
+	edited.Rating = rating
+	edited.Title = title
+	edited.Body = body
+	if err := edited.Validate(); err != nil {
+		return err
+	}
+
+	edited.UpdatedAt = time.Now()
+	*r = edited
+	return nil
+}
+
+// Moderate hides or shows the review. The reason is only kept while hidden.
+func (r *Review) Moderate(hidden bool, reason string) error {
+	if !hidden {
+		reason = ""
+	}
+	if utf8.RuneCountInString(reason) > MaxModerationReasonLength {
+		return ErrModerationReasonTooLong
+	}
+
+	r.Hidden = hidden
+	r.HiddenReason = reason
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// Stars returns how many visible reviews gave the product rating stars.
+func (h RatingHistogram) Stars(rating int) int {
+	switch rating {
+	case 1:
+		return h.One
+	case 2:
+		return h.Two
+	case 3:
+		return h.Three
+	case 4:
+		return h.Four
+	case 5:
+		return h.Five
+	}
+	return 0
+}
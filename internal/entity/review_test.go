@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReview(t *testing.T) {
+	review, err := NewReview(entity.NewID(), entity.NewID(), 4, "Good", "Works as expected")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, review.Rating)
+	assert.False(t, review.Hidden)
+}
+
+func TestNewReviewWhenRatingIsInvalid(t *testing.T) {
+	_, err := NewReview(entity.NewID(), entity.NewID(), 0, "", "")
+	assert.Equal(t, ErrRatingInvalid, err)
+	_, err = NewReview(entity.NewID(), entity.NewID(), 6, "", "")
+	assert.Equal(t, ErrRatingInvalid, err)
+}
+
+func TestNewReviewWhenTitleIsTooLong(t *testing.T) {
+	_, err := NewReview(entity.NewID(), entity.NewID(), 3, strings.Repeat("a", MaxReviewTitleLength+1), "")
+	assert.Equal(t, ErrReviewTitleTooLong, err)
+}
+
+func TestReviewEditKeepsReviewWhenInvalid(t *testing.T) {
+	review, _ := NewReview(entity.NewID(), entity.NewID(), 4, "Good", "")
+
+	assert.Equal(t, ErrRatingInvalid, review.Edit(9, "Best", ""))
+	assert.Equal(t, 4, review.Rating)
+	assert.Equal(t, "Good", review.Title)
+
+	assert.Nil(t, review.Edit(2, "Meh", "Broke after a week"))
+	assert.Equal(t, 2, review.Rating)
+}
+
+func TestReviewModerate(t *testing.T) {
+	review, _ := NewReview(entity.NewID(), entity.NewID(), 1, "Spam", "")
+
+	assert.Nil(t, review.Moderate(true, "spam"))
+	assert.True(t, review.Hidden)
+	assert.Equal(t, "spam", review.HiddenReason)
+
+	assert.Nil(t, review.Moderate(false, "ignored"))
+	assert.False(t, review.Hidden)
+	assert.Empty(t, review.HiddenReason)
+}
+
+func TestStarsColumn(t *testing.T) {
+	assert.Equal(t, "rating_stars_one", StarsColumn(1))
+	assert.Equal(t, "rating_stars_five", StarsColumn(5))
+}
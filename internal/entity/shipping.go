@@ -0,0 +1,265 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/bhyago/crud-products-go/pkg/units"
+)
+
+// DefaultVolumetricDivisor is the volume in cubic centimeters charged as one
+// kilogram, the usual ratio of couriers.
+const DefaultVolumetricDivisor = 5000
+
+var (
+	ErrWeightInvalid     = errors.New("weight must not be negative and its unit must be kg or lb")
+	ErrDimensionsInvalid = errors.New("dimensions must not be negative and their unit must be cm or in")
+	ErrWeightMissing     = errors.New("product has no weight")
+	ErrMethodsRequired   = errors.New("shipping zone requires at least one method")
+	ErrRatesRequired     = errors.New("shipping method requires at least one rate")
+	ErrRateInvalid       = errors.New("rates must have distinct positive up_to weights and prices not negative")
+	ErrMethodInvalid     = errors.New("shipping method requires a name, a weight unit of kg or lb, a positive volumetric divisor and delivery days not negative")
+)
+
+// Weight is a weight in kilograms or pounds. Zero means unknown.
+type Weight struct {
+	Value float64 `json:"value" gorm:"not null;default:0"`
+	Unit  string  `json:"unit"`
+}
+
+// Dimensions are the length, width and height of a package in centimeters
+// or inches. Zero means unknown.
+type Dimensions struct {
+	Length float64 `json:"length" gorm:"not null;default:0"`
+	Width  float64 `json:"width" gorm:"not null;default:0"`
+	Height float64 `json:"height" gorm:"not null;default:0"`
+	Unit   string  `json:"unit"`
+}
+
+// Validate checks the value and the unit, which may only be left empty while
+// the weight is not set.
+func (w Weight) Validate() error {
+	if w.Value < 0 || (w.Value > 0 || w.Unit != "") && !units.IsWeight(w.Unit) {
+		return ErrWeightInvalid
+	}
+	return nil
+}
+
+func (w Weight) Kilograms() float64 {
+	return units.ToKilograms(w.Value, w.Unit)
+}
+
+// Validate checks the sizes and the unit, which may only be left empty while
+// the dimensions are not set.
+func (d Dimensions) Validate() error {
+	if d.Length < 0 || d.Width < 0 || d.Height < 0 {
+		return ErrDimensionsInvalid
+	}
+	if (d.Length*d.Width*d.Height > 0 || d.Unit != "") && !units.IsLength(d.Unit) {
+		return ErrDimensionsInvalid
+	}
+	return nil
+}
+
+// CubicCentimeters returns the volume of the package.
+func (d Dimensions) CubicCentimeters() float64 {
+	return units.ToCentimeters(d.Length, d.Unit) * units.ToCentimeters(d.Width, d.Unit) * units.ToCentimeters(d.Height, d.Unit)
+}
+
+// SetWeight sets the weight of the product, in kilograms unless a unit is
+// given.
+func (p *Product) SetWeight(weight Weight) error {
+	if weight.Unit == "" {
+		weight.Unit = units.Kilogram
+	}
+	if err := weight.Validate(); err != nil {
+		return err
+	}
+	p.Weight = weight
+	return nil
+}
+
+// SetDimensions sets the dimensions of the product, in centimeters unless a
+// unit is given.
+func (p *Product) SetDimensions(dimensions Dimensions) error {
+	if dimensions.Unit == "" {
+		dimensions.Unit = units.Centimeter
+	}
+	if err := dimensions.Validate(); err != nil {
+		return err
+	}
+	p.Dimensions = dimensions
+	return nil
+}
+
+// ShippingZone is a destination with the shipping methods serving it.
+type ShippingZone struct {
+	ID        entity.ID        `json:"id"`
+	Name      string           `json:"name" gorm:"uniqueIndex"`
+	Methods   []ShippingMethod `json:"methods" gorm:"foreignKey:ZoneID"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// ShippingMethod prices a shipment by its chargeable weight, the greater of
+// its actual weight and its volume divided by VolumetricDivisor (cubic
+// centimeters per kilogram). The rates apply up to a weight in WeightUnit.
+type ShippingMethod struct {
+	ID                entity.ID      `json:"id"`
+	ZoneID            entity.ID      `json:"-" gorm:"index"`
+	Name              string         `json:"name"`
+	DeliveryDays      int            `json:"delivery_days"`
+	WeightUnit        string         `json:"weight_unit"`
+	VolumetricDivisor float64        `json:"volumetric_divisor"`
+	Rates             []ShippingRate `json:"rates" gorm:"foreignKey:MethodID"`
+}
+
+// ShippingRate is the price of the shipments weighing up to UpTo.
+type ShippingRate struct {
+	MethodID entity.ID `json:"-" gorm:"primaryKey"`
+	UpTo     float64   `json:"up_to" gorm:"primaryKey"`
+	Price    float64   `json:"price"`
+}
+
+// ShippingLine is a product and quantity to ship.
+type ShippingLine struct {
+	Product  Product
+	Quantity int
+}
+
+// ShippingQuote lists the methods able to ship a set of products to a zone,
+// cheapest first. Weights are in kilograms, volume in cubic centimeters.
+type ShippingQuote struct {
+	ZoneID  string           `json:"zone_id"`
+	Weight  float64          `json:"weight"`
+	Volume  float64          `json:"volume"`
+	Options []ShippingOption `json:"options"`
+}
+
+// ShippingOption is the price of a method for a shipment. Weights are in the
+// unit of the method.
+type ShippingOption struct {
+	MethodID         string  `json:"method_id"`
+	Name             string  `json:"name"`
+	DeliveryDays     int     `json:"delivery_days"`
+	WeightUnit       string  `json:"weight_unit"`
+	VolumetricWeight float64 `json:"volumetric_weight"`
+	ChargeableWeight float64 `json:"chargeable_weight"`
+	Price            float64 `json:"price"`
+}
+
+func NewShippingZone(name string, methods []ShippingMethod) (*ShippingZone, error) {
+	zone := &ShippingZone{
+		ID:        entity.NewID(),
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+	zone.SetMethods(methods)
+
+	if err := zone.Validate(); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// SetMethods replaces the methods of the zone under new IDs, defaulting
+// their weight unit to kilograms and their divisor to
+// DefaultVolumetricDivisor, with their rates by increasing weight.
+func (z *ShippingZone) SetMethods(methods []ShippingMethod) {
+	z.Methods = make([]ShippingMethod, len(methods))
+	for i, method := range methods {
+		method.ID = entity.NewID()
+		method.ZoneID = z.ID
+		if method.WeightUnit == "" {
+			method.WeightUnit = units.Kilogram
+		}
+		if method.VolumetricDivisor == 0 {
+			method.VolumetricDivisor = DefaultVolumetricDivisor
+		}
+		rates := make([]ShippingRate, len(method.Rates))
+		for j, rate := range method.Rates {
+			rate.MethodID = method.ID
+			rates[j] = rate
+		}
+		sort.Slice(rates, func(a, b int) bool { return rates[a].UpTo < rates[b].UpTo })
+		method.Rates = rates
+		z.Methods[i] = method
+	}
+}
+
+func (z *ShippingZone) Validate() error {
+	if z.Name == "" {
+		return ErrNameRequired
+	}
+	if len(z.Methods) == 0 {
+		return ErrMethodsRequired
+	}
+	for _, method := range z.Methods {
+		if method.Name == "" || !units.IsWeight(method.WeightUnit) || method.VolumetricDivisor <= 0 || method.DeliveryDays < 0 {
+			return ErrMethodInvalid
+		}
+		if len(method.Rates) == 0 {
+			return ErrRatesRequired
+		}
+		for i, rate := range method.Rates {
+			if rate.UpTo <= 0 || rate.Price < 0 || i > 0 && rate.UpTo == method.Rates[i-1].UpTo {
+				return ErrRateInvalid
+			}
+		}
+	}
+	return nil
+}
+
+// Quote prices the shipment of lines with each method of the zone. The
+// methods whose rates stop below the chargeable weight are left out.
+func (z *ShippingZone) Quote(lines []ShippingLine) (*ShippingQuote, error) {
+	var kilograms, volume float64
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, ErrQuantityInvalid
+		}
+		if line.Product.Weight.Value == 0 {
+			return nil, ErrWeightMissing
+		}
+		kilograms += float64(line.Quantity) * line.Product.Weight.Kilograms()
+		volume += float64(line.Quantity) * line.Product.Dimensions.CubicCentimeters()
+	}
+
+	quote := &ShippingQuote{
+		ZoneID:  z.ID.String(),
+		Weight:  roundWeight(kilograms),
+		Volume:  math.Round(volume),
+		Options: []ShippingOption{},
+	}
+	for _, method := range z.Methods {
+		volumetric := units.FromKilograms(volume/method.VolumetricDivisor, method.WeightUnit)
+		chargeable := math.Max(units.FromKilograms(kilograms, method.WeightUnit), volumetric)
+		for _, rate := range method.Rates {
+			if chargeable <= rate.UpTo {
+				quote.Options = append(quote.Options, ShippingOption{
+					MethodID:         method.ID.String(),
+					Name:             method.Name,
+					DeliveryDays:     method.DeliveryDays,
+					WeightUnit:       method.WeightUnit,
+					VolumetricWeight: roundWeight(volumetric),
+					ChargeableWeight: roundWeight(chargeable),
+					Price:            roundCents(rate.Price),
+				})
+				break
+			}
+		}
+	}
+	sort.SliceStable(quote.Options, func(i, j int) bool {
+		if quote.Options[i].Price != quote.Options[j].Price {
+			return quote.Options[i].Price < quote.Options[j].Price
+		}
+		return quote.Options[i].DeliveryDays < quote.Options[j].DeliveryDays
+	})
+	return quote, nil
+}
+
+func roundWeight(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductSetWeightAndDimensions(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	assert.Nil(t, product.SetWeight(Weight{Value: 2}))
+	assert.Equal(t, Weight{Value: 2, Unit: "kg"}, product.Weight)
+	assert.Equal(t, ErrWeightInvalid, product.SetWeight(Weight{Value: 2, Unit: "oz"}))
+	assert.Equal(t, ErrWeightInvalid, product.SetWeight(Weight{Value: -1}))
+	assert.Equal(t, ErrWeightInvalid, product.SetWeight(Weight{Value: 0, Unit: "oz"}))
+	assert.Nil(t, Weight{}.Validate())
+
+	assert.Nil(t, product.SetDimensions(Dimensions{Length: 10, Width: 10, Height: 10, Unit: "in"}))
+	assert.InDelta(t, 16387.064, product.Dimensions.CubicCentimeters(), 1e-6)
+	assert.Equal(t, ErrDimensionsInvalid, product.SetDimensions(Dimensions{Length: -1}))
+	assert.Equal(t, ErrDimensionsInvalid, product.SetDimensions(Dimensions{Unit: "ft"}))
+	assert.Nil(t, Dimensions{}.Validate())
+	assert.Nil(t, product.Validate())
+}
+
+func TestNewShippingZone(t *testing.T) {
+	zone, err := NewShippingZone("Domestic", []ShippingMethod{
+		{Name: "Standard", Rates: []ShippingRate{{UpTo: 5, Price: 10}, {UpTo: 1, Price: 5}}},
+	})
+	assert.Nil(t, err)
+	method := zone.Methods[0]
+	assert.Equal(t, zone.ID, method.ZoneID)
+	assert.Equal(t, "kg", method.WeightUnit)
+	assert.Equal(t, float64(DefaultVolumetricDivisor), method.VolumetricDivisor)
+	assert.Equal(t, 1.0, method.Rates[0].UpTo)
+	assert.Equal(t, method.ID, method.Rates[0].MethodID)
+
+	_, err = NewShippingZone("Domestic", nil)
+	assert.Equal(t, ErrMethodsRequired, err)
+	_, err = NewShippingZone("Domestic", []ShippingMethod{{Name: "Standard"}})
+	assert.Equal(t, ErrRatesRequired, err)
+	_, err = NewShippingZone("Domestic", []ShippingMethod{{Name: "Standard", Rates: []ShippingRate{{UpTo: 1}, {UpTo: 1}}}})
+	assert.Equal(t, ErrRateInvalid, err)
+	_, err = NewShippingZone("Domestic", []ShippingMethod{{Name: "Standard", WeightUnit: "g", Rates: []ShippingRate{{UpTo: 1}}}})
+	assert.Equal(t, ErrMethodInvalid, err)
+}
+
+func TestShippingZoneQuote(t *testing.T) {
+	zone, _ := NewShippingZone("Domestic", []ShippingMethod{
+		{Name: "Standard", DeliveryDays: 5, Rates: []ShippingRate{{UpTo: 2, Price: 5}, {UpTo: 10, Price: 12}}},
+		{Name: "Express", DeliveryDays: 1, WeightUnit: "lb", VolumetricDivisor: 4000, Rates: []ShippingRate{{UpTo: 10, Price: 20}}},
+		{Name: "Letter", DeliveryDays: 3, Rates: []ShippingRate{{UpTo: 0.5, Price: 1}}},
+	})
+	book, _ := NewProduct("Book", 10)
+	book.SetWeight(Weight{Value: 1, Unit: "lb"})
+	pillow, _ := NewProduct("Pillow", 10)
+	pillow.SetWeight(Weight{Value: 0.5})
+	pillow.SetDimensions(Dimensions{Length: 50, Width: 40, Height: 10})
+
+	quote, err := zone.Quote([]ShippingLine{{Product: *book, Quantity: 2}, {Product: *pillow, Quantity: 1}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1.407, quote.Weight)
+	assert.Equal(t, 20000.0, quote.Volume)
+	// 20000 cm³ / 5000 = 4 kg outweighs the 1.407 kg of the products.
+	// Express charges 20000 cm³ / 4000 = 5 kg, or 11.023 lb, over its last
+	// rate, and Letter stops at 0.5 kg.
+	assert.Len(t, quote.Options, 1)
+	assert.Equal(t, "Standard", quote.Options[0].Name)
+	assert.Equal(t, 4.0, quote.Options[0].VolumetricWeight)
+	assert.Equal(t, 4.0, quote.Options[0].ChargeableWeight)
+	assert.Equal(t, 12.0, quote.Options[0].Price)
+
+	quote, err = zone.Quote([]ShippingLine{{Product: *book, Quantity: 1}})
+	assert.Nil(t, err)
+	assert.Len(t, quote.Options, 3)
+	assert.Equal(t, "Letter", quote.Options[0].Name)
+	assert.Equal(t, 0.454, quote.Options[0].ChargeableWeight)
+	assert.Equal(t, "Standard", quote.Options[1].Name)
+	assert.Equal(t, "Express", quote.Options[2].Name)
+	assert.Equal(t, "lb", quote.Options[2].WeightUnit)
+	assert.Equal(t, 1.0, quote.Options[2].ChargeableWeight)
+
+	_, err = zone.Quote([]ShippingLine{{Product: *book, Quantity: 0}})
+	assert.Equal(t, ErrQuantityInvalid, err)
+	unknown, _ := NewProduct("Unknown", 10)
+	_, err = zone.Quote([]ShippingLine{{Product: *unknown, Quantity: 1}})
+	assert.Equal(t, ErrWeightMissing, err)
+}
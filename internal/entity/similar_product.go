@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"math"
+	"sort"
+)
+
+// The weights of the similarity of the texts, the prices and the tags in the
+// score of a similar product.
+const (
+	SimilarTextWeight  = 0.6
+	SimilarPriceWeight = 0.2
+	SimilarTagWeight   = 0.2
+)
+
+// SimilarProduct is a product recommended for another, with its score and the
+// parts it is made of, each from 0 to 1.
+type SimilarProduct struct {
+	Product    Product `json:"product"`
+	Score      float64 `json:"score"`
+	TextScore  float64 `json:"text_score"`
+	PriceScore float64 `json:"price_score"`
+	TagScore   float64 `json:"tag_score"`
+}
+
+// SimilarText is the text compared to find similar products: the name,
+// counted twice, and the description.
+func (p *Product) SimilarText() string {
+	return p.Name + " " + p.Name + " " + p.Description
+}
+
+// PriceProximity returns 1 for equal prices, down to 0 as one becomes
+// negligible next to the other.
+func PriceProximity(a, b float64) float64 {
+	if a == b {
+		return 1
+	}
+	return 1 - math.Abs(a-b)/math.Max(a, b)
+}
+
+// TagOverlap returns the share of their tags two products have in common.
+func TagOverlap(a, b StringList) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for _, tag := range a {
+		if b.Contains(tag) {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// RankSimilar scores the candidates against product, given the similarity of
+// their texts by ID, and returns the best limit, the best first. Candidates
+// sharing neither words nor tags with product are left out, however close
+// their price.
+func RankSimilar(product Product, candidates []Product, text map[string]float64, limit int) []SimilarProduct {
+	similar := []SimilarProduct{}
+	for _, candidate := range candidates {
+		if candidate.ID == product.ID {
+			continue
+		}
+		textScore := text[candidate.ID.String()]
+		tagScore := TagOverlap(product.Tags, candidate.Tags)
+		if textScore == 0 && tagScore == 0 {
+			continue
+		}
+		priceScore := PriceProximity(product.Price, candidate.Price)
+		similar = append(similar, SimilarProduct{
+			Product:    candidate,
+			Score:      SimilarTextWeight*textScore + SimilarPriceWeight*priceScore + SimilarTagWeight*tagScore,
+			TextScore:  textScore,
+			PriceScore: priceScore,
+			TagScore:   tagScore,
+		})
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		return similar[i].Product.Name < similar[j].Product.Name
+	})
+
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	for i := range similar {
+		similar[i].Score = roundCents(similar[i].Score)
+		similar[i].TextScore = roundCents(similar[i].TextScore)
+		similar[i].PriceScore = roundCents(similar[i].PriceScore)
+		similar[i].TagScore = roundCents(similar[i].TagScore)
+	}
+	return similar
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceProximityAndTagOverlap(t *testing.T) {
+	assert.Equal(t, 1.0, PriceProximity(10, 10))
+	assert.Equal(t, 0.5, PriceProximity(10, 20))
+	assert.Equal(t, 0.5, PriceProximity(20, 10))
+	assert.Equal(t, 0.0, PriceProximity(0, 20))
+
+	assert.Equal(t, 0.5, TagOverlap(StringList{"coffee", "kitchen"}, StringList{"coffee", "espresso", "kitchen", "milk"}))
+	assert.Equal(t, 0.0, TagOverlap(StringList{"coffee"}, nil))
+}
+
+func TestRankSimilar(t *testing.T) {
+	espresso, _ := NewProduct("Espresso machine", 200)
+	espresso.Tags = StringList{"coffee"}
+	capsule, _ := NewProduct("Capsule espresso machine", 100)
+	grinder, _ := NewProduct("Coffee grinder", 200)
+	grinder.Tags = StringList{"coffee"}
+	kettle, _ := NewProduct("Kettle", 200)
+
+	text := map[string]float64{capsule.ID.String(): 0.8}
+	similar := RankSimilar(*espresso, []Product{*espresso, *capsule, *grinder, *kettle}, text, 10)
+	assert.Len(t, similar, 2)
+	// 0.6 × 0.8 + 0.2 × 0.5 + 0.2 × 0 beats 0.6 × 0 + 0.2 × 1 + 0.2 × 1.
+	assert.Equal(t, capsule.ID, similar[0].Product.ID)
+	assert.Equal(t, 0.58, similar[0].Score)
+	assert.Equal(t, 0.5, similar[0].PriceScore)
+	assert.Equal(t, grinder.ID, similar[1].Product.ID)
+	assert.Equal(t, 0.4, similar[1].Score)
+	assert.Equal(t, 1.0, similar[1].TagScore)
+
+	assert.Len(t, RankSimilar(*espresso, []Product{*capsule, *grinder}, text, 1), 1)
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+)
+
+var (
+	ErrAlertNotOpen       = errors.New("only open alerts can be acknowledged")
+	ErrAlertResolved      = errors.New("alert is already resolved")
+	ErrAlertStatusInvalid = errors.New("alert status is invalid")
+)
+
+// LowStock is a product whose stock, summed over every warehouse, is below
+// its reorder point.
+type LowStock struct {
+	ProductID    entity.ID
+	Name         string
+	ReorderPoint int
+	OnHand       int
+}
+
+// StockAlert reports a product low on stock. A product has at most one
+// alert that is not resolved; it is resolved once the stock recovers.
+type StockAlert struct {
+	ID             entity.ID  `json:"id"`
+	ProductID      entity.ID  `json:"product_id" gorm:"index"`
+	ProductName    string     `json:"product_name"`
+	Status         string     `json:"status" gorm:"index"`
+	ReorderPoint   int        `json:"reorder_point"`
+	OnHand         int        `json:"on_hand"`
+	CreatedAt      time.Time  `json:"created_at"`
+	NotifiedAt     *time.Time `json:"notified_at"`
+	DeliveredTo    StringList `json:"delivered_to"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	AcknowledgedBy *entity.ID `json:"acknowledged_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+func NewStockAlert(low LowStock, now time.Time) *StockAlert {
+	return &StockAlert{
+		ID:           entity.NewID(),
+		ProductID:    low.ProductID,
+		ProductName:  low.Name,
+		Status:       AlertOpen,
+		ReorderPoint: low.ReorderPoint,
+		OnHand:       low.OnHand,
+		CreatedAt:    now,
+	}
+}
+
+func (a *StockAlert) Acknowledge(userID entity.ID, now time.Time) error {
+	if a.Status != AlertOpen {
+		return ErrAlertNotOpen
+	}
+	a.Status = AlertAcknowledged
+	a.AcknowledgedAt = &now
+	a.AcknowledgedBy = &userID
+	return nil
+}
+
+func (a *StockAlert) Resolve(now time.Time) error {
+	if a.Status == AlertResolved {
+		return ErrAlertResolved
+	}
+	a.Status = AlertResolved
+	a.ResolvedAt = &now
+	return nil
+}
+
+func IsAlertStatus(status string) bool {
+	switch status {
+	case AlertOpen, AlertAcknowledged, AlertResolved:
+		return true
+	}
+	return false
+}
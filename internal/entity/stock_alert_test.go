@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockAlertLifecycle(t *testing.T) {
+	alert := NewStockAlert(LowStock{ProductID: entity.NewID(), Name: "Product 1", ReorderPoint: 5, OnHand: 2}, time.Now())
+	assert.Equal(t, AlertOpen, alert.Status)
+
+	userID := entity.NewID()
+	assert.Nil(t, alert.Acknowledge(userID, time.Now()))
+	assert.Equal(t, AlertAcknowledged, alert.Status)
+	assert.Equal(t, userID, *alert.AcknowledgedBy)
+	assert.Equal(t, ErrAlertNotOpen, alert.Acknowledge(userID, time.Now()))
+
+	assert.Nil(t, alert.Resolve(time.Now()))
+	assert.Equal(t, AlertResolved, alert.Status)
+	assert.NotNil(t, alert.ResolvedAt)
+	assert.Equal(t, ErrAlertResolved, alert.Resolve(time.Now()))
+}
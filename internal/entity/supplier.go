@@ -0,0 +1,141 @@
+package entity
+
+import (
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	CostSourceReceived = "received"
+	CostSourceSupplier = "supplier"
+)
+
+var (
+	ErrEmailInvalid    = errors.New("email is invalid")
+	ErrSKURequired     = errors.New("supplier_sku is required")
+	ErrCostInvalid     = errors.New("cost_price must not be negative")
+	ErrLeadTimeInvalid = errors.New("lead_time_days must not be negative")
+)
+
+type Supplier struct {
+	ID        entity.ID `json:"id"`
+	Name      string    `json:"name" gorm:"uniqueIndex"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProductSupplier links a product to a supplier selling it, with the terms
+// of that supplier. CostPrice is updated when a purchase order is received.
+type ProductSupplier struct {
+	ProductID      entity.ID  `json:"product_id" gorm:"primaryKey"`
+	SupplierID     entity.ID  `json:"supplier_id" gorm:"primaryKey;index"`
+	SupplierSKU    string     `json:"supplier_sku"`
+	CostPrice      float64    `json:"cost_price"`
+	LeadTimeDays   int        `json:"lead_time_days"`
+	LastReceivedAt *time.Time `json:"last_received_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Margin compares the price of a product to what it costs.
+type Margin struct {
+	ProductID     string  `json:"product_id"`
+	Name          string  `json:"name"`
+	Price         float64 `json:"price"`
+	CostPrice     float64 `json:"cost_price"`
+	CostSource    string  `json:"cost_source"`
+	Margin        float64 `json:"margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+func NewSupplier(name, email, phone string) (*Supplier, error) {
+	supplier := &Supplier{
+		ID:        entity.NewID(),
+		Name:      name,
+		Email:     email,
+		Phone:     phone,
+		CreatedAt: time.Now(),
+	}
+
+	if err := supplier.Validate(); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+func (s *Supplier) Validate() error {
+	if s.Name == "" {
+		return ErrNameRequired
+	}
+	if s.Email != "" {
+		if _, err := mail.ParseAddress(s.Email); err != nil {
+			return ErrEmailInvalid
+		}
+	}
+	return nil
+}
+
+func NewProductSupplier(productID, supplierID entity.ID, sku string, costPrice float64, leadTimeDays int) (*ProductSupplier, error) {
+	link := &ProductSupplier{
+		ProductID:    productID,
+		SupplierID:   supplierID,
+		SupplierSKU:  sku,
+		CostPrice:    costPrice,
+		LeadTimeDays: leadTimeDays,
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := link.Validate(); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (l *ProductSupplier) Validate() error {
+	if l.SupplierSKU == "" {
+		return ErrSKURequired
+	}
+	if l.CostPrice < 0 {
+		return ErrCostInvalid
+	}
+	if l.LeadTimeDays < 0 {
+		return ErrLeadTimeInvalid
+	}
+	return nil
+}
+
+// ComputeMargin uses the cost of the last received purchase order when there
+// is one, and the cheapest supplier cost otherwise. Products without any cost
+// get an empty CostSource and no margin.
+func ComputeMargin(product Product, links []ProductSupplier) Margin {
+	margin := Margin{
+		ProductID: product.ID.String(),
+		Name:      product.Name,
+		Price:     product.Price,
+	}
+
+	switch {
+	case product.CostPrice > 0:
+		margin.CostPrice = product.CostPrice
+		margin.CostSource = CostSourceReceived
+	default:
+		for _, link := range links {
+			if link.CostPrice > 0 && (margin.CostSource == "" || link.CostPrice < margin.CostPrice) {
+				margin.CostPrice = link.CostPrice
+				margin.CostSource = CostSourceSupplier
+			}
+		}
+	}
+	if margin.CostSource == "" {
+		return margin
+	}
+
+	margin.Margin = roundCents(product.Price - margin.CostPrice)
+	if product.Price > 0 {
+		margin.MarginPercent = roundCents(margin.Margin / product.Price * 100)
+	}
+	return margin
+}
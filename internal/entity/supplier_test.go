@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSupplier(t *testing.T) {
+	supplier, err := NewSupplier("Acme", "sales@acme.com", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "Acme", supplier.Name)
+
+	_, err = NewSupplier("", "", "")
+	assert.Equal(t, ErrNameRequired, err)
+	_, err = NewSupplier("Acme", "not an email", "")
+	assert.Equal(t, ErrEmailInvalid, err)
+}
+
+func TestNewProductSupplier(t *testing.T) {
+	link, err := NewProductSupplier(entity.NewID(), entity.NewID(), "ACME-1", 4.5, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, link.LeadTimeDays)
+
+	_, err = NewProductSupplier(entity.NewID(), entity.NewID(), "", 4.5, 7)
+	assert.Equal(t, ErrSKURequired, err)
+	_, err = NewProductSupplier(entity.NewID(), entity.NewID(), "ACME-1", -1, 7)
+	assert.Equal(t, ErrCostInvalid, err)
+	_, err = NewProductSupplier(entity.NewID(), entity.NewID(), "ACME-1", 1, -7)
+	assert.Equal(t, ErrLeadTimeInvalid, err)
+}
+
+func TestComputeMargin(t *testing.T) {
+	product, _ := NewProduct("Product 1", 20)
+	links := []ProductSupplier{{CostPrice: 15}, {CostPrice: 12}, {CostPrice: 0}}
+
+	margin := ComputeMargin(*product, links)
+	assert.Equal(t, CostSourceSupplier, margin.CostSource)
+	assert.Equal(t, 12.0, margin.CostPrice)
+	assert.Equal(t, 8.0, margin.Margin)
+	assert.Equal(t, 40.0, margin.MarginPercent)
+
+	product.CostPrice = 16
+	margin = ComputeMargin(*product, links)
+	assert.Equal(t, CostSourceReceived, margin.CostSource)
+	assert.Equal(t, 20.0, margin.MarginPercent)
+}
+
+func TestComputeMarginWithoutCost(t *testing.T) {
+	product, _ := NewProduct("Product 1", 20)
+
+	margin := ComputeMargin(*product, nil)
+	assert.Empty(t, margin.CostSource)
+	assert.Equal(t, 0.0, margin.Margin)
+}
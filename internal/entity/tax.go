@@ -0,0 +1,172 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+var (
+	ErrJurisdictionRequired = errors.New("jurisdiction is required")
+	ErrTaxRateInvalid       = errors.New("rate must be between 0 and 100")
+	ErrEffectiveDateInvalid = errors.New("effective_to must be after effective_from")
+	ErrTaxClassRequired     = errors.New("product has no tax class")
+	ErrTaxClassTaken        = errors.New("a tax class with this name already exists")
+	ErrTaxRateTaken         = errors.New("a rate with this class, jurisdiction, name and effective_from already exists")
+)
+
+// TaxClass groups products taxed the same way, e.g. "standard" or "food".
+type TaxClass struct {
+	ID        entity.ID `json:"id"`
+	Name      string    `json:"name" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaxRate is one tax (VAT, ICMS...) levied on a tax class in a jurisdiction
+// during [EffectiveFrom, EffectiveTo). Several rates can apply at once.
+type TaxRate struct {
+	ID            entity.ID  `json:"id"`
+	TaxClassID    entity.ID  `json:"tax_class_id" gorm:"index;uniqueIndex:idx_tax_rate_key"`
+	Jurisdiction  string     `json:"jurisdiction" gorm:"index;uniqueIndex:idx_tax_rate_key"`
+	Name          string     `json:"name" gorm:"uniqueIndex:idx_tax_rate_key"`
+	Rate          float64    `json:"rate"`
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"uniqueIndex:idx_tax_rate_key"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type TaxComponent struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+// TaxBreakdown splits a price into its net amount and taxes.
+type TaxBreakdown struct {
+	Jurisdiction string         `json:"region"`
+	Inclusive    bool           `json:"tax_inclusive"`
+	Net          float64        `json:"net"`
+	Tax          float64        `json:"tax"`
+	Gross        float64        `json:"gross"`
+	Rate         float64        `json:"rate"`
+	Components   []TaxComponent `json:"components"`
+}
+
+func NewTaxClass(name string) (*TaxClass, error) {
+	class := &TaxClass{
+		ID:        entity.NewID(),
+		Name:      strings.TrimSpace(name),
+		CreatedAt: time.Now(),
+	}
+	if class.Name == "" {
+		return nil, ErrNameRequired
+	}
+	return class, nil
+}
+
+func NewTaxRate(classID entity.ID, jurisdiction, name string, rate float64, from time.Time, to *time.Time) (*TaxRate, error) {
+	taxRate := &TaxRate{
+		ID:            entity.NewID(),
+		TaxClassID:    classID,
+		Jurisdiction:  NormalizeJurisdiction(jurisdiction),
+		Name:          strings.TrimSpace(name),
+		Rate:          rate,
+		EffectiveFrom: from.UTC(),
+		CreatedAt:     time.Now(),
+	}
+	if to != nil {
+		end := to.UTC()
+		taxRate.EffectiveTo = &end
+	}
+
+	if err := taxRate.Validate(); err != nil {
+		return nil, err
+	}
+
+	return taxRate, nil
+}
+
+func NormalizeJurisdiction(jurisdiction string) string {
+	return strings.ToUpper(strings.TrimSpace(jurisdiction))
+}
+
+func (t *TaxRate) Validate() error {
+	if t.Jurisdiction == "" {
+		return ErrJurisdictionRequired
+	}
+	if t.Name == "" {
+		return ErrNameRequired
+	}
+	if t.Rate < 0 || t.Rate > 100 {
+		return ErrTaxRateInvalid
+	}
+	if t.EffectiveTo != nil && !t.EffectiveTo.After(t.EffectiveFrom) {
+		return ErrEffectiveDateInvalid
+	}
+	return nil
+}
+
+func (t *TaxRate) IsEffective(at time.Time) bool {
+	if at.Before(t.EffectiveFrom) {
+		return false
+	}
+	return t.EffectiveTo == nil || at.Before(*t.EffectiveTo)
+}
+
+// CalculateTax breaks price down using the rates effective at the given
+// time. When inclusive is true price already contains the taxes and the net
+// amount is backed out of it; otherwise taxes are added on top. Amounts are
+// computed in cents and rounded half away from zero; with several rates, the
+// last one absorbs the rounding difference so net + tax always equals gross.
+func CalculateTax(price float64, inclusive bool, jurisdiction string, rates []TaxRate, at time.Time) TaxBreakdown {
+	effective := make([]TaxRate, 0, len(rates))
+	totalRate := 0.0
+	for _, rate := range rates {
+		if rate.IsEffective(at) {
+			effective = append(effective, rate)
+			totalRate += rate.Rate
+		}
+	}
+
+	cents := toCents(price)
+	var net, tax int64
+	if inclusive {
+		net = int64(math.Round(float64(cents) / (1 + totalRate/100)))
+		tax = cents - net
+	} else {
+		net = cents
+		tax = int64(math.Round(float64(net) * totalRate / 100))
+	}
+
+	components := make([]TaxComponent, len(effective))
+	remaining := tax
+	for i, rate := range effective {
+		amount := remaining
+		if i < len(effective)-1 && totalRate > 0 {
+			amount = int64(math.Round(float64(tax) * rate.Rate / totalRate))
+		}
+		remaining -= amount
+		components[i] = TaxComponent{Name: rate.Name, Rate: rate.Rate, Amount: fromCents(amount)}
+	}
+
+	return TaxBreakdown{
+		Jurisdiction: NormalizeJurisdiction(jurisdiction),
+		Inclusive:    inclusive,
+		Net:          fromCents(net),
+		Tax:          fromCents(tax),
+		Gross:        fromCents(net + tax),
+		Rate:         totalRate,
+		Components:   components,
+	}
+}
+
+func toCents(value float64) int64 {
+	return int64(math.Round(value * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var taxNow = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestTaxRate(t *testing.T, name string, rate float64) TaxRate {
+	class, err := NewTaxClass("standard")
+	assert.Nil(t, err)
+	taxRate, err := NewTaxRate(class.ID, "sp", name, rate, taxNow.AddDate(-1, 0, 0), nil)
+	assert.Nil(t, err)
+	return *taxRate
+}
+
+func TestNewTaxRate(t *testing.T) {
+	rate := newTestTaxRate(t, "ICMS", 18)
+	assert.Equal(t, "SP", rate.Jurisdiction)
+	assert.Equal(t, 18.0, rate.Rate)
+}
+
+func TestTaxRateWhenRateIsInvalid(t *testing.T) {
+	class, _ := NewTaxClass("standard")
+	rate, err := NewTaxRate(class.ID, "SP", "ICMS", 180, taxNow, nil)
+	assert.Nil(t, rate)
+	assert.Equal(t, ErrTaxRateInvalid, err)
+}
+
+func TestTaxRateWhenDatesAreInvalid(t *testing.T) {
+	class, _ := NewTaxClass("standard")
+	before := taxNow.Add(-time.Hour)
+	rate, err := NewTaxRate(class.ID, "SP", "ICMS", 18, taxNow, &before)
+	assert.Nil(t, rate)
+	assert.Equal(t, ErrEffectiveDateInvalid, err)
+}
+
+func TestCalculateTaxExclusive(t *testing.T) {
+	rates := []TaxRate{newTestTaxRate(t, "ICMS", 18)}
+
+	breakdown := CalculateTax(19.99, false, "SP", rates, taxNow)
+	assert.Equal(t, 19.99, breakdown.Net)
+	assert.Equal(t, 3.60, breakdown.Tax)
+	assert.Equal(t, 23.59, breakdown.Gross)
+}
+
+func TestCalculateTaxInclusive(t *testing.T) {
+	rates := []TaxRate{newTestTaxRate(t, "VAT", 20)}
+
+	breakdown := CalculateTax(10, true, "SP", rates, taxNow)
+	assert.Equal(t, 8.33, breakdown.Net)
+	assert.Equal(t, 1.67, breakdown.Tax)
+	assert.Equal(t, 10.0, breakdown.Gross)
+}
+
+func TestCalculateTaxSplitsComponents(t *testing.T) {
+	rates := []TaxRate{newTestTaxRate(t, "ICMS", 18), newTestTaxRate(t, "PIS", 1.65)}
+
+	breakdown := CalculateTax(100, true, "SP", rates, taxNow)
+	assert.Equal(t, breakdown.Gross, 100.0)
+	assert.Len(t, breakdown.Components, 2)
+	assert.InDelta(t, breakdown.Tax, breakdown.Components[0].Amount+breakdown.Components[1].Amount, 0.001)
+	assert.InDelta(t, breakdown.Gross, breakdown.Net+breakdown.Tax, 0.001)
+}
+
+func TestCalculateTaxIgnoresRatesOutOfEffect(t *testing.T) {
+	class, _ := NewTaxClass("standard")
+	end := taxNow.Add(-time.Hour)
+	old, err := NewTaxRate(class.ID, "SP", "ICMS", 17, taxNow.AddDate(-2, 0, 0), &end)
+	assert.Nil(t, err)
+	current, err := NewTaxRate(class.ID, "SP", "ICMS", 18, end, nil)
+	assert.Nil(t, err)
+
+	breakdown := CalculateTax(100, false, "SP", []TaxRate{*old, *current}, taxNow)
+	assert.Equal(t, 18.0, breakdown.Rate)
+	assert.Equal(t, 118.0, breakdown.Gross)
+}
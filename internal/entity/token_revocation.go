@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// RevokedToken denies an access token, by its jti claim, until it expires
+// on its own.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"primaryKey"`
+	UserID    entity.ID `json:"user_id" gorm:"index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SessionRevocation denies the access tokens of a user issued up to
+// RevokedAt. It is kept until ExpiresAt, when the last of them has expired.
+type SessionRevocation struct {
+	UserID    entity.ID `json:"user_id" gorm:"primaryKey"`
+	RevokedAt time.Time `json:"revoked_at"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+}
+
+func NewSessionRevocation(userID entity.ID, accessTTL time.Duration, now time.Time) *SessionRevocation {
+	return &SessionRevocation{
+		UserID:    userID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(accessTTL),
+	}
+}
+
+// Covers reports whether a token issued at issuedAt is revoked. The iat
+// claim only has a precision of a second: the tokens issued within the
+// second of the revocation, even just after it, are revoked as well.
+func (r SessionRevocation) Covers(issuedAt time.Time) bool {
+	return !issuedAt.After(r.RevokedAt.Truncate(time.Second))
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRevocationCovers(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 900_000_000, time.UTC)
+	revocation := NewSessionRevocation(entity.NewID(), 5*time.Minute, now)
+	assert.Equal(t, now.Add(5*time.Minute), revocation.ExpiresAt)
+
+	assert.True(t, revocation.Covers(time.Date(2024, 3, 1, 9, 59, 0, 0, time.UTC)))
+	assert.True(t, revocation.Covers(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)))
+	assert.False(t, revocation.Covers(time.Date(2024, 3, 1, 10, 0, 1, 0, time.UTC)))
+}
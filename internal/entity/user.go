@@ -1,15 +1,34 @@
 package entity
 
 import (
+	"errors"
+
 	"github.com/bhyago/crud-products-go/pkg/entity"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// The roles of the users, each granting what the previous ones do: viewers
+// read the catalog, editors change it, admins also manage the users.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+var (
+	ErrRoleInvalid = errors.New("role must be viewer, editor or admin")
+	ErrLastAdmin   = errors.New("the last admin cannot lose the admin role")
+	ErrEmailTaken  = errors.New("email is already registered")
+)
+
 type User struct {
 	ID       entity.ID `json:"id"`
 	Name     string    `json:"name"`
 	Password string    `json:"-"`
-	Email    string    `json:"email"`
+	Email    string    `json:"email" gorm:"uniqueIndex:idx_users_email,expression:LOWER(email)"`
+	Role     string    `json:"role" gorm:"not null;default:viewer"`
 }
 
 func NewUser(name, password, email string) (*User, error) {
@@ -23,6 +42,7 @@ func NewUser(name, password, email string) (*User, error) {
 		Name:     name,
 		Password: string(hash),
 		Email:    email,
+		Role:     RoleViewer,
 	}, nil
 }
 
@@ -30,3 +50,15 @@ func (u *User) ValidadePassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HasRole reports whether role grants at least the required one. An unknown
+// role grants nothing.
+func HasRole(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
@@ -15,6 +15,7 @@ func TestNewUser(t *testing.T) {
 	assert.NotEmpty(t, user.Password)
 	assert.Equal(t, "John", user.Name)
 	assert.Equal(t, "j@j.com", user.Email)
+	assert.Equal(t, RoleViewer, user.Role)
 }
 
 func TestUser_ValidatePassword(t *testing.T) {
@@ -25,3 +26,12 @@ func TestUser_ValidatePassword(t *testing.T) {
 	assert.False(t, user.ValidadePassword("1234567"))
 	assert.NotEqual(t, "123456", user.Password)
 }
+
+func TestHasRole(t *testing.T) {
+	assert.True(t, HasRole(RoleAdmin, RoleEditor))
+	assert.True(t, HasRole(RoleEditor, RoleEditor))
+	assert.False(t, HasRole(RoleViewer, RoleEditor))
+	assert.False(t, HasRole("", RoleViewer))
+	assert.True(t, IsValidRole(RoleViewer))
+	assert.False(t, IsValidRole("owner"))
+}
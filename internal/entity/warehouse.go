@@ -0,0 +1,160 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
+
+var (
+	ErrCodeRequired          = errors.New("code is required")
+	ErrInsufficientStock     = errors.New("insufficient stock")
+	ErrSameWarehouse         = errors.New("source and destination warehouses must differ")
+	ErrTransferNotInTransit  = errors.New("transfer is not in transit")
+	ErrWarehouseNotEmpty     = errors.New("warehouse still holds stock")
+	ErrProductHasStock       = errors.New("product still has stock in a warehouse")
+	ErrStockDeltaInvalid     = errors.New("delta must not be zero")
+	ErrTransferStatusInvalid = errors.New("transfer status is invalid")
+)
+
+type Warehouse struct {
+	ID        entity.ID `json:"id"`
+	Code      string    `json:"code" gorm:"uniqueIndex"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockLevel is the quantity of a product on hand in a warehouse. The check
+// constraint backs the conditional updates that keep it from going negative.
+type StockLevel struct {
+	ProductID   entity.ID `json:"product_id" gorm:"primaryKey"`
+	WarehouseID entity.ID `json:"warehouse_id" gorm:"primaryKey;index"`
+	Quantity    int       `json:"quantity" gorm:"not null;check:quantity >= 0"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StockTransfer moves stock between warehouses. The quantity leaves the
+// source when the transfer is created and reaches the destination when it is
+// received; cancelling returns it to the source.
+type StockTransfer struct {
+	ID              entity.ID  `json:"id"`
+	ProductID       entity.ID  `json:"product_id" gorm:"index"`
+	FromWarehouseID entity.ID  `json:"from_warehouse_id"`
+	ToWarehouseID   entity.ID  `json:"to_warehouse_id"`
+	Quantity        int        `json:"quantity"`
+	Status          string     `json:"status" gorm:"index"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ReceivedAt      *time.Time `json:"received_at"`
+	CancelledAt     *time.Time `json:"cancelled_at"`
+}
+
+// LocationStock is the stock of a product in one warehouse.
+type LocationStock struct {
+	WarehouseID string `json:"warehouse_id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	OnHand      int    `json:"on_hand"`
+	Inbound     int    `json:"inbound"`
+}
+
+// Availability breaks down the stock of a product by warehouse.
+type Availability struct {
+	ProductID string          `json:"product_id"`
+	OnHand    int             `json:"on_hand"`
+	InTransit int             `json:"in_transit"`
+	Locations []LocationStock `json:"locations"`
+}
+
+func NewWarehouse(code, name, address string) (*Warehouse, error) {
+	warehouse := &Warehouse{
+		ID:        entity.NewID(),
+		Code:      strings.ToUpper(strings.TrimSpace(code)),
+		Name:      name,
+		Address:   address,
+		CreatedAt: time.Now(),
+	}
+
+	if err := warehouse.Validate(); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (w *Warehouse) Validate() error {
+	if w.Code == "" {
+		return ErrCodeRequired
+	}
+	if w.Name == "" {
+		return ErrNameRequired
+	}
+	return nil
+}
+
+func NewStockTransfer(productID, from, to entity.ID, quantity int) (*StockTransfer, error) {
+	if from == to {
+		return nil, ErrSameWarehouse
+	}
+	if quantity <= 0 {
+		return nil, ErrQuantityInvalid
+	}
+	return &StockTransfer{
+		ID:              entity.NewID(),
+		ProductID:       productID,
+		FromWarehouseID: from,
+		ToWarehouseID:   to,
+		Quantity:        quantity,
+		Status:          TransferInTransit,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+func IsTransferStatus(status string) bool {
+	switch status {
+	case TransferInTransit, TransferReceived, TransferCancelled:
+		return true
+	}
+	return false
+}
+
+// ComputeAvailability lists every warehouse with its stock of the product and
+// the quantity in transit towards it.
+func ComputeAvailability(productID entity.ID, warehouses []Warehouse, levels []StockLevel, transfers []StockTransfer) Availability {
+	availability := Availability{
+		ProductID: productID.String(),
+		Locations: make([]LocationStock, 0, len(warehouses)),
+	}
+
+	onHand := make(map[entity.ID]int, len(levels))
+	for _, level := range levels {
+		onHand[level.WarehouseID] += level.Quantity
+	}
+	inbound := make(map[entity.ID]int, len(transfers))
+	for _, transfer := range transfers {
+		if transfer.Status == TransferInTransit {
+			inbound[transfer.ToWarehouseID] += transfer.Quantity
+			availability.InTransit += transfer.Quantity
+		}
+	}
+
+	for _, warehouse := range warehouses {
+		location := LocationStock{
+			WarehouseID: warehouse.ID.String(),
+			Code:        warehouse.Code,
+			Name:        warehouse.Name,
+			OnHand:      onHand[warehouse.ID],
+			Inbound:     inbound[warehouse.ID],
+		}
+		availability.OnHand += location.OnHand
+		availability.Locations = append(availability.Locations, location)
+	}
+	return availability
+}
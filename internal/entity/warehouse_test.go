@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWarehouse(t *testing.T) {
+	warehouse, err := NewWarehouse(" nyc-1 ", "New York", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "NYC-1", warehouse.Code)
+
+	_, err = NewWarehouse("", "New York", "")
+	assert.Equal(t, ErrCodeRequired, err)
+	_, err = NewWarehouse("NYC-1", "", "")
+	assert.Equal(t, ErrNameRequired, err)
+}
+
+func TestNewStockTransfer(t *testing.T) {
+	from, to := entity.NewID(), entity.NewID()
+	transfer, err := NewStockTransfer(entity.NewID(), from, to, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, TransferInTransit, transfer.Status)
+
+	_, err = NewStockTransfer(entity.NewID(), from, from, 3)
+	assert.Equal(t, ErrSameWarehouse, err)
+	_, err = NewStockTransfer(entity.NewID(), from, to, 0)
+	assert.Equal(t, ErrQuantityInvalid, err)
+}
+
+func TestComputeAvailability(t *testing.T) {
+	productID := entity.NewID()
+	east, _ := NewWarehouse("EAST", "East", "")
+	west, _ := NewWarehouse("WEST", "West", "")
+	levels := []StockLevel{{ProductID: productID, WarehouseID: east.ID, Quantity: 7}}
+	transfers := []StockTransfer{
+		{ProductID: productID, FromWarehouseID: east.ID, ToWarehouseID: west.ID, Quantity: 3, Status: TransferInTransit},
+		{ProductID: productID, FromWarehouseID: east.ID, ToWarehouseID: west.ID, Quantity: 2, Status: TransferCancelled},
+	}
+
+	availability := ComputeAvailability(productID, []Warehouse{*east, *west}, levels, transfers)
+	assert.Equal(t, 7, availability.OnHand)
+	assert.Equal(t, 3, availability.InTransit)
+	assert.Equal(t, 7, availability.Locations[0].OnHand)
+	assert.Equal(t, 0, availability.Locations[1].OnHand)
+	assert.Equal(t, 3, availability.Locations[1].Inbound)
+}
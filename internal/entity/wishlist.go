@@ -0,0 +1,159 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+const (
+	MaxWishlistNameLength = 100
+	MaxWishlistNoteLength = 500
+)
+
+var (
+	ErrWishlistNameTooLong   = errors.New("name is too long")
+	ErrWishlistNoteTooLong   = errors.New("note is too long")
+	ErrWishlistItemNotFound  = errors.New("product is not in the wishlist")
+	ErrWishlistItemDuplicate = errors.New("product is already in the wishlist")
+)
+
+// Wishlist is a named list of products a user saved for later. When
+// ShareToken is set, anyone holding it can read the list.
+type Wishlist struct {
+	ID         entity.ID      `json:"id"`
+	UserID     entity.ID      `json:"user_id" gorm:"index"`
+	Name       string         `json:"name"`
+	ShareToken *string        `json:"share_token" gorm:"uniqueIndex"`
+	Items      []WishlistItem `json:"items" gorm:"foreignKey:WishlistID"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// WishlistItem keeps the name of the product when it was saved, so the item
+// can still be shown after the product is deleted.
+type WishlistItem struct {
+	WishlistID  entity.ID `json:"-" gorm:"primaryKey"`
+	ProductID   entity.ID `json:"product_id" gorm:"primaryKey"`
+	ProductName string    `json:"product_name"`
+	Note        string    `json:"note"`
+	AddedAt     time.Time `json:"added_at"`
+
+	Product        *Product `json:"product,omitempty" gorm:"-"`
+	ProductDeleted bool     `json:"product_deleted" gorm:"-"`
+}
+
+func NewWishlist(userID entity.ID, name string) (*Wishlist, error) {
+	now := time.Now()
+	wishlist := &Wishlist{
+		ID:        entity.NewID(),
+		UserID:    userID,
+		Name:      name,
+		Items:     []WishlistItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := wishlist.Validate(); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+func (w *Wishlist) Validate() error {
+	if w.Name == "" {
+		return ErrNameRequired
+	}
+	if utf8.RuneCountInString(w.Name) > MaxWishlistNameLength {
+		return ErrWishlistNameTooLong
+	}
+	for _, item := range w.Items {
+		if utf8.RuneCountInString(item.Note) > MaxWishlistNoteLength {
+			return ErrWishlistNoteTooLong
+		}
+	}
+	return nil
+}
+
+func (w *Wishlist) AddItem(product *Product, note string) error {
+	if utf8.RuneCountInString(note) > MaxWishlistNoteLength {
+		return ErrWishlistNoteTooLong
+	}
+	if w.item(product.ID) != nil {
+		return ErrWishlistItemDuplicate
+	}
+
+	w.Items = append(w.Items, WishlistItem{
+		WishlistID:  w.ID,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Note:        note,
+		AddedAt:     time.Now(),
+	})
+	return nil
+}
+
+func (w *Wishlist) SetNote(productID entity.ID, note string) error {
+	if utf8.RuneCountInString(note) > MaxWishlistNoteLength {
+		return ErrWishlistNoteTooLong
+	}
+	item := w.item(productID)
+	if item == nil {
+		return ErrWishlistItemNotFound
+	}
+	item.Note = note
+	return nil
+}
+
+func (w *Wishlist) RemoveItem(productID entity.ID) error {
+	for i, item := range w.Items {
+		if item.ProductID == productID {
+			w.Items = append(w.Items[:i], w.Items[i+1:]...)
+			return nil
+		}
+	}
+	return ErrWishlistItemNotFound
+}
+
+// Share gives the wishlist a new share token, invalidating the previous one.
+func (w *Wishlist) Share() error {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(buf)
+	w.ShareToken = &token
+	return nil
+}
+
+func (w *Wishlist) Unshare() {
+	w.ShareToken = nil
+}
+
+// Resolve attaches the current products to the items. Items whose product is
+// missing from products are flagged as deleted instead of being dropped.
+func (w *Wishlist) Resolve(products map[string]Product) {
+	for i := range w.Items {
+		item := &w.Items[i]
+		product, ok := products[item.ProductID.String()]
+		item.ProductDeleted = !ok
+		item.Product = nil
+		if ok {
+			item.Product = &product
+			item.ProductName = product.Name
+		}
+	}
+}
+
+func (w *Wishlist) item(productID entity.ID) *WishlistItem {
+	for i := range w.Items {
+		if w.Items[i].ProductID == productID {
+			return &w.Items[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWishlist(t *testing.T) {
+	wishlist, err := NewWishlist(entity.NewID(), "Birthday")
+	assert.Nil(t, err)
+	assert.Equal(t, "Birthday", wishlist.Name)
+	assert.Nil(t, wishlist.ShareToken)
+
+	_, err = NewWishlist(entity.NewID(), "")
+	assert.Equal(t, ErrNameRequired, err)
+	_, err = NewWishlist(entity.NewID(), strings.Repeat("a", MaxWishlistNameLength+1))
+	assert.Equal(t, ErrWishlistNameTooLong, err)
+}
+
+func TestWishlistItems(t *testing.T) {
+	product, _ := NewProduct("Product 1", 10)
+	wishlist, _ := NewWishlist(entity.NewID(), "Birthday")
+
+	assert.Nil(t, wishlist.AddItem(product, "size M"))
+	assert.Equal(t, ErrWishlistItemDuplicate, wishlist.AddItem(product, ""))
+	assert.Nil(t, wishlist.SetNote(product.ID, "size L"))
+	assert.Equal(t, "size L", wishlist.Items[0].Note)
+	assert.Equal(t, ErrWishlistNoteTooLong, wishlist.SetNote(product.ID, strings.Repeat("a", MaxWishlistNoteLength+1)))
+
+	assert.Nil(t, wishlist.RemoveItem(product.ID))
+	assert.Equal(t, ErrWishlistItemNotFound, wishlist.RemoveItem(product.ID))
+}
+
+func TestWishlistShare(t *testing.T) {
+	wishlist, _ := NewWishlist(entity.NewID(), "Birthday")
+
+	assert.Nil(t, wishlist.Share())
+	first := *wishlist.ShareToken
+	assert.Len(t, first, 32)
+	assert.Nil(t, wishlist.Share())
+	assert.NotEqual(t, first, *wishlist.ShareToken)
+
+	wishlist.Unshare()
+	assert.Nil(t, wishlist.ShareToken)
+}
+
+func TestWishlistResolveFlagsDeletedProducts(t *testing.T) {
+	kept, _ := NewProduct("Product 1", 10)
+	deleted, _ := NewProduct("Product 2", 20)
+	wishlist, _ := NewWishlist(entity.NewID(), "Birthday")
+	assert.Nil(t, wishlist.AddItem(kept, ""))
+	assert.Nil(t, wishlist.AddItem(deleted, ""))
+
+	wishlist.Resolve(map[string]Product{kept.ID.String(): *kept})
+	assert.False(t, wishlist.Items[0].ProductDeleted)
+	assert.Equal(t, kept.ID, wishlist.Items[0].Product.ID)
+	assert.True(t, wishlist.Items[1].ProductDeleted)
+	assert.Nil(t, wishlist.Items[1].Product)
+	assert.Equal(t, "Product 2", wishlist.Items[1].ProductName)
+}
@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// DefaultBatchSize is the number of distinct counters that triggers a flush
+// before the interval elapses.
+const DefaultBatchSize = 1000
+
+// ViewCounter counts product views in memory and flushes them to the daily
+// roll-ups in batches, so that recording a view never waits on the database.
+// Bots and the views repeated by a viewer within DedupWindow are not counted.
+type ViewCounter struct {
+	ViewDB        database.ProductViewInterface
+	FlushInterval time.Duration
+	DedupWindow   time.Duration
+	BatchSize     int
+	Now           func() time.Time
+
+	mu      sync.Mutex
+	pending map[entity.ViewKey]int64
+	seen    map[string]time.Time
+	flush   chan struct{}
+}
+
+func NewViewCounter(viewDB database.ProductViewInterface, flushInterval, dedupWindow time.Duration) *ViewCounter {
+	return &ViewCounter{
+		ViewDB:        viewDB,
+		FlushInterval: flushInterval,
+		DedupWindow:   dedupWindow,
+		BatchSize:     DefaultBatchSize,
+		Now:           time.Now,
+		pending:       make(map[entity.ViewKey]int64),
+		seen:          make(map[string]time.Time),
+		flush:         make(chan struct{}, 1),
+	}
+}
+
+// Record counts a view of the product by the viewer, a user ID or an address,
+// and reports whether it was counted.
+func (c *ViewCounter) Record(productID entityPkg.ID, viewer, userAgent string) bool {
+	if entity.IsBot(userAgent) {
+		return false
+	}
+
+	now := c.Now()
+	seenKey := viewer + "|" + productID.String()
+
+	c.mu.Lock()
+	if last, ok := c.seen[seenKey]; ok && now.Sub(last) < c.DedupWindow {
+		c.mu.Unlock()
+		return false
+	}
+	c.seen[seenKey] = now
+	c.pending[entity.ViewKey{ProductID: productID, Day: entity.ViewDay(now)}]++
+	full := c.BatchSize > 0 && len(c.pending) >= c.BatchSize
+	c.mu.Unlock()
+
+	if full {
+		select {
+		case c.flush <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// Flush writes the pending counts to the database. The counts are kept for
+// the next flush when the write fails.
+func (c *ViewCounter) Flush() error {
+	now := c.Now()
+
+	c.mu.Lock()
+	counts := c.pending
+	c.pending = make(map[entity.ViewKey]int64)
+	for key, last := range c.seen {
+		if now.Sub(last) >= c.DedupWindow {
+			delete(c.seen, key)
+		}
+	}
+	c.mu.Unlock()
+
+	if err := c.ViewDB.AddViews(counts); err != nil {
+		c.mu.Lock()
+		for key, views := range counts {
+			c.pending[key] += views
+		}
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes the counts every FlushInterval, or sooner when BatchSize
+// counters are pending, until ctx is done; it then flushes one last time.
+func (c *ViewCounter) Run(ctx context.Context) {
+	interval := c.FlushInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.Flush(); err != nil {
+				log.Printf("view counter: %v", err)
+			}
+			return
+		case <-ticker.C:
+		case <-c.flush:
+		}
+
+		if err := c.Flush(); err != nil {
+			log.Printf("view counter: %v", err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+const browser = "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+type fakeViewDB struct {
+	counts map[entity.ViewKey]int64
+	err    error
+}
+
+func (f *fakeViewDB) AddViews(counts map[entity.ViewKey]int64) error {
+	if f.err != nil {
+		return f.err
+	}
+	for key, views := range counts {
+		f.counts[key] += views
+	}
+	return nil
+}
+
+func (f *fakeViewDB) FindPopular(since string, limit int) ([]entity.PopularProduct, error) {
+	return nil, nil
+}
+
+func (f *fakeViewDB) FindDaily(productID, since string) ([]entity.ProductViewDaily, error) {
+	return nil, nil
+}
+
+func TestViewCounterExcludesBotsAndRepeatViews(t *testing.T) {
+	viewDB := &fakeViewDB{counts: map[entity.ViewKey]int64{}}
+	counter := NewViewCounter(viewDB, time.Minute, 30*time.Minute)
+	now := time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC)
+	counter.Now = func() time.Time { return now }
+	productID := entityPkg.NewID()
+
+	assert.True(t, counter.Record(productID, "alice", browser))
+	assert.False(t, counter.Record(productID, "alice", browser))
+	assert.False(t, counter.Record(productID, "crawler", "Googlebot/2.1"))
+	assert.True(t, counter.Record(productID, "bob", browser))
+
+	now = now.Add(31 * time.Minute)
+	assert.True(t, counter.Record(productID, "alice", browser))
+
+	assert.Nil(t, counter.Flush())
+	assert.Equal(t, int64(3), viewDB.counts[entity.ViewKey{ProductID: productID, Day: "2024-03-02"}])
+}
+
+func TestViewCounterKeepsCountsWhenFlushFails(t *testing.T) {
+	viewDB := &fakeViewDB{counts: map[entity.ViewKey]int64{}, err: errors.New("database is locked")}
+	counter := NewViewCounter(viewDB, time.Minute, time.Minute)
+	productID := entityPkg.NewID()
+	counter.Record(productID, "alice", browser)
+
+	assert.NotNil(t, counter.Flush())
+	viewDB.err = nil
+	counter.Record(productID, "bob", browser)
+	assert.Nil(t, counter.Flush())
+	assert.Equal(t, int64(2), viewDB.counts[entity.ViewKey{ProductID: productID, Day: entity.ViewDay(time.Now())}])
+}
+
+func TestViewCounterSignalsFullBatch(t *testing.T) {
+	counter := NewViewCounter(&fakeViewDB{counts: map[entity.ViewKey]int64{}}, time.Minute, time.Minute)
+	counter.BatchSize = 2
+
+	counter.Record(entityPkg.NewID(), "alice", browser)
+	assert.Len(t, counter.flush, 0)
+	counter.Record(entityPkg.NewID(), "alice", browser)
+	assert.Len(t, counter.flush, 1)
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupBundle(t *testing.T) (*Product, *entity.Product, *entity.Product) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{}, &entity.StockLevel{}, &entity.StockTransfer{})
+	productDB := NewProduct(db)
+
+	component, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, productDB.Save(component))
+
+	bundle, err := entity.NewBundle("Kit", 0, entity.PricingDerived, 50, []entity.BundleComponent{
+		{ProductID: component.ID, Quantity: 3},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Save(bundle))
+	return productDB, component, bundle
+}
+
+func TestSaveBundle(t *testing.T) {
+	productDB, _, bundle := setupBundle(t)
+
+	found, err := productDB.FindByID(bundle.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ProductTypeBundle, found.Type)
+	assert.Equal(t, 15.0, found.Price)
+	assert.Len(t, found.Components, 1)
+	assert.Equal(t, 3, found.Components[0].Quantity)
+}
+
+func TestSaveBundleWithUnknownComponent(t *testing.T) {
+	productDB, _, _ := setupBundle(t)
+	unknown, _ := entity.NewProduct("Unknown", 10)
+
+	bundle, err := entity.NewBundle("Kit 2", 10, entity.PricingFixed, 0, []entity.BundleComponent{
+		{ProductID: unknown.ID, Quantity: 1},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ErrComponentInvalid, productDB.Save(bundle))
+}
+
+func TestUpdateComponentRefreshesBundle(t *testing.T) {
+	productDB, component, bundle := setupBundle(t)
+
+	component.Price = 20
+	component.Available = false
+	assert.Nil(t, productDB.Update(component))
+
+	found, err := productDB.FindByID(bundle.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, 30.0, found.Price)
+	assert.False(t, found.Available)
+}
+
+func TestDeleteComponentIsBlocked(t *testing.T) {
+	productDB, component, bundle := setupBundle(t)
+
+	err := productDB.Delete(component.ID.String())
+	assert.Equal(t, entity.ErrProductInBundle, err)
+
+	_, err = productDB.FindByID(bundle.ID.String())
+	assert.Nil(t, err)
+}
+
+func TestDeleteComponentCascades(t *testing.T) {
+	productDB, component, bundle := setupBundle(t)
+	productDB.BundlePolicy = BundlePolicyCascade
+
+	err := productDB.Delete(component.ID.String())
+	assert.Nil(t, err)
+
+	_, err = productDB.FindByID(bundle.ID.String())
+	assert.NotNil(t, err)
+}
+
+func TestFindAllByType(t *testing.T) {
+	productDB, _, bundle := setupBundle(t)
+
+	bundles, err := productDB.FindAllBy(ProductFilter{Type: entity.ProductTypeBundle}, 0, 0, "")
+	assert.Nil(t, err)
+	assert.Len(t, bundles, 1)
+	assert.Equal(t, bundle.ID, bundles[0].ID)
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+)
+
+type Cart struct {
+	DB *gorm.DB
+}
+
+func NewCart(db *gorm.DB) *Cart {
+	return &Cart{
+		DB: db,
+	}
+}
+
+func (c *Cart) FindByID(id string) (*entity.Cart, error) {
+	return c.find(c.DB, "id = ?", id)
+}
+
+func (c *Cart) FindByUserID(userID string) (*entity.Cart, error) {
+	return c.find(c.DB, "user_id = ?", userID)
+}
+
+// Save stores the cart and replaces its lines, bumping UpdatedAt so the cart
+// is not considered stale.
+func (c *Cart) Save(cart *entity.Cart) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		return saveCart(tx, cart)
+	})
+}
+
+func (c *Cart) Delete(id string) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteCart(tx, id)
+	})
+}
+
+// Merge moves the lines of the anonymous cart into the cart of the user,
+// creating it when needed, and deletes the anonymous cart. Carts that
+// already belong to someone are never merged.
+func (c *Cart) Merge(anonymousID string, userID entityPkg.ID) (*entity.Cart, error) {
+	var merged *entity.Cart
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		anonymous, err := c.find(tx, "id = ? AND user_id IS NULL", anonymousID)
+		if err != nil {
+			return err
+		}
+
+		cart, err := c.find(tx, "user_id = ?", userID.String())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			cart = entity.NewCart(&userID)
+		} else if err != nil {
+			return err
+		}
+
+		cart.Merge(anonymous)
+		if err := deleteCart(tx, anonymous.ID.String()); err != nil {
+			return err
+		}
+		merged = cart
+		return saveCart(tx, cart)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// DeleteStale removes the carts not updated since before and returns how
+// many were deleted.
+func (c *Cart) DeleteStale(before time.Time) (int64, error) {
+	var deleted int64
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&entity.Cart{}).Select("id").Where("updated_at < ?", before)
+		if err := tx.Where("cart_id IN (?)", stale).Delete(&entity.CartLine{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("updated_at < ?", before).Delete(&entity.Cart{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+func (c *Cart) find(db *gorm.DB, query string, args ...interface{}) (*entity.Cart, error) {
+	var cart entity.Cart
+	err := db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("added_at, product_id")
+	}).Where(query, args...).First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func saveCart(tx *gorm.DB, cart *entity.Cart) error {
+	cart.UpdatedAt = time.Now()
+	if err := tx.Omit("Lines").Save(cart).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("cart_id = ?", cart.ID).Delete(&entity.CartLine{}).Error; err != nil {
+		return err
+	}
+	if len(cart.Lines) == 0 {
+		return nil
+	}
+	return tx.Create(&cart.Lines).Error
+}
+
+func deleteCart(tx *gorm.DB, id string) error {
+	if err := tx.Where("cart_id = ?", id).Delete(&entity.CartLine{}).Error; err != nil {
+		return err
+	}
+	result := tx.Where("id = ?", id).Delete(&entity.Cart{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
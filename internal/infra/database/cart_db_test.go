@@ -0,0 +1,121 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSaveCart(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Cart{}, &entity.CartLine{})
+	cartDB := NewCart(db)
+	userID := entityPkg.NewID()
+	product, _ := entity.NewProduct("Product 1", 10)
+	cart := entity.NewCart(&userID)
+	assert.Nil(t, cart.AddProduct(product, 2))
+
+	err = cartDB.Save(cart)
+	assert.Nil(t, err)
+
+	found, err := cartDB.FindByUserID(userID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, cart.ID, found.ID)
+	assert.Len(t, found.Lines, 1)
+	assert.Equal(t, 2, found.Lines[0].Quantity)
+
+	assert.Nil(t, found.RemoveProduct(product.ID))
+	assert.Nil(t, cartDB.Save(found))
+	found, err = cartDB.FindByID(cart.ID.String())
+	assert.Nil(t, err)
+	assert.Empty(t, found.Lines)
+}
+
+func TestMergeCart(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Cart{}, &entity.CartLine{})
+	cartDB := NewCart(db)
+	userID := entityPkg.NewID()
+	first, _ := entity.NewProduct("Product 1", 10)
+	second, _ := entity.NewProduct("Product 2", 20)
+
+	cart := entity.NewCart(&userID)
+	assert.Nil(t, cart.AddProduct(first, 1))
+	assert.Nil(t, cartDB.Save(cart))
+	anonymous := entity.NewCart(nil)
+	assert.Nil(t, anonymous.AddProduct(first, 1))
+	assert.Nil(t, anonymous.AddProduct(second, 3))
+	assert.Nil(t, cartDB.Save(anonymous))
+
+	merged, err := cartDB.Merge(anonymous.ID.String(), userID)
+	assert.Nil(t, err)
+	assert.Equal(t, cart.ID, merged.ID)
+
+	found, err := cartDB.FindByUserID(userID.String())
+	assert.Nil(t, err)
+	assert.Len(t, found.Lines, 2)
+	assert.Equal(t, 2, found.Lines[0].Quantity)
+	assert.Equal(t, 3, found.Lines[1].Quantity)
+
+	_, err = cartDB.FindByID(anonymous.ID.String())
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestMergeCartCreatesUserCart(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Cart{}, &entity.CartLine{})
+	cartDB := NewCart(db)
+	userID := entityPkg.NewID()
+	product, _ := entity.NewProduct("Product 1", 10)
+	anonymous := entity.NewCart(nil)
+	assert.Nil(t, anonymous.AddProduct(product, 1))
+	assert.Nil(t, cartDB.Save(anonymous))
+
+	merged, err := cartDB.Merge(anonymous.ID.String(), userID)
+	assert.Nil(t, err)
+	assert.Equal(t, userID, *merged.UserID)
+	assert.Len(t, merged.Lines, 1)
+
+	_, err = cartDB.Merge(merged.ID.String(), entityPkg.NewID())
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestDeleteStaleCarts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Cart{}, &entity.CartLine{})
+	cartDB := NewCart(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	stale := entity.NewCart(nil)
+	assert.Nil(t, stale.AddProduct(product, 1))
+	assert.Nil(t, cartDB.Save(stale))
+	assert.Nil(t, db.Model(stale).Update("updated_at", time.Now().Add(-48*time.Hour)).Error)
+	fresh := entity.NewCart(nil)
+	assert.Nil(t, cartDB.Save(fresh))
+
+	deleted, err := cartDB.DeleteStale(time.Now().Add(-24 * time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	var lines int64
+	db.Model(&entity.CartLine{}).Count(&lines)
+	assert.Equal(t, int64(0), lines)
+	_, err = cartDB.FindByID(fresh.ID.String())
+	assert.Nil(t, err)
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+)
+
+// reportPeriods are the SQLite expressions grouping products by creation
+// period. Weeks follow ISO 8601: they start on Monday and belong to the year
+// of their Thursday, so 2021-01-01 falls in 2020-W53.
+var reportPeriods = map[string]string{
+	entity.ReportIntervalDay:   "strftime('%Y-%m-%d', created_at)",
+	entity.ReportIntervalWeek:  "printf('%s-W%02d', strftime('%Y', created_at, '-3 days', 'weekday 4'), (strftime('%j', created_at, '-3 days', 'weekday 4') - 1) / 7 + 1)",
+	entity.ReportIntervalMonth: "strftime('%Y-%m', created_at)",
+}
+
+// CatalogReport aggregates the products matching filter in the database:
+// price statistics, a histogram over the buckets lower bounds and the number
+// of products created per interval.
+func (p *Product) CatalogReport(filter ProductFilter, buckets []float64, interval string) (*entity.CatalogReport, error) {
+	period, ok := reportPeriods[interval]
+	if !ok {
+		return nil, entity.ErrIntervalInvalid
+	}
+	products := func() *gorm.DB {
+		return filterProducts(p.DB.Model(&entity.Product{}), filter)
+	}
+
+	var summary struct {
+		ProductCount int64
+		MinPrice     float64
+		MaxPrice     float64
+		AvgPrice     float64
+	}
+	err := products().
+		Select("COUNT(*) AS product_count, COALESCE(MIN(price), 0) AS min_price, COALESCE(MAX(price), 0) AS max_price, COALESCE(ROUND(AVG(price), 2), 0) AS avg_price").
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	report := &entity.CatalogReport{
+		ProductCount: summary.ProductCount,
+		MinPrice:     summary.MinPrice,
+		MaxPrice:     summary.MaxPrice,
+		AvgPrice:     summary.AvgPrice,
+		Histogram:    make([]entity.PriceBucket, 0, len(buckets)),
+		Interval:     interval,
+		Created:      []entity.CreatedCount{},
+	}
+
+	// The median is the middle price, or the average of the two middle ones
+	// when the count is even.
+	if report.ProductCount > 0 {
+		middle := products().Select("price").Order("price").
+			Limit(int(2 - report.ProductCount%2)).
+			Offset(int((report.ProductCount - 1) / 2))
+		err = p.DB.Table("(?) AS middle", middle).Select("COALESCE(ROUND(AVG(price), 2), 0)").Scan(&report.MedianPrice).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(buckets) > 0 {
+		selects := make([]string, len(buckets))
+		args := make([]interface{}, 0, 2*len(buckets))
+		for i, from := range buckets {
+			if i+1 < len(buckets) {
+				selects[i] = "COALESCE(SUM(CASE WHEN price >= ? AND price < ? THEN 1 ELSE 0 END), 0)"
+				args = append(args, from, buckets[i+1])
+			} else {
+				selects[i] = "COALESCE(SUM(CASE WHEN price >= ? THEN 1 ELSE 0 END), 0)"
+				args = append(args, from)
+			}
+		}
+		counts := make([]int64, len(buckets))
+		row := products().Select(strings.Join(selects, ", "), args...).Row()
+		targets := make([]interface{}, len(counts))
+		for i := range counts {
+			targets[i] = &counts[i]
+		}
+		if err := row.Scan(targets...); err != nil {
+			return nil, err
+		}
+		for i, from := range buckets {
+			bucket := entity.PriceBucket{From: from, Count: counts[i]}
+			if i+1 < len(buckets) {
+				to := buckets[i+1]
+				bucket.To = &to
+			}
+			report.Histogram = append(report.Histogram, bucket)
+		}
+	}
+
+	err = products().
+		Select(period + " AS period, COUNT(*) AS count").
+		Group("period").
+		Order("period").
+		Scan(&report.Created).Error
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
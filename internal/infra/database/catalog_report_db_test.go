@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCatalogReport(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{})
+	productDB := NewProduct(db)
+	for i, price := range []float64{5, 20, 10, 100} {
+		product, _ := entity.NewProduct(fmt.Sprintf("Product %d", i), price)
+		product.CreatedAt = time.Date(2024, time.Month(2+i/2), 1+i, 12, 0, 0, 0, time.UTC)
+		assert.Nil(t, db.Create(product).Error)
+	}
+
+	report, err := productDB.CatalogReport(ProductFilter{}, []float64{0, 10, 50}, entity.ReportIntervalMonth)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), report.ProductCount)
+	assert.Equal(t, 5.0, report.MinPrice)
+	assert.Equal(t, 100.0, report.MaxPrice)
+	assert.Equal(t, 33.75, report.AvgPrice)
+	assert.Equal(t, 15.0, report.MedianPrice)
+	assert.Equal(t, []int64{1, 2, 1}, []int64{report.Histogram[0].Count, report.Histogram[1].Count, report.Histogram[2].Count})
+	assert.Nil(t, report.Histogram[2].To)
+	assert.Equal(t, []entity.CreatedCount{{Period: "2024-02", Count: 2}, {Period: "2024-03", Count: 2}}, report.Created)
+
+	maxPrice := 20.0
+	report, err = productDB.CatalogReport(ProductFilter{MaxPrice: &maxPrice}, nil, entity.ReportIntervalDay)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), report.ProductCount)
+	assert.Equal(t, 10.0, report.MedianPrice)
+	assert.Equal(t, "2024-02-01", report.Created[0].Period)
+
+	minPrice := 1000.0
+	report, err = productDB.CatalogReport(ProductFilter{MinPrice: &minPrice}, []float64{0}, entity.ReportIntervalWeek)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), report.ProductCount)
+	assert.Equal(t, []entity.PriceBucket{{From: 0, Count: 0}}, report.Histogram)
+	assert.Empty(t, report.Created)
+
+	_, err = productDB.CatalogReport(ProductFilter{}, nil, "year")
+	assert.Equal(t, entity.ErrIntervalInvalid, err)
+}
+
+func TestCatalogReportUsesISOWeeks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{})
+	productDB := NewProduct(db)
+	days := []time.Time{
+		time.Date(2020, 12, 28, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, 1, 3, 23, 0, 0, 0, time.UTC),
+		time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 30, 9, 0, 0, 0, time.UTC),
+	}
+	for i, day := range days {
+		product, _ := entity.NewProduct(fmt.Sprintf("Product %d", i), 10)
+		product.CreatedAt = day
+		assert.Nil(t, db.Create(product).Error)
+	}
+
+	report, err := productDB.CatalogReport(ProductFilter{}, nil, entity.ReportIntervalWeek)
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.CreatedCount{
+		{Period: "2020-W53", Count: 3},
+		{Period: "2021-W01", Count: 1},
+		{Period: "2025-W01", Count: 1},
+	}, report.Created)
+}
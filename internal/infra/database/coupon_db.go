@@ -0,0 +1,132 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxCodeAttempts bounds how many times a colliding coupon code is redrawn.
+const maxCodeAttempts = 10
+
+var ErrCouponCodeUnavailable = errors.New("could not generate a unique coupon code")
+
+type Coupon struct {
+	DB *gorm.DB
+}
+
+func NewCoupon(db *gorm.DB) *Coupon {
+	return &Coupon{
+		DB: db,
+	}
+}
+
+// SaveBatch inserts the coupons in one transaction. A coupon whose code is
+// already taken gets a new one from regenerate.
+func (c *Coupon) SaveBatch(coupons []entity.Coupon, regenerate func() (string, error)) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range coupons {
+			saved := false
+			for attempt := 0; attempt < maxCodeAttempts && !saved; attempt++ {
+				if attempt > 0 {
+					code, err := regenerate()
+					if err != nil {
+						return err
+					}
+					coupons[i].Code = code
+				}
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&coupons[i])
+				if result.Error != nil {
+					return result.Error
+				}
+				saved = result.RowsAffected == 1
+			}
+			if !saved {
+				return ErrCouponCodeUnavailable
+			}
+		}
+		return nil
+	})
+}
+
+func (c *Coupon) FindByCode(code string) (*entity.Coupon, error) {
+	var coupon entity.Coupon
+	if err := c.DB.Where("code = ?", code).First(&coupon).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (c *Coupon) FindAll(batchID string) ([]entity.Coupon, error) {
+	var coupons []entity.Coupon
+	query := c.DB.Order("created_at, code")
+	if batchID != "" {
+		query = query.Where("batch_id = ?", batchID)
+	}
+	err := query.Find(&coupons).Error
+	return coupons, err
+}
+
+func (c *Coupon) CountRedemptions(code, userID string) (int64, error) {
+	var count int64
+	err := c.DB.Model(&entity.CouponRedemption{}).Where("code = ? AND user_id = ?", code, userID).Count(&count).Error
+	return count, err
+}
+
+// Redeem records a redemption of the coupon by the user. The usage counter is
+// incremented with a conditional update before anything else, so concurrent
+// redemptions serialize on the row and can never go past MaxRedemptions; the
+// per-user limit is then checked inside the same transaction.
+func (c *Coupon) Redeem(code string, userID entityPkg.ID, discount float64, now time.Time) (*entity.CouponRedemption, error) {
+	redemption := &entity.CouponRedemption{
+		ID:        entityPkg.NewID(),
+		Code:      code,
+		UserID:    userID,
+		Discount:  discount,
+		CreatedAt: now,
+	}
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Coupon{}).
+			Where("code = ? AND (max_redemptions = 0 OR redemptions < max_redemptions)", code).
+			Update("redemptions", gorm.Expr("redemptions + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// The code is unknown, or the coupon used up.
+			if err := tx.Where("code = ?", code).First(&entity.Coupon{}).Error; err != nil {
+				return err
+			}
+			return entity.ErrCouponLimitReached
+		}
+
+		var coupon entity.Coupon
+		if err := tx.Where("code = ?", code).First(&coupon).Error; err != nil {
+			return err
+		}
+		if coupon.IsExpired(now) {
+			return entity.ErrCouponExpired
+		}
+		if coupon.MaxPerUser > 0 {
+			var used int64
+			err := tx.Model(&entity.CouponRedemption{}).Where("code = ? AND user_id = ?", code, userID).Count(&used).Error
+			if err != nil {
+				return err
+			}
+			if used >= int64(coupon.MaxPerUser) {
+				return entity.ErrCouponUserLimitReached
+			}
+		}
+
+		return tx.Create(redemption).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSaveCouponBatch(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Coupon{}, &entity.CouponRedemption{})
+	couponDB := NewCoupon(db)
+
+	coupons, err := entity.NewCouponBatch(entity.Coupon{Kind: entity.DiscountFixed, Value: 5}, 3, "", 8)
+	assert.Nil(t, err)
+	coupons[1].Code = coupons[0].Code
+
+	err = couponDB.SaveBatch(coupons, func() (string, error) {
+		return entity.GenerateCouponCode("", 8)
+	})
+	assert.Nil(t, err)
+	assert.NotEqual(t, coupons[0].Code, coupons[1].Code)
+
+	found, err := couponDB.FindAll(coupons[0].BatchID.String())
+	assert.Nil(t, err)
+	assert.Len(t, found, 3)
+}
+
+func TestRedeemCouponRespectsUserLimit(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Coupon{}, &entity.CouponRedemption{})
+	couponDB := NewCoupon(db)
+	coupon := entity.Coupon{Code: "ONCE", Kind: entity.DiscountFixed, Value: 5, MaxPerUser: 1}
+	assert.Nil(t, db.Create(&coupon).Error)
+
+	userID := entityPkg.NewID()
+	_, err = couponDB.Redeem("ONCE", userID, 5, time.Now())
+	assert.Nil(t, err)
+	_, err = couponDB.Redeem("ONCE", userID, 5, time.Now())
+	assert.Equal(t, entity.ErrCouponUserLimitReached, err)
+
+	// The failed attempt must not consume a redemption.
+	found, err := couponDB.FindByCode("ONCE")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, found.Redemptions)
+}
+
+func TestRedeemUnknownCoupon(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Coupon{}, &entity.CouponRedemption{})
+	couponDB := NewCoupon(db)
+	coupon := entity.Coupon{Code: "USEDUP", Kind: entity.DiscountFixed, Value: 5, MaxRedemptions: 1, Redemptions: 1}
+	assert.Nil(t, db.Create(&coupon).Error)
+
+	_, err = couponDB.Redeem("UNKNOWN", entityPkg.NewID(), 5, time.Now())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = couponDB.Redeem("USEDUP", entityPkg.NewID(), 5, time.Now())
+	assert.Equal(t, entity.ErrCouponLimitReached, err)
+}
+
+func TestRedeemCouponConcurrently(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Coupon{}, &entity.CouponRedemption{})
+	couponDB := NewCoupon(db)
+	coupon := entity.Coupon{Code: "FIVE", Kind: entity.DiscountFixed, Value: 5, MaxRedemptions: 5}
+	assert.Nil(t, db.Create(&coupon).Error)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	redeemed := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := couponDB.Redeem("FIVE", entityPkg.NewID(), 5, time.Now())
+			if err == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			} else {
+				assert.Equal(t, entity.ErrCouponLimitReached, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, redeemed)
+	found, err := couponDB.FindByCode("FIVE")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, found.Redemptions)
+}
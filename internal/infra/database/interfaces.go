@@ -1,16 +1,183 @@
 package database
 
-import "github.com/bhyago/crud-products-go/internal/entity"
+import (
+	"io"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+)
 
 type UserInterface interface {
 	FindByEmail(email string) (*entity.User, error)
+	FindByID(id string) (*entity.User, error)
+	FindAll() ([]entity.User, error)
 	Save(user *entity.User) error
+	UpdateRole(id, role string) (*entity.User, error)
+}
+
+type RefreshTokenInterface interface {
+	Create(token *entity.RefreshToken) error
+	Rotate(plain string, idleTTL time.Duration, now time.Time) (*entity.RefreshToken, string, error)
+	RevokeFamily(familyID string, now time.Time) error
+	RevokeUser(userID string, now time.Time) error
+}
+
+type TokenRevocationInterface interface {
+	RevokeToken(token *entity.RevokedToken) error
+	RevokeSessions(revocation *entity.SessionRevocation) error
+	FindRevocations(now time.Time) ([]entity.RevokedToken, []entity.SessionRevocation, error)
+	DeleteExpired(now time.Time) (int64, error)
 }
 
 type ProductInterface interface {
 	FindAll(page, limit int, sort string) ([]entity.Product, error)
+	FindAllBy(filter ProductFilter, page, limit int, sort string) ([]entity.Product, error)
+	CatalogReport(filter ProductFilter, buckets []float64, interval string) (*entity.CatalogReport, error)
 	FindByID(id string) (*entity.Product, error)
+	FindByIDs(ids []string) ([]entity.Product, error)
+	FindBySlug(slug string) (*entity.Product, error)
+	FindByGTIN(code string) (*entity.Product, error)
+	FindNames() ([]entity.ProductName, error)
+	FindRedirect(id string) (*entity.ProductRedirect, error)
+	FindMerges(productID string) ([]entity.ProductMerge, error)
+	Merge(targetID string, sourceIDs []string, rules map[string]string, userID *entityPkg.ID) (*entity.Product, *entity.ProductMerge, error)
 	Save(product *entity.Product) error
 	Update(product *entity.Product) error
 	Delete(id string) error
 }
+
+type ProductTemplateInterface interface {
+	Create(template *entity.ProductTemplate) error
+	FindAll() ([]entity.ProductTemplate, error)
+	FindByID(id string) (*entity.ProductTemplate, error)
+	Update(template *entity.ProductTemplate) error
+	Delete(id string) error
+}
+
+type ProductTranslationInterface interface {
+	FindByProductID(productID string) ([]entity.ProductTranslation, error)
+	FindByProductIDs(productIDs []string) (map[string][]entity.ProductTranslation, error)
+	Save(translation *entity.ProductTranslation) error
+	Delete(productID, locale string) error
+}
+
+type PromotionInterface interface {
+	FindAll() ([]entity.Promotion, error)
+	FindRunning(now time.Time) ([]entity.Promotion, error)
+	FindByID(id string) (*entity.Promotion, error)
+	Save(promotion *entity.Promotion) error
+	Update(promotion *entity.Promotion) error
+	Delete(id string) error
+}
+
+type CouponInterface interface {
+	SaveBatch(coupons []entity.Coupon, regenerate func() (string, error)) error
+	FindByCode(code string) (*entity.Coupon, error)
+	FindAll(batchID string) ([]entity.Coupon, error)
+	CountRedemptions(code, userID string) (int64, error)
+	Redeem(code string, userID entityPkg.ID, discount float64, now time.Time) (*entity.CouponRedemption, error)
+}
+
+type TaxInterface interface {
+	SaveClass(class *entity.TaxClass) error
+	FindClasses() ([]entity.TaxClass, error)
+	FindClassByID(id string) (*entity.TaxClass, error)
+	SaveRate(rate *entity.TaxRate) error
+	FindRates(jurisdiction, classID string) ([]entity.TaxRate, error)
+	ImportRatesCSV(r io.Reader) (int, error)
+}
+
+type CartInterface interface {
+	FindByID(id string) (*entity.Cart, error)
+	FindByUserID(userID string) (*entity.Cart, error)
+	Save(cart *entity.Cart) error
+	Delete(id string) error
+	Merge(anonymousID string, userID entityPkg.ID) (*entity.Cart, error)
+	DeleteStale(before time.Time) (int64, error)
+}
+
+type OrderInterface interface {
+	Checkout(userID entityPkg.ID, key string, items []entity.OrderItem, promotions []entity.Promotion, now time.Time) (*entity.Order, bool, error)
+	FindByID(id string) (*entity.Order, error)
+	FindAll(filter OrderFilter, page, limit int) ([]entity.Order, error)
+	UpdateStatus(id, status string) (*entity.Order, error)
+	Cancel(id string) (*entity.Order, error)
+}
+
+type WishlistInterface interface {
+	FindByID(id string) (*entity.Wishlist, error)
+	FindByUserID(userID string) ([]entity.Wishlist, error)
+	FindByShareToken(token string) (*entity.Wishlist, error)
+	Save(wishlist *entity.Wishlist) error
+	Delete(id string) error
+}
+
+type ReviewInterface interface {
+	FindByID(id string) (*entity.Review, error)
+	FindByProductID(productID string, includeHidden bool, page, limit int) ([]entity.Review, error)
+	Create(review *entity.Review) error
+	Update(review *entity.Review) error
+	Delete(id string) error
+}
+
+type ShippingInterface interface {
+	CreateZone(zone *entity.ShippingZone) error
+	FindZones() ([]entity.ShippingZone, error)
+	FindZoneByID(id string) (*entity.ShippingZone, error)
+	UpdateZone(zone *entity.ShippingZone) error
+	DeleteZone(id string) error
+}
+
+type SupplierInterface interface {
+	Create(supplier *entity.Supplier) error
+	FindAll() ([]entity.Supplier, error)
+	FindByID(id string) (*entity.Supplier, error)
+	Update(supplier *entity.Supplier) error
+	Delete(id string) error
+	SaveLink(link *entity.ProductSupplier) error
+	DeleteLink(productID, supplierID string) error
+	FindLinksByProductID(productID string) ([]entity.ProductSupplier, error)
+	FindLinksBySupplierID(supplierID string) ([]entity.ProductSupplier, error)
+	FindAllLinks() (map[string][]entity.ProductSupplier, error)
+}
+
+type PurchaseOrderInterface interface {
+	FindByID(id string) (*entity.PurchaseOrder, error)
+	FindAll(filter PurchaseOrderFilter) ([]entity.PurchaseOrder, error)
+	Save(order *entity.PurchaseOrder) error
+	Receive(id string, quantities map[entityPkg.ID]int, now time.Time) (*entity.PurchaseOrder, error)
+}
+
+type WarehouseInterface interface {
+	Create(warehouse *entity.Warehouse) error
+	FindAll() ([]entity.Warehouse, error)
+	FindByID(id string) (*entity.Warehouse, error)
+	Update(warehouse *entity.Warehouse) error
+	Delete(id string) error
+	FindStockByWarehouseID(warehouseID string) ([]entity.StockLevel, error)
+	FindStockByProductID(productID string) ([]entity.StockLevel, error)
+	AdjustStock(productID, warehouseID entityPkg.ID, delta int, now time.Time) (*entity.StockLevel, error)
+	CreateTransfer(transfer *entity.StockTransfer) error
+	ReceiveTransfer(id string, now time.Time) (*entity.StockTransfer, error)
+	CancelTransfer(id string, now time.Time) (*entity.StockTransfer, error)
+	FindTransferByID(id string) (*entity.StockTransfer, error)
+	FindTransfers(filter TransferFilter) ([]entity.StockTransfer, error)
+}
+
+type StockAlertInterface interface {
+	FindLowStock() ([]entity.LowStock, error)
+	Create(alert *entity.StockAlert) error
+	FindByID(id string) (*entity.StockAlert, error)
+	FindAll(status string) ([]entity.StockAlert, error)
+	FindUnresolved() ([]entity.StockAlert, error)
+	MarkDelivered(id entityPkg.ID, channels entity.StringList, notifiedAt *time.Time) error
+	Acknowledge(id string, userID entityPkg.ID, now time.Time) (*entity.StockAlert, error)
+	Resolve(id entityPkg.ID, now time.Time) error
+}
+
+type ProductViewInterface interface {
+	AddViews(counts map[entity.ViewKey]int64) error
+	FindPopular(since string, limit int) ([]entity.PopularProduct, error)
+	FindDaily(productID, since string) ([]entity.ProductViewDaily, error)
+}
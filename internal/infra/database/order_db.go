@@ -0,0 +1,165 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+)
+
+// ErrProductNotFound is returned by checkout for items referencing products
+// that do not exist.
+var ErrProductNotFound = errors.New("product not found")
+
+type OrderFilter struct {
+	UserID string
+	Status string
+}
+
+type Order struct {
+	DB *gorm.DB
+}
+
+func NewOrder(db *gorm.DB) *Order {
+	return &Order{
+		DB: db,
+	}
+}
+
+// Checkout places the order of the user for items, pricing the products as
+// they are inside the transaction with the promotions running at now. When
+// the user already placed an order with the same key, that order is returned
+// with created set to false and nothing else happens.
+func (o *Order) Checkout(userID entityPkg.ID, key string, items []entity.OrderItem, promotions []entity.Promotion, now time.Time) (order *entity.Order, created bool, err error) {
+	inserting := false
+	err = o.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := o.findByKey(tx, userID, key)
+		if err == nil {
+			order = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		order, err = entity.NewOrder(userID, key)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			var product entity.Product
+			if err := tx.Where("id = ?", item.ProductID).First(&product).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrProductNotFound
+				}
+				return err
+			}
+			product.ApplyPromotions(promotions, now)
+			if err := order.AddLine(&product, item.Quantity, item.ExpectedPrice); err != nil {
+				return err
+			}
+		}
+		if err := order.Validate(); err != nil {
+			return err
+		}
+
+		inserting = true
+		if err := tx.Omit("Lines").Create(order).Error; err != nil {
+			return err
+		}
+		created = true
+		return tx.Create(&order.Lines).Error
+	})
+	if err != nil && inserting {
+		// Another checkout with the same key won the race: replay its order.
+		if existing, findErr := o.findByKey(o.DB, userID, key); findErr == nil {
+			return existing, false, nil
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return order, created, nil
+}
+
+func (o *Order) FindByID(id string) (*entity.Order, error) {
+	var order entity.Order
+	if err := o.DB.Preload("Lines").Where("id = ?", id).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (o *Order) FindAll(filter OrderFilter, page, limit int) ([]entity.Order, error) {
+	var orders []entity.Order
+	query := o.DB.Preload("Lines").Order("created_at desc")
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if page != 0 && limit != 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+// UpdateStatus moves the order to status. The update only applies while the
+// order still has the status it was loaded with, so two concurrent
+// transitions cannot both succeed.
+func (o *Order) UpdateStatus(id, status string) (*entity.Order, error) {
+	order, err := o.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return o.transition(order, status)
+}
+
+// Cancel cancels the order while it is still pending. An order paid in the
+// meantime is left alone and ErrOrderNotCancellable is returned.
+func (o *Order) Cancel(id string) (*entity.Order, error) {
+	order, err := o.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != entity.OrderPending {
+		return nil, entity.ErrOrderNotCancellable
+	}
+
+	order, err = o.transition(order, entity.OrderCancelled)
+	if errors.Is(err, entity.ErrOrderStatusTransition) {
+		return nil, entity.ErrOrderNotCancellable
+	}
+	return order, err
+}
+
+func (o *Order) transition(order *entity.Order, status string) (*entity.Order, error) {
+	previous := order.Status
+	if err := order.TransitionTo(status); err != nil {
+		return nil, err
+	}
+
+	result := o.DB.Model(&entity.Order{}).
+		Where("id = ? AND status = ?", order.ID, previous).
+		Updates(map[string]interface{}{"status": order.Status, "updated_at": order.UpdatedAt})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, entity.ErrOrderStatusTransition
+	}
+	return order, nil
+}
+
+func (o *Order) findByKey(db *gorm.DB, userID entityPkg.ID, key string) (*entity.Order, error) {
+	var order entity.Order
+	err := db.Preload("Lines").Where("user_id = ? AND idempotency_key = ?", userID, key).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCheckoutOrder(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.Order{}, &entity.OrderLine{})
+	orderDB := NewOrder(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, db.Create(product).Error)
+	userID := entityPkg.NewID()
+	items := []entity.OrderItem{{ProductID: product.ID.String(), Quantity: 3}}
+
+	order, created, err := orderDB.Checkout(userID, "key-1", items, nil, time.Now())
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 30.0, order.Total)
+
+	// The price change does not affect the order already placed, and the
+	// same key replays it instead of placing another one.
+	assert.Nil(t, db.Model(product).Update("price", 12).Error)
+	replayed, created, err := orderDB.Checkout(userID, "key-1", items, nil, time.Now())
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, order.ID, replayed.ID)
+	assert.Equal(t, 10.0, replayed.Lines[0].UnitPrice)
+
+	orders, err := orderDB.FindAll(OrderFilter{UserID: userID.String()}, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, orders, 1)
+}
+
+func TestCheckoutOrderWhenPriceChanged(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.Order{}, &entity.OrderLine{})
+	orderDB := NewOrder(db)
+	first, _ := entity.NewProduct("Product 1", 10)
+	second, _ := entity.NewProduct("Product 2", 20)
+	assert.Nil(t, db.Create(first).Error)
+	assert.Nil(t, db.Create(second).Error)
+	expected := 18.0
+
+	_, _, err = orderDB.Checkout(entityPkg.NewID(), "key-1", []entity.OrderItem{
+		{ProductID: first.ID.String(), Quantity: 1},
+		{ProductID: second.ID.String(), Quantity: 1, ExpectedPrice: &expected},
+	}, nil, time.Now())
+	assert.True(t, errors.Is(err, entity.ErrOrderPriceChanged))
+
+	var count int64
+	db.Model(&entity.Order{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Model(&entity.OrderLine{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCheckoutOrderWhenProductIsMissing(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.Order{}, &entity.OrderLine{})
+	orderDB := NewOrder(db)
+
+	_, _, err = orderDB.Checkout(entityPkg.NewID(), "key-1", []entity.OrderItem{{ProductID: entityPkg.NewID().String(), Quantity: 1}}, nil, time.Now())
+	assert.Equal(t, ErrProductNotFound, err)
+}
+
+func TestCheckoutOrderConcurrentlyWithSameKey(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "orders.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.Order{}, &entity.OrderLine{})
+	orderDB := NewOrder(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, db.Create(product).Error)
+	userID := entityPkg.NewID()
+	items := []entity.OrderItem{{ProductID: product.ID.String(), Quantity: 1}}
+
+	var wg sync.WaitGroup
+	ids := make([]entityPkg.ID, 10)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			order, _, err := orderDB.Checkout(userID, "key-1", items, nil, time.Now())
+			assert.Nil(t, err)
+			if order != nil {
+				ids[i] = order.ID
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+	var count int64
+	db.Model(&entity.Order{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.Order{}, &entity.OrderLine{})
+	orderDB := NewOrder(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, db.Create(product).Error)
+	order, _, err := orderDB.Checkout(entityPkg.NewID(), "key-1", []entity.OrderItem{{ProductID: product.ID.String(), Quantity: 1}}, nil, time.Now())
+	assert.Nil(t, err)
+
+	_, err = orderDB.UpdateStatus(order.ID.String(), entity.OrderShipped)
+	assert.Equal(t, entity.ErrOrderStatusTransition, err)
+
+	updated, err := orderDB.UpdateStatus(order.ID.String(), entity.OrderPaid)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.OrderPaid, updated.Status)
+
+	found, err := orderDB.FindAll(OrderFilter{Status: entity.OrderPaid}, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, found, 1)
+}
+
+func TestCancelOrderOnlyWhilePending(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.Order{}, &entity.OrderLine{})
+	orderDB := NewOrder(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, db.Create(product).Error)
+	userID := entityPkg.NewID()
+	items := []entity.OrderItem{{ProductID: product.ID.String(), Quantity: 1}}
+
+	pending, _, err := orderDB.Checkout(userID, "key-1", items, nil, time.Now())
+	assert.Nil(t, err)
+	cancelled, err := orderDB.Cancel(pending.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.OrderCancelled, cancelled.Status)
+
+	paid, _, err := orderDB.Checkout(userID, "key-2", items, nil, time.Now())
+	assert.Nil(t, err)
+	_, err = orderDB.UpdateStatus(paid.ID.String(), entity.OrderPaid)
+	assert.Nil(t, err)
+	_, err = orderDB.Cancel(paid.ID.String())
+	assert.Equal(t, entity.ErrOrderNotCancellable, err)
+
+	found, err := orderDB.FindByID(paid.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.OrderPaid, found.Status)
+}
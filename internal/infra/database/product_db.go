@@ -1,57 +1,215 @@
 package database
 
 import (
+	"errors"
+	"time"
+
 	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/pkg/barcode"
+	"github.com/bhyago/crud-products-go/pkg/slug"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSlugAttempts bounds the numbered suffixes tried before giving up on a
+// slug base.
+const maxSlugAttempts = 1000
+
+const (
+	// BundlePolicyBlock refuses to delete a product used by a bundle.
+	BundlePolicyBlock = "block"
+	// BundlePolicyCascade deletes the bundles using a product along with it.
+	BundlePolicyCascade = "cascade"
 )
 
+var ErrSlugUnavailable = errors.New("no slug available for product")
+
+// managedColumns are the product columns maintained in place by other
+// repositories: the rating summary by reviews, the cost by purchase orders.
+var managedColumns = append([]string{"cost_price"}, entity.RatingColumns...)
+
+// ProductIndex is kept up to date with the products saved and deleted, such
+// as the index of similar products.
+type ProductIndex interface {
+	Put(product entity.Product)
+	Remove(id string)
+}
+
 type Product struct {
-	DB *gorm.DB
+	DB           *gorm.DB
+	BundlePolicy string
+	// Index, when set, is told of the products saved and deleted once their
+	// transaction is committed.
+	Index ProductIndex
+}
+
+// ProductOrderRating lists the best rated products first.
+const ProductOrderRating = "rating"
+
+type ProductFilter struct {
+	Type        string
+	MinRating   float64
+	OrderBy     string
+	Available   *bool
+	MinPrice    *float64
+	MaxPrice    *float64
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
 }
 
 func NewProduct(db *gorm.DB) *Product {
 	return &Product{
-		DB: db,
+		DB:           db,
+		BundlePolicy: BundlePolicyBlock,
 	}
 }
 
 func (p *Product) FindAll(page, limit int, sort string) ([]entity.Product, error) {
+	return p.FindAllBy(ProductFilter{}, page, limit, sort)
+}
+
+func (p *Product) FindAllBy(filter ProductFilter, page, limit int, sort string) ([]entity.Product, error) {
 	var products []entity.Product
-	var err error
-	if sort == "" && sort != "asc" && sort != "desc" {
+	if sort != "asc" && sort != "desc" {
 		sort = "asc"
 	}
+
+	query := p.DB.Preload("Components")
+	if filter.OrderBy == ProductOrderRating {
+		query = query.Order("rating_average desc, rating_count desc")
+	}
+	query = filterProducts(query.Order("created_at "+sort), filter)
 	if page != 0 && limit != 0 {
-		err = p.DB.Limit(limit).Offset((page - 1) * limit).Order(sort).Find(&products).Error
-	} else {
-		err = p.DB.Find(&products).Order("created_at " + sort).Error
+		query = query.Limit(limit).Offset((page - 1) * limit)
 	}
+	err := query.Find(&products).Error
 	return products, err
 }
 
+// filterProducts restricts the query to the products matching filter.
+// CreatedTo is excluded.
+func filterProducts(query *gorm.DB, filter ProductFilter) *gorm.DB {
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.MinRating > 0 {
+		query = query.Where("rating_average >= ?", filter.MinRating)
+	}
+	if filter.Available != nil {
+		query = query.Where("available = ?", *filter.Available)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	return query
+}
+
 func (p *Product) FindByID(id string) (*entity.Product, error) {
 	var product entity.Product
-	if err := p.DB.Where("id = ?", id).First(&product).Error; err != nil {
+	if err := p.DB.Preload("Components").Where("id = ?", id).First(&product).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// FindByIDs loads the products with the IDs in one query. IDs of missing
+// products are skipped.
+func (p *Product) FindByIDs(ids []string) ([]entity.Product, error) {
+	products := []entity.Product{}
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := p.DB.Preload("Components").Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+func (p *Product) FindBySlug(s string) (*entity.Product, error) {
+	var product entity.Product
+	err := p.DB.Preload("Components").Where("slug = ?", s).First(&product).Error
+	if err == nil {
+		return &product, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var retired entity.ProductSlug
+	if err := p.DB.Where("slug = ?", s).First(&retired).Error; err != nil {
+		return nil, err
+	}
+	return p.FindByID(retired.ProductID.String())
+}
+
+// FindByGTIN finds the product with the GTIN code, whether it was stored
+// with or without leading zeros.
+func (p *Product) FindByGTIN(code string) (*entity.Product, error) {
+	var product entity.Product
+	err := p.DB.Preload("Components").Where("gtin IN ?", barcode.Variants(code)).First(&product).Error
+	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
+// FindNames loads only the id and name of every product, for the duplicate
+// checks.
+func (p *Product) FindNames() ([]entity.ProductName, error) {
+	var names []entity.ProductName
+	err := p.DB.Model(&entity.Product{}).Select("id, name").Order("name").Scan(&names).Error
+	return names, err
+}
+
 func (p *Product) Save(product *entity.Product) error {
-	if err := p.DB.Save(product).Error; err != nil {
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := refreshBundle(tx, product); err != nil {
+			return err
+		}
+		if err := reserveSlug(tx, product); err != nil {
+			return err
+		}
+		return saveProduct(tx, product)
+	})
+	if err != nil {
 		return err
 	}
+	p.indexPut(*product)
 	return nil
 }
 
 func (p *Product) Update(product *entity.Product) error {
-	_, err := p.FindByID(product.ID.String())
+	current, err := p.FindByID(product.ID.String())
 	if err != nil {
 		return err
 	}
-	if err := p.DB.Save(product).Error; err != nil {
+	var bundles []entity.Product
+	err = p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := refreshBundle(tx, product); err != nil {
+			return err
+		}
+		if current.Name != product.Name || product.Slug == "" {
+			if err := reserveSlug(tx, product); err != nil {
+				return err
+			}
+		}
+		if err := saveProduct(tx, product); err != nil {
+			return err
+		}
+		bundles, err = refreshBundlesOf(tx, product.ID.String())
+		return err
+	})
+	if err != nil {
 		return err
 	}
+	p.indexPut(append(bundles, *product)...)
 	return nil
 }
 
@@ -61,8 +219,231 @@ func (p *Product) Delete(id string) error {
 		return err
 	}
 
-	if err := p.DB.Where("id = ?", id).Delete(&entity.Product{}).Error; err != nil {
+	var bundleIDs []string
+	err = p.DB.Transaction(func(tx *gorm.DB) error {
+		bundleIDs, err = bundlesOf(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(bundleIDs) > 0 && p.BundlePolicy != BundlePolicyCascade {
+			return entity.ErrProductInBundle
+		}
+		for _, bundleID := range bundleIDs {
+			if err := deleteProduct(tx, bundleID); err != nil {
+				return err
+			}
+		}
+		return deleteProduct(tx, id)
+	})
+	if err != nil {
+		return err
+	}
+	p.indexRemove(append(bundleIDs, id)...)
+	return nil
+}
+
+func (p *Product) indexPut(products ...entity.Product) {
+	if p.Index == nil {
+		return
+	}
+	for _, product := range products {
+		p.Index.Put(product)
+	}
+}
+
+func (p *Product) indexRemove(ids ...string) {
+	if p.Index == nil {
+		return
+	}
+	for _, id := range ids {
+		p.Index.Remove(id)
+	}
+}
+
+// Backfill fills in the columns of products created before slugs, bundles
+// and tags existed.
+func (p *Product) Backfill() error {
+	err := p.DB.Model(&entity.Product{}).
+		Where("type IS NULL OR type = ''").
+		Updates(map[string]interface{}{"type": entity.ProductTypeSimple, "available": true}).Error
+	if err != nil {
+		return err
+	}
+
+	err = p.DB.Model(&entity.Product{}).Where("tags IS NULL").Update("tags", entity.StringList{}).Error
+	if err != nil {
+		return err
+	}
+
+	var products []entity.Product
+	if err := p.DB.Preload("Components").Where("slug IS NULL OR slug = ''").Find(&products).Error; err != nil {
 		return err
 	}
+	for i := range products {
+		if err := p.Update(&products[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// saveProduct writes the product and its components. The managed columns are
+// left alone: the values loaded with product may already be stale. A GTIN
+// already used by another product, even padded differently, is refused.
+func saveProduct(tx *gorm.DB, product *entity.Product) error {
+	if product.GTIN != nil {
+		var count int64
+		err := tx.Model(&entity.Product{}).
+			Where("gtin IN ? AND id <> ?", barcode.Variants(*product.GTIN), product.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return entity.ErrGTINTaken
+		}
+	}
+	omit := append([]string{clause.Associations}, managedColumns...)
+	if err := tx.Omit(omit...).Save(product).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("bundle_id = ?", product.ID).Delete(&entity.BundleComponent{}).Error; err != nil {
+		return err
+	}
+	if len(product.Components) == 0 {
+		return nil
+	}
+	return tx.Create(&product.Components).Error
+}
+
+func deleteProduct(tx *gorm.DB, id string) error {
+	if err := deleteStockLevels(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Where("bundle_id = ?", id).Delete(&entity.BundleComponent{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id = ?", id).Delete(&entity.ProductSlug{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id = ?", id).Delete(&entity.ProductTranslation{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id = ?", id).Delete(&entity.Product{}).Error
+}
+
+// deleteStockLevels removes the empty stock levels of a product, so they do
+// not outlive it. A product still held in a warehouse or in transit between
+// two cannot be deleted.
+func deleteStockLevels(tx *gorm.DB, id string) error {
+	var held int64
+	err := tx.Model(&entity.StockLevel{}).Where("product_id = ? AND quantity > 0", id).Count(&held).Error
+	if err != nil {
+		return err
+	}
+	var inTransit int64
+	err = tx.Model(&entity.StockTransfer{}).
+		Where("product_id = ? AND status = ?", id, entity.TransferInTransit).
+		Count(&inTransit).Error
+	if err != nil {
+		return err
+	}
+	if held > 0 || inTransit > 0 {
+		return entity.ErrProductHasStock
+	}
+	return tx.Where("product_id = ?", id).Delete(&entity.StockLevel{}).Error
+}
+
+// refreshBundle loads the components of a bundle and recomputes its derived
+// price and availability before validating it.
+func refreshBundle(tx *gorm.DB, product *entity.Product) error {
+	if !product.IsBundle() {
+		return nil
+	}
+
+	ids := make([]string, len(product.Components))
+	for i, c := range product.Components {
+		ids[i] = c.ProductID.String()
+	}
+	var components []entity.Product
+	if err := tx.Where("id IN ?", ids).Find(&components).Error; err != nil {
+		return err
+	}
+	byID := make(map[string]entity.Product, len(components))
+	for _, c := range components {
+		byID[c.ID.String()] = c
+	}
+
+	if err := product.Refresh(byID); err != nil {
+		return err
+	}
+	return product.Validate()
+}
+
+// refreshBundlesOf propagates a component change to the bundles using it,
+// and returns them.
+func refreshBundlesOf(tx *gorm.DB, id string) ([]entity.Product, error) {
+	bundleIDs, err := bundlesOf(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	bundles := make([]entity.Product, len(bundleIDs))
+	for i, bundleID := range bundleIDs {
+		bundle := &bundles[i]
+		if err := tx.Preload("Components").Where("id = ?", bundleID).First(bundle).Error; err != nil {
+			return nil, err
+		}
+		if err := refreshBundle(tx, bundle); err != nil {
+			return nil, err
+		}
+		err := tx.Model(bundle).Updates(map[string]interface{}{
+			"price":     bundle.Price,
+			"available": bundle.Available,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bundles, nil
+}
+
+func bundlesOf(tx *gorm.DB, id string) ([]string, error) {
+	var ids []string
+	err := tx.Model(&entity.BundleComponent{}).Where("product_id = ?", id).Distinct().Pluck("bundle_id", &ids).Error
+	return ids, err
+}
+
+// reserveSlug points product.Slug at a slug derived from its name. A slug the
+// product used before is reused; otherwise the first free candidate is claimed
+// in product_slugs, whose primary key keeps slugs unique across concurrent
+// creates and across retired slugs of other products.
+func reserveSlug(tx *gorm.DB, product *entity.Product) error {
+	base := slug.Make(product.Name)
+	for n := 1; n <= maxSlugAttempts; n++ {
+		candidate := slug.WithSuffix(base, n)
+
+		var owned entity.ProductSlug
+		err := tx.Where("slug = ? AND product_id = ?", candidate, product.ID).Limit(1).Find(&owned).Error
+		if err != nil {
+			return err
+		}
+		if owned.Slug != "" {
+			product.Slug = candidate
+			return nil
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.ProductSlug{
+			Slug:      candidate,
+			ProductID: product.ID,
+			CreatedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			product.Slug = candidate
+			return nil
+		}
+	}
+	return ErrSlugUnavailable
+}
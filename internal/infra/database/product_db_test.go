@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -14,7 +15,7 @@ func TestCreateProduct(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	product, err := entity.NewProduct("Product 1", 10)
 	if err != nil {
 		t.Error(err)
@@ -31,7 +32,7 @@ func TestFindAllProducts(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	product, err := entity.NewProduct("Product 1", 10)
 	if err != nil {
 		t.Error(err)
@@ -50,7 +51,7 @@ func TestFindByID(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	product, err := entity.NewProduct("Product 1", 10)
 	if err != nil {
 		t.Error(err)
@@ -66,12 +67,79 @@ func TestFindByID(t *testing.T) {
 	assert.Equal(t, product.Price, productFound.Price)
 }
 
+func TestFindByIDs(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
+	productDB := NewProduct(db)
+	first, _ := entity.NewProduct("Product 1", 10)
+	second, _ := entity.NewProduct("Product 2", 20)
+	assert.Nil(t, productDB.Save(first))
+	assert.Nil(t, productDB.Save(second))
+
+	products, err := productDB.FindByIDs([]string{first.ID.String(), second.ID.String(), entityPkg.NewID().String()})
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+
+	products, err = productDB.FindByIDs(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, products)
+}
+
+func TestFindByGTIN(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
+	productDB := NewProduct(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, product.SetGTIN("036000291452"))
+	assert.Nil(t, productDB.Save(product))
+	withoutGTIN, _ := entity.NewProduct("Product 2", 10)
+	assert.Nil(t, productDB.Save(withoutGTIN))
+	withoutGTIN, _ = entity.NewProduct("Product 3", 10)
+	assert.Nil(t, productDB.Save(withoutGTIN))
+
+	found, err := productDB.FindByGTIN("0036000291452")
+	assert.Nil(t, err)
+	assert.Equal(t, product.ID, found.ID)
+	_, err = productDB.FindByGTIN("4006381333931")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	found.Price = 12
+	assert.Nil(t, productDB.Update(found))
+	assert.Nil(t, withoutGTIN.SetGTIN("0036000291452"))
+	assert.Equal(t, entity.ErrGTINTaken, productDB.Update(withoutGTIN))
+}
+
+func TestFindNames(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
+	productDB := NewProduct(db)
+	for _, name := range []string{"Product B", "Product A"} {
+		product, _ := entity.NewProduct(name, 10)
+		assert.Nil(t, productDB.Save(product))
+	}
+
+	names, err := productDB.FindNames()
+	assert.Nil(t, err)
+	assert.Len(t, names, 2)
+	assert.Equal(t, "Product A", names[0].Name)
+	assert.NotEmpty(t, names[0].ID)
+}
+
 func TestUpdateProduct(t *testing.T) {
 	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	product, err := entity.NewProduct("Product 1", 10)
 	if err != nil {
 		t.Error(err)
@@ -97,7 +165,7 @@ func TestDeleteProduct(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{}, &entity.StockLevel{}, &entity.StockTransfer{})
 	product, err := entity.NewProduct("Product 1", 10)
 	if err != nil {
 		t.Error(err)
@@ -119,7 +187,7 @@ func TestFindAllProductsEmpty(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(1, 10, "asc")
@@ -132,7 +200,7 @@ func TestFindByIDNotFound(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	productFound, err := productDB.FindByID("1")
@@ -145,7 +213,7 @@ func TestUpdateProductNotFound(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	product, err := entity.NewProduct("Product 1", 10)
@@ -161,7 +229,7 @@ func TestDeleteProductNotFound(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	err = productDB.Delete("1")
@@ -173,7 +241,7 @@ func TestFindAllProductsPageZeroLimitZeroSortEmpty(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(0, 0, "")
@@ -186,7 +254,7 @@ func TestFindAllProductsPageZeroLimitZeroSortAsc(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(0, 0, "asc")
@@ -199,7 +267,7 @@ func TestFindAllProductsPageZeroLimitZeroSortDesc(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(0, 0, "desc")
@@ -212,7 +280,7 @@ func TestFindAllProductsPageZeroLimitZeroSortInvalid(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(0, 0, "invalid")
@@ -225,7 +293,7 @@ func TestFindAllProductsPageZeroLimitSortAsc(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(0, 10, "asc")
@@ -238,7 +306,7 @@ func TestFindAllProductsPageZeroLimitSortDesc(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(0, 10, "desc")
@@ -251,7 +319,7 @@ func TestFindAllProductsPageZeroLimitSortInvalid(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(0, 10, "invalid")
@@ -264,7 +332,7 @@ func TestFindAllProductsPageLimitZeroSortAsc(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(1, 0, "asc")
@@ -277,7 +345,7 @@ func TestFindAllProductsPageLimitZeroSortDesc(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(1, 0, "desc")
@@ -290,7 +358,7 @@ func TestFindAllProductsPageLimitZeroSortInvalid(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	db.AutoMigrate(&entity.Product{})
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
 	productDB := NewProduct(db)
 
 	products, err := productDB.FindAll(1, 0, "invalid")
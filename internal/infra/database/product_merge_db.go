@@ -0,0 +1,234 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+)
+
+// mergedTable is a table of rows keyed by a product and key. When a source
+// and the target both have a row for a key, sums are added to the target row
+// and the source row is dropped.
+type mergedTable struct {
+	name string
+	key  string
+	sums []string
+}
+
+// mergedTables hold the data moved from the sources to the target of a merge.
+// Orders and purchase orders keep pointing at the
+// sources: they are history, and the redirects resolve their products.
+var mergedTables = []mergedTable{
+	{name: "bundle_components", key: "bundle_id", sums: []string{"quantity"}},
+	{name: "cart_lines", key: "cart_id", sums: []string{"quantity"}},
+	{name: "wishlist_items", key: "wishlist_id"},
+	{name: "stock_levels", key: "warehouse_id", sums: []string{"quantity"}},
+	{name: "product_suppliers", key: "supplier_id"},
+	{name: "product_translations", key: "locale"},
+	{name: "product_view_dailies", key: "day", sums: []string{"views"}},
+	{name: "reviews", key: "user_id"},
+}
+
+// Merge merges the source products into the target in one transaction: the
+// fields of the target are resolved with rules, the data of the sources is
+// moved to it, the sources are deleted and their IDs redirected to the
+// target, and the merge is recorded.
+func (p *Product) Merge(targetID string, sourceIDs []string, rules map[string]string, userID *entityPkg.ID) (*entity.Product, *entity.ProductMerge, error) {
+	var target entity.Product
+	var merge *entity.ProductMerge
+	var bundles []entity.Product
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Components").Where("id = ?", targetID).First(&target).Error; err != nil {
+			return err
+		}
+		var sources []entity.Product
+		if err := tx.Where("id IN ?", sourceIDs).Find(&sources).Error; err != nil {
+			return err
+		}
+		if len(sources) != len(sourceIDs) {
+			return entity.ErrMergeSourceInvalid
+		}
+		for _, source := range sources {
+			bundleIDs, err := bundlesOf(tx, source.ID.String())
+			if err != nil {
+				return err
+			}
+			for _, bundleID := range bundleIDs {
+				if bundleID == targetID {
+					return entity.ErrMergeSourceInvalid
+				}
+			}
+		}
+
+		name := target.Name
+		var err error
+		merge, err = entity.MergeProducts(&target, sources, rules, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			if err := mergeProduct(tx, source.ID, target.ID, merge.ID); err != nil {
+				return err
+			}
+		}
+		if err := recountRating(tx, target.ID); err != nil {
+			return err
+		}
+		if target.Name != name {
+			if err := reserveSlug(tx, &target); err != nil {
+				return err
+			}
+		}
+		if err := saveProduct(tx, &target); err != nil {
+			return err
+		}
+		bundles, err = refreshBundlesOf(tx, targetID)
+		if err != nil {
+			return err
+		}
+		return tx.Create(merge).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	p.indexRemove(sourceIDs...)
+	product, err := p.FindByID(targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.indexPut(append(bundles, *product)...)
+	return product, merge, nil
+}
+
+// mergeProduct moves the data of source to target, deletes source and
+// redirects its ID, and the IDs already redirected to it, to target.
+func mergeProduct(tx *gorm.DB, source, target, mergeID entityPkg.ID) error {
+	for _, table := range mergedTables {
+		if err := mergeRows(tx, table, source, target); err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&entity.ProductSlug{}).Where("product_id = ?", source).Update("product_id", target).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id = ?", source).Delete(&entity.StockAlert{}).Error; err != nil {
+		return err
+	}
+	if err := retargetPromotions(tx, source.String(), target.String()); err != nil {
+		return err
+	}
+	if err := retargetCoupons(tx, source.String(), target.String()); err != nil {
+		return err
+	}
+	err := tx.Model(&entity.StockTransfer{}).
+		Where("product_id = ? AND status = ?", source, entity.TransferInTransit).
+		Update("product_id", target).Error
+	if err != nil {
+		return err
+	}
+	if err := deleteProduct(tx, source.String()); err != nil {
+		return err
+	}
+
+	if err := tx.Model(&entity.ProductRedirect{}).Where("to_id = ?", source).Update("to_id", target).Error; err != nil {
+		return err
+	}
+	return tx.Create(&entity.ProductRedirect{
+		FromID:    source,
+		ToID:      target,
+		MergeID:   mergeID,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// mergeRows moves the rows of source in table to target, folding the rows
+// whose key target already has into the target ones.
+func mergeRows(tx *gorm.DB, table mergedTable, source, target entityPkg.ID) error {
+	shared := fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE product_id = ?)", table.key, table.key, table.name)
+	if len(table.sums) > 0 {
+		sums := map[string]interface{}{}
+		for _, column := range table.sums {
+			sums[column] = gorm.Expr(fmt.Sprintf(
+				"%s + (SELECT s.%s FROM %s s WHERE s.product_id = ? AND s.%s = %s.%s)",
+				column, column, table.name, table.key, table.name, table.key,
+			), source)
+		}
+		err := tx.Table(table.name).Where("product_id = ?", target).Where(shared, source).UpdateColumns(sums).Error
+		if err != nil {
+			return err
+		}
+	}
+	err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE product_id = ? AND %s", table.name, shared), source, target).Error
+	if err != nil {
+		return err
+	}
+	return tx.Table(table.name).Where("product_id = ?", source).UpdateColumn("product_id", target).Error
+}
+
+// retargetPromotions replaces source by target in the promotions targeting
+// it.
+func retargetPromotions(tx *gorm.DB, source, target string) error {
+	var promotions []entity.Promotion
+	if err := tx.Where("product_ids LIKE ?", "%"+source+"%").Find(&promotions).Error; err != nil {
+		return err
+	}
+	for _, promotion := range promotions {
+		ids := retargetIDs(promotion.ProductIDs, source, target)
+		if err := tx.Model(&promotion).Update("product_ids", ids).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retargetCoupons replaces source by target in the coupons restricted to it.
+func retargetCoupons(tx *gorm.DB, source, target string) error {
+	var coupons []entity.Coupon
+	if err := tx.Where("product_ids LIKE ?", "%"+source+"%").Find(&coupons).Error; err != nil {
+		return err
+	}
+	for _, coupon := range coupons {
+		ids := retargetIDs(coupon.ProductIDs, source, target)
+		if err := tx.Model(&coupon).Update("product_ids", ids).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retargetIDs replaces source by target in ids, without repeating target.
+func retargetIDs(ids entity.StringList, source, target string) entity.StringList {
+	retargeted := entity.StringList{}
+	for _, id := range ids {
+		if id == source {
+			id = target
+		}
+		if !retargeted.Contains(id) {
+			retargeted = append(retargeted, id)
+		}
+	}
+	return retargeted
+}
+
+// FindRedirect returns where the ID of a product merged away now points.
+func (p *Product) FindRedirect(id string) (*entity.ProductRedirect, error) {
+	var redirect entity.ProductRedirect
+	if err := p.DB.Where("from_id = ?", id).First(&redirect).Error; err != nil {
+		return nil, err
+	}
+	return &redirect, nil
+}
+
+// FindMerges lists the merges into a product, newest first.
+func (p *Product) FindMerges(productID string) ([]entity.ProductMerge, error) {
+	if _, err := p.FindByID(productID); err != nil {
+		return nil, err
+	}
+	var merges []entity.ProductMerge
+	err := p.DB.Where("target_id = ?", productID).Order("created_at desc").Find(&merges).Error
+	return merges, err
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMergeProducts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{}, &entity.StockLevel{}, &entity.StockTransfer{}, &entity.CartLine{}, &entity.WishlistItem{}, &entity.ProductSupplier{}, &entity.ProductViewDaily{}, &entity.Review{}, &entity.StockAlert{}, &entity.Promotion{}, &entity.Coupon{}, &entity.ProductMerge{}, &entity.ProductRedirect{})
+	productDB := NewProduct(db)
+	reviewDB := NewReview(db)
+
+	target, _ := entity.NewProduct("iPhone 15", 10)
+	target.Tags = entity.StringList{"phone"}
+	source, _ := entity.NewProduct("iPhone-15 Apple", 8)
+	source.Tags = entity.StringList{"apple"}
+	assert.Nil(t, productDB.Save(target))
+	assert.Nil(t, productDB.Save(source))
+
+	userID := entityPkg.NewID()
+	for _, review := range []struct {
+		productID entityPkg.ID
+		userID    entityPkg.ID
+		rating    int
+	}{{target.ID, userID, 5}, {source.ID, userID, 1}, {source.ID, entityPkg.NewID(), 4}} {
+		r, _ := entity.NewReview(review.productID, review.userID, review.rating, "", "")
+		assert.Nil(t, reviewDB.Create(r))
+	}
+	warehouseID := entityPkg.NewID()
+	assert.Nil(t, db.Create(&[]entity.StockLevel{
+		{ProductID: target.ID, WarehouseID: warehouseID, Quantity: 3},
+		{ProductID: source.ID, WarehouseID: warehouseID, Quantity: 2},
+	}).Error)
+
+	transfer := entity.StockTransfer{ID: entityPkg.NewID(), ProductID: source.ID, FromWarehouseID: entityPkg.NewID(), ToWarehouseID: warehouseID, Quantity: 1, Status: entity.TransferInTransit}
+	assert.Nil(t, db.Create(&transfer).Error)
+	coupon := entity.Coupon{Code: "IPHONE", Kind: entity.DiscountFixed, Value: 1, ProductIDs: entity.StringList{source.ID.String(), target.ID.String()}}
+	assert.Nil(t, db.Create(&coupon).Error)
+
+	_, _, err = productDB.Merge(target.ID.String(), []string{source.ID.String()}, map[string]string{"price": "median"}, nil)
+	assert.Equal(t, entity.ErrMergeRuleInvalid, err)
+	_, _, err = productDB.Merge(target.ID.String(), []string{entityPkg.NewID().String()}, nil, nil)
+	assert.Equal(t, entity.ErrMergeSourceInvalid, err)
+
+	merged, merge, err := productDB.Merge(target.ID.String(), []string{source.ID.String()}, map[string]string{"price": "min", "name": source.ID.String()}, &userID)
+	assert.Nil(t, err)
+	assert.Equal(t, "iPhone-15 Apple", merged.Name)
+	assert.Equal(t, "iphone-15-apple", merged.Slug)
+	assert.Equal(t, 8.0, merged.Price)
+	assert.Equal(t, entity.StringList{"apple", "phone"}, merged.Tags)
+	assert.Equal(t, 2, merged.Rating.Count)
+	assert.Equal(t, 4.5, merged.Rating.Average)
+	assert.Equal(t, entity.StringList{source.ID.String()}, merge.SourceIDs)
+	assert.Equal(t, entity.MergeRuleUnion, merge.Rules["tags"])
+
+	var level entity.StockLevel
+	assert.Nil(t, db.Where("product_id = ?", target.ID).First(&level).Error)
+	assert.Equal(t, 5, level.Quantity)
+	assert.Nil(t, db.Where("code = ?", coupon.Code).First(&coupon).Error)
+	assert.Equal(t, entity.StringList{target.ID.String()}, coupon.ProductIDs)
+	assert.Nil(t, db.Where("id = ?", transfer.ID).First(&transfer).Error)
+	assert.Equal(t, target.ID, transfer.ProductID)
+
+	_, err = productDB.FindByID(source.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	redirect, err := productDB.FindRedirect(source.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, target.ID, redirect.ToID)
+	bySlug, err := productDB.FindBySlug("iphone-15")
+	assert.Nil(t, err)
+	assert.Equal(t, target.ID, bySlug.ID)
+
+	other, _ := entity.NewProduct("iPhone 15 (2023)", 9)
+	assert.Nil(t, productDB.Save(other))
+	_, _, err = productDB.Merge(other.ID.String(), []string{target.ID.String()}, nil, nil)
+	assert.Nil(t, err)
+	redirect, err = productDB.FindRedirect(source.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, other.ID, redirect.ToID)
+
+	merges, err := productDB.FindMerges(target.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Nil(t, merges)
+	merges, err = productDB.FindMerges(other.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, merges, 1)
+}
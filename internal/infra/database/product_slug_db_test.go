@@ -0,0 +1,103 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSaveProductGeneratesUniqueSlugs(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
+	productDB := NewProduct(db)
+
+	first, _ := entity.NewProduct("Café Especial", 10)
+	second, _ := entity.NewProduct("Cafe especial", 10)
+	assert.Nil(t, productDB.Save(first))
+	assert.Nil(t, productDB.Save(second))
+
+	assert.Equal(t, "cafe-especial", first.Slug)
+	assert.Equal(t, "cafe-especial-2", second.Slug)
+}
+
+func TestUpdateProductRetiresSlug(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
+	productDB := NewProduct(db)
+
+	product, _ := entity.NewProduct("Old Name", 10)
+	assert.Nil(t, productDB.Save(product))
+
+	product.Name = "New Name"
+	assert.Nil(t, productDB.Update(product))
+	assert.Equal(t, "new-name", product.Slug)
+
+	found, err := productDB.FindBySlug("old-name")
+	assert.Nil(t, err)
+	assert.Equal(t, product.ID, found.ID)
+	assert.Equal(t, "new-name", found.Slug)
+
+	// Another product must not take over the retired slug.
+	other, _ := entity.NewProduct("Old Name", 10)
+	assert.Nil(t, productDB.Save(other))
+	assert.Equal(t, "old-name-2", other.Slug)
+
+	// Renaming back reuses the product's own retired slug.
+	product.Name = "Old Name"
+	assert.Nil(t, productDB.Update(product))
+	assert.Equal(t, "old-name", product.Slug)
+}
+
+func TestFindBySlugNotFound(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
+	productDB := NewProduct(db)
+
+	found, err := productDB.FindBySlug("missing")
+	assert.NotNil(t, err)
+	assert.Nil(t, found)
+}
+
+func TestSaveProductSlugsConcurrently(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{})
+	productDB := NewProduct(db)
+
+	const n = 10
+	products := make([]*entity.Product, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		products[i], _ = entity.NewProduct("Same Name", 10)
+		wg.Add(1)
+		go func(p *entity.Product) {
+			defer wg.Done()
+			assert.Nil(t, productDB.Save(p))
+		}(products[i])
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, p := range products {
+		assert.False(t, seen[p.Slug], p.Slug)
+		seen[p.Slug] = true
+	}
+	assert.Len(t, seen, n)
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+)
+
+type ProductTemplate struct {
+	DB *gorm.DB
+}
+
+func NewProductTemplate(db *gorm.DB) *ProductTemplate {
+	return &ProductTemplate{
+		DB: db,
+	}
+}
+
+func (t *ProductTemplate) Create(template *entity.ProductTemplate) error {
+	return t.DB.Create(template).Error
+}
+
+func (t *ProductTemplate) FindAll() ([]entity.ProductTemplate, error) {
+	var templates []entity.ProductTemplate
+	err := t.DB.Order("name").Find(&templates).Error
+	return templates, err
+}
+
+func (t *ProductTemplate) FindByID(id string) (*entity.ProductTemplate, error) {
+	var template entity.ProductTemplate
+	if err := t.DB.Where("id = ?", id).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (t *ProductTemplate) Update(template *entity.ProductTemplate) error {
+	if _, err := t.FindByID(template.ID.String()); err != nil {
+		return err
+	}
+	return t.DB.Save(template).Error
+}
+
+func (t *ProductTemplate) Delete(id string) error {
+	result := t.DB.Where("id = ?", id).Delete(&entity.ProductTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
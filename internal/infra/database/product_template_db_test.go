@@ -0,0 +1,43 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestProductTemplateCRUD(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.ProductTemplate{})
+	templateDB := NewProductTemplate(db)
+
+	available := false
+	template, _ := entity.NewProductTemplate("T-shirt", 19.9, "Cotton", &available, []string{"clothes"})
+	assert.Nil(t, templateDB.Create(template))
+	duplicated, _ := entity.NewProductTemplate("T-shirt", 10, "", nil, nil)
+	assert.NotNil(t, templateDB.Create(duplicated))
+
+	found, err := templateDB.FindByID(template.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, 19.9, found.Price)
+	assert.False(t, *found.Available)
+	assert.Equal(t, entity.StringList{"clothes"}, found.Tags)
+
+	found.Price = 24.9
+	assert.Nil(t, templateDB.Update(found))
+	templates, err := templateDB.FindAll()
+	assert.Nil(t, err)
+	assert.Len(t, templates, 1)
+	assert.Equal(t, 24.9, templates[0].Price)
+
+	assert.Nil(t, templateDB.Delete(template.ID.String()))
+	assert.ErrorIs(t, templateDB.Delete(template.ID.String()), gorm.ErrRecordNotFound)
+	_, err = templateDB.FindByID(template.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
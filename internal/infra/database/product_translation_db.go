@@ -0,0 +1,60 @@
+package database
+
+import (
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductTranslation struct {
+	DB *gorm.DB
+}
+
+func NewProductTranslation(db *gorm.DB) *ProductTranslation {
+	return &ProductTranslation{
+		DB: db,
+	}
+}
+
+func (t *ProductTranslation) FindByProductID(productID string) ([]entity.ProductTranslation, error) {
+	var translations []entity.ProductTranslation
+	err := t.DB.Where("product_id = ?", productID).Order("locale").Find(&translations).Error
+	return translations, err
+}
+
+func (t *ProductTranslation) FindByProductIDs(productIDs []string) (map[string][]entity.ProductTranslation, error) {
+	result := make(map[string][]entity.ProductTranslation, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	var translations []entity.ProductTranslation
+	if err := t.DB.Where("product_id IN ?", productIDs).Order("locale").Find(&translations).Error; err != nil {
+		return nil, err
+	}
+	for _, translation := range translations {
+		id := translation.ProductID.String()
+		result[id] = append(result[id], translation)
+	}
+	return result, nil
+}
+
+// Save inserts the translation or replaces the existing one for the same
+// product and locale.
+func (t *ProductTranslation) Save(translation *entity.ProductTranslation) error {
+	return t.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "description_html", "updated_at"}),
+	}).Create(translation).Error
+}
+
+func (t *ProductTranslation) Delete(productID, locale string) error {
+	result := t.DB.Where("product_id = ? AND locale = ?", productID, locale).Delete(&entity.ProductTranslation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
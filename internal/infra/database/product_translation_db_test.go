@@ -0,0 +1,97 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSaveProductTranslation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{})
+	product, err := entity.NewProduct("Product 1", 10)
+	if err != nil {
+		t.Error(err)
+	}
+	translation, err := entity.NewProductTranslation(product.ID, "pt-BR", "Produto 1", "")
+	if err != nil {
+		t.Error(err)
+	}
+	translationDB := NewProductTranslation(db)
+
+	err = translationDB.Save(translation)
+	assert.Nil(t, err)
+
+	translations, err := translationDB.FindByProductID(product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, translations, 1)
+	assert.Equal(t, "Produto 1", translations[0].Name)
+}
+
+func TestSaveProductTranslationReplacesLocale(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{})
+	product, err := entity.NewProduct("Product 1", 10)
+	if err != nil {
+		t.Error(err)
+	}
+	translationDB := NewProductTranslation(db)
+
+	first, _ := entity.NewProductTranslation(product.ID, "pt-BR", "Produto 1", "")
+	second, _ := entity.NewProductTranslation(product.ID, "pt-BR", "Produto Um", "")
+	assert.Nil(t, translationDB.Save(first))
+	assert.Nil(t, translationDB.Save(second))
+
+	translations, err := translationDB.FindByProductIDs([]string{product.ID.String()})
+	assert.Nil(t, err)
+	assert.Len(t, translations[product.ID.String()], 1)
+	assert.Equal(t, "Produto Um", translations[product.ID.String()][0].Name)
+}
+
+func TestDeleteProductTranslation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{})
+	product, err := entity.NewProduct("Product 1", 10)
+	if err != nil {
+		t.Error(err)
+	}
+	translationDB := NewProductTranslation(db)
+	translation, _ := entity.NewProductTranslation(product.ID, "pt-BR", "Produto 1", "")
+	assert.Nil(t, translationDB.Save(translation))
+
+	err = translationDB.Delete(product.ID.String(), "pt-BR")
+	assert.Nil(t, err)
+
+	err = translationDB.Delete(product.ID.String(), "pt-BR")
+	assert.NotNil(t, err)
+}
+
+func TestDeleteProductDeletesTranslations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{}, &entity.StockLevel{}, &entity.StockTransfer{})
+	product, _ := entity.NewProduct("Product 1", 10)
+	productDB := NewProduct(db)
+	assert.Nil(t, productDB.Save(product))
+	translation, _ := entity.NewProductTranslation(product.ID, "pt-BR", "Produto 1", "")
+	assert.Nil(t, NewProductTranslation(db).Save(translation))
+
+	assert.Nil(t, productDB.Delete(product.ID.String()))
+	var count int64
+	db.Model(&entity.ProductTranslation{}).Where("product_id = ?", product.ID).Count(&count)
+	assert.Zero(t, count)
+}
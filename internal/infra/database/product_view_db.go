@@ -0,0 +1,55 @@
+package database
+
+import (
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductView struct {
+	DB *gorm.DB
+}
+
+func NewProductView(db *gorm.DB) *ProductView {
+	return &ProductView{
+		DB: db,
+	}
+}
+
+// AddViews adds the counts to the daily roll-ups in one transaction.
+func (p *ProductView) AddViews(counts map[entity.ViewKey]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	rows := make([]entity.ProductViewDaily, 0, len(counts))
+	for key, views := range counts {
+		rows = append(rows, entity.ProductViewDaily{ProductID: key.ProductID, Day: key.Day, Views: views})
+	}
+	return p.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("product_view_dailies.views + excluded.views")}),
+		}).CreateInBatches(rows, 100).Error
+	})
+}
+
+// FindPopular returns the most viewed products since the given day.
+func (p *ProductView) FindPopular(since string, limit int) ([]entity.PopularProduct, error) {
+	var popular []entity.PopularProduct
+	err := p.DB.Model(&entity.ProductViewDaily{}).
+		Select("products.id AS product_id, products.name, SUM(product_view_dailies.views) AS views").
+		Joins("JOIN products ON products.id = product_view_dailies.product_id").
+		Where("product_view_dailies.day >= ?", since).
+		Group("products.id").
+		Order("views desc, products.name").
+		Limit(limit).
+		Scan(&popular).Error
+	return popular, err
+}
+
+func (p *ProductView) FindDaily(productID, since string) ([]entity.ProductViewDaily, error) {
+	var daily []entity.ProductViewDaily
+	err := p.DB.Where("product_id = ? AND day >= ?", productID, since).Order("day").Find(&daily).Error
+	return daily, err
+}
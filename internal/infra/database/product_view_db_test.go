@@ -0,0 +1,45 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAddProductViews(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductViewDaily{})
+	viewDB := NewProductView(db)
+	first, _ := entity.NewProduct("First", 10)
+	second, _ := entity.NewProduct("Second", 10)
+	assert.Nil(t, db.Create(first).Error)
+	assert.Nil(t, db.Create(second).Error)
+
+	assert.Nil(t, viewDB.AddViews(map[entity.ViewKey]int64{
+		{ProductID: first.ID, Day: "2024-03-01"}:  2,
+		{ProductID: second.ID, Day: "2024-03-01"}: 3,
+		{ProductID: first.ID, Day: "2024-02-01"}:  10,
+	}))
+	assert.Nil(t, viewDB.AddViews(map[entity.ViewKey]int64{
+		{ProductID: first.ID, Day: "2024-03-01"}: 2,
+		{ProductID: first.ID, Day: "2024-03-02"}: 1,
+	}))
+
+	daily, err := viewDB.FindDaily(first.ID.String(), "2024-03-01")
+	assert.Nil(t, err)
+	assert.Len(t, daily, 2)
+	assert.Equal(t, int64(4), daily[0].Views)
+
+	popular, err := viewDB.FindPopular("2024-03-01", 10)
+	assert.Nil(t, err)
+	assert.Len(t, popular, 2)
+	assert.Equal(t, "First", popular[0].Name)
+	assert.Equal(t, int64(5), popular[0].Views)
+	assert.Equal(t, int64(3), popular[1].Views)
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+)
+
+type Promotion struct {
+	DB *gorm.DB
+}
+
+func NewPromotion(db *gorm.DB) *Promotion {
+	return &Promotion{
+		DB: db,
+	}
+}
+
+func (p *Promotion) FindAll() ([]entity.Promotion, error) {
+	var promotions []entity.Promotion
+	err := p.DB.Order("priority desc, id").Find(&promotions).Error
+	return promotions, err
+}
+
+// FindRunning returns the promotions whose date window contains now.
+func (p *Promotion) FindRunning(now time.Time) ([]entity.Promotion, error) {
+	var promotions []entity.Promotion
+	now = now.UTC()
+	err := p.DB.
+		Where("starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Order("priority desc, id").
+		Find(&promotions).Error
+	return promotions, err
+}
+
+func (p *Promotion) FindByID(id string) (*entity.Promotion, error) {
+	var promotion entity.Promotion
+	if err := p.DB.Where("id = ?", id).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (p *Promotion) Save(promotion *entity.Promotion) error {
+	return p.DB.Save(promotion).Error
+}
+
+func (p *Promotion) Update(promotion *entity.Promotion) error {
+	if _, err := p.FindByID(promotion.ID.String()); err != nil {
+		return err
+	}
+	return p.DB.Save(promotion).Error
+}
+
+func (p *Promotion) Delete(id string) error {
+	if _, err := p.FindByID(id); err != nil {
+		return err
+	}
+	return p.DB.Where("id = ?", id).Delete(&entity.Promotion{}).Error
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCreatePromotion(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Promotion{})
+	promotion, err := entity.NewPromotion("Summer", entity.DiscountPercentage, 10, time.Now(), nil)
+	if err != nil {
+		t.Error(err)
+	}
+	promotion.Tags = entity.StringList{"shoes"}
+	promotionDB := NewPromotion(db)
+
+	err = promotionDB.Save(promotion)
+	assert.Nil(t, err)
+
+	found, err := promotionDB.FindByID(promotion.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, promotion.Name, found.Name)
+	assert.Equal(t, entity.StringList{"shoes"}, found.Tags)
+}
+
+func TestFindRunningPromotions(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Promotion{})
+	promotionDB := NewPromotion(db)
+
+	now := time.Now()
+	ended := now.Add(-time.Hour)
+	running, _ := entity.NewPromotion("Running", entity.DiscountFixed, 5, now.Add(-time.Hour), nil)
+	past, _ := entity.NewPromotion("Past", entity.DiscountFixed, 5, now.Add(-2*time.Hour), &ended)
+	future, _ := entity.NewPromotion("Future", entity.DiscountFixed, 5, now.Add(time.Hour), nil)
+	assert.Nil(t, promotionDB.Save(running))
+	assert.Nil(t, promotionDB.Save(past))
+	assert.Nil(t, promotionDB.Save(future))
+
+	promotions, err := promotionDB.FindRunning(now)
+	assert.Nil(t, err)
+	assert.Len(t, promotions, 1)
+	assert.Equal(t, running.ID, promotions[0].ID)
+}
+
+func TestDeletePromotion(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Promotion{})
+	promotionDB := NewPromotion(db)
+	promotion, _ := entity.NewPromotion("Summer", entity.DiscountFixed, 5, time.Now(), nil)
+	assert.Nil(t, promotionDB.Save(promotion))
+
+	assert.Nil(t, promotionDB.Delete(promotion.ID.String()))
+	assert.NotNil(t, promotionDB.Delete(promotion.ID.String()))
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PurchaseOrderFilter struct {
+	SupplierID string
+	Status     string
+}
+
+type PurchaseOrder struct {
+	DB *gorm.DB
+}
+
+func NewPurchaseOrder(db *gorm.DB) *PurchaseOrder {
+	return &PurchaseOrder{
+		DB: db,
+	}
+}
+
+func (p *PurchaseOrder) FindByID(id string) (*entity.PurchaseOrder, error) {
+	return findPurchaseOrder(p.DB, id)
+}
+
+func (p *PurchaseOrder) FindAll(filter PurchaseOrderFilter) ([]entity.PurchaseOrder, error) {
+	var orders []entity.PurchaseOrder
+	query := p.DB.Preload("Lines").Order("created_at desc")
+	if filter.SupplierID != "" {
+		query = query.Where("supplier_id = ?", filter.SupplierID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+// Save stores the purchase order and replaces its lines.
+func (p *PurchaseOrder) Save(order *entity.PurchaseOrder) error {
+	return p.DB.Transaction(func(tx *gorm.DB) error {
+		return savePurchaseOrder(tx, order)
+	})
+}
+
+// Receive records the quantities delivered for the purchase order. The cost
+// of each received line becomes the cost price of the product and of its
+// supplier link, in the same transaction.
+func (p *PurchaseOrder) Receive(id string, quantities map[entityPkg.ID]int, now time.Time) (*entity.PurchaseOrder, error) {
+	var order *entity.PurchaseOrder
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = findPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		receipts, err := order.Receive(quantities, now)
+		if err != nil {
+			return err
+		}
+		if err := savePurchaseOrder(tx, order); err != nil {
+			return err
+		}
+
+		for _, receipt := range receipts {
+			err := tx.Model(&entity.Product{}).Where("id = ?", receipt.ProductID).
+				UpdateColumn("cost_price", receipt.UnitCost).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&entity.ProductSupplier{}).
+				Where("product_id = ? AND supplier_id = ?", receipt.ProductID, order.SupplierID).
+				UpdateColumns(map[string]interface{}{"cost_price": receipt.UnitCost, "last_received_at": now, "updated_at": now}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func findPurchaseOrder(db *gorm.DB, id string) (*entity.PurchaseOrder, error) {
+	var order entity.PurchaseOrder
+	err := db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("product_id")
+	}).Where("id = ?", id).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func savePurchaseOrder(tx *gorm.DB, order *entity.PurchaseOrder) error {
+	if err := tx.Omit(clause.Associations).Save(order).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&entity.PurchaseOrderLine{}).Error; err != nil {
+		return err
+	}
+	if len(order.Lines) == 0 {
+		return nil
+	}
+	return tx.Create(&order.Lines).Error
+}
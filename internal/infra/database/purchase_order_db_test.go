@@ -0,0 +1,79 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestReceivePurchaseOrderUpdatesCost(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.Supplier{}, &entity.ProductSupplier{}, &entity.PurchaseOrder{}, &entity.PurchaseOrderLine{})
+	productDB := NewProduct(db)
+	supplierDB := NewSupplier(db)
+	orderDB := NewPurchaseOrder(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, productDB.Save(product))
+	supplier, _ := entity.NewSupplier("Acme", "", "")
+	assert.Nil(t, supplierDB.Create(supplier))
+	link, _ := entity.NewProductSupplier(product.ID, supplier.ID, "ACME-1", 5, 7)
+	assert.Nil(t, supplierDB.SaveLink(link))
+
+	order := entity.NewPurchaseOrder(supplier.ID, "")
+	assert.Nil(t, order.SetLine(product.ID, 10, 6.5))
+	assert.Nil(t, order.Send(time.Now()))
+	assert.Nil(t, orderDB.Save(order))
+
+	received, err := orderDB.Receive(order.ID.String(), map[entityPkg.ID]int{product.ID: 4}, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.PurchaseOrderPartiallyReceived, received.Status)
+
+	found, _ := productDB.FindByID(product.ID.String())
+	assert.Equal(t, 6.5, found.CostPrice)
+	links, _ := supplierDB.FindLinksByProductID(product.ID.String())
+	assert.Equal(t, 6.5, links[0].CostPrice)
+	assert.NotNil(t, links[0].LastReceivedAt)
+
+	// Editing the product does not overwrite the cost set by the receipt.
+	product.Price = 12
+	assert.Nil(t, productDB.Update(product))
+	found, _ = productDB.FindByID(product.ID.String())
+	assert.Equal(t, 6.5, found.CostPrice)
+}
+
+func TestReceivePurchaseOrderIsAllOrNothing(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.Supplier{}, &entity.ProductSupplier{}, &entity.PurchaseOrder{}, &entity.PurchaseOrderLine{})
+	orderDB := NewPurchaseOrder(db)
+	first, second := entityPkg.NewID(), entityPkg.NewID()
+	order := entity.NewPurchaseOrder(entityPkg.NewID(), "")
+	assert.Nil(t, order.SetLine(first, 2, 1))
+	assert.Nil(t, order.SetLine(second, 2, 1))
+	assert.Nil(t, order.Send(time.Now()))
+	assert.Nil(t, orderDB.Save(order))
+
+	_, err = orderDB.Receive(order.ID.String(), map[entityPkg.ID]int{first: 1, second: 3}, time.Now())
+	assert.Equal(t, entity.ErrReceiveExceedsOrdered, err)
+
+	found, err := orderDB.FindByID(order.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.PurchaseOrderSent, found.Status)
+	for _, line := range found.Lines {
+		assert.Equal(t, 0, line.ReceivedQuantity)
+	}
+
+	orders, err := orderDB.FindAll(PurchaseOrderFilter{Status: entity.PurchaseOrderSent})
+	assert.Nil(t, err)
+	assert.Len(t, orders, 1)
+}
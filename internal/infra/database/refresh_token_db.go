@@ -0,0 +1,95 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+)
+
+type RefreshToken struct {
+	DB *gorm.DB
+}
+
+func NewRefreshToken(db *gorm.DB) *RefreshToken {
+	return &RefreshToken{
+		DB: db,
+	}
+}
+
+// Create stores the first token of a family, and deletes the families of
+// the user past their absolute expiry. The rotated tokens of the live
+// families are kept to detect their reuse.
+func (r *RefreshToken) Create(token *entity.RefreshToken) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND expires_at <= ?", token.UserID, token.CreatedAt).Delete(&entity.RefreshToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// Rotate exchanges the plain token for its successor, returned with its
+// plain text. Presenting a token already rotated revokes its whole family
+// and fails with entity.ErrRefreshTokenReused.
+func (r *RefreshToken) Rotate(plain string, idleTTL time.Duration, now time.Time) (*entity.RefreshToken, string, error) {
+	var next *entity.RefreshToken
+	var nextPlain string
+	var reused *entity.RefreshToken
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var token entity.RefreshToken
+		if err := tx.Where("hash = ?", entity.HashRefreshToken(plain)).First(&token).Error; err != nil {
+			return err
+		}
+
+		var err error
+		next, nextPlain, err = token.Rotate(idleTTL, now)
+		if errors.Is(err, entity.ErrRefreshTokenReused) {
+			reused = &token
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Only one of concurrent rotations of the token wins, the others
+		// are reuses.
+		result := tx.Model(&entity.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", token.ID).
+			Update("rotated_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = &token
+			return nil
+		}
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if reused != nil {
+		if err := r.RevokeFamily(reused.FamilyID.String(), now); err != nil {
+			return nil, "", err
+		}
+		return nil, "", entity.ErrRefreshTokenReused
+	}
+	return next, nextPlain, nil
+}
+
+// RevokeFamily revokes the tokens of a family still valid.
+func (r *RefreshToken) RevokeFamily(familyID string, now time.Time) error {
+	return r.DB.Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+// RevokeUser revokes the tokens of every family of the user.
+func (r *RefreshToken) RevokeUser(userID string, now time.Time) error {
+	return r.DB.Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
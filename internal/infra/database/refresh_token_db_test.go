@@ -0,0 +1,84 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRefreshTokenRotateDetectsReuse(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.RefreshToken{})
+	tokenDB := NewRefreshToken(db)
+	now := time.Now()
+
+	token, plain, _ := entity.NewRefreshToken(entityPkg.NewID(), time.Hour, time.Hour, now)
+	assert.Nil(t, tokenDB.Create(token))
+
+	next, nextPlain, err := tokenDB.Rotate(plain, time.Hour, now.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, token.FamilyID, next.FamilyID)
+
+	_, _, err = tokenDB.Rotate(plain, time.Hour, now.Add(2*time.Minute))
+	assert.Equal(t, entity.ErrRefreshTokenReused, err)
+	// The reuse revoked the successor too.
+	_, _, err = tokenDB.Rotate(nextPlain, time.Hour, now.Add(3*time.Minute))
+	assert.Equal(t, entity.ErrRefreshTokenRevoked, err)
+
+	_, _, err = tokenDB.Rotate("unknown", time.Hour, now)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRefreshTokenCreateDeletesExpiredFamilies(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.RefreshToken{})
+	tokenDB := NewRefreshToken(db)
+	userID := entityPkg.NewID()
+	now := time.Now()
+
+	old, _, _ := entity.NewRefreshToken(userID, time.Hour, time.Hour, now.Add(-2*time.Hour))
+	assert.Nil(t, tokenDB.Create(old))
+	token, _, _ := entity.NewRefreshToken(userID, time.Hour, time.Hour, now)
+	assert.Nil(t, tokenDB.Create(token))
+
+	var count int64
+	db.Model(&entity.RefreshToken{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRefreshTokenRevokeUser(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.RefreshToken{})
+	tokenDB := NewRefreshToken(db)
+	userID := entityPkg.NewID()
+	now := time.Now()
+
+	first, firstPlain, _ := entity.NewRefreshToken(userID, time.Hour, time.Hour, now)
+	second, secondPlain, _ := entity.NewRefreshToken(userID, time.Hour, time.Hour, now)
+	other, otherPlain, _ := entity.NewRefreshToken(entityPkg.NewID(), time.Hour, time.Hour, now)
+	for _, token := range []*entity.RefreshToken{first, second, other} {
+		assert.Nil(t, tokenDB.Create(token))
+	}
+
+	assert.Nil(t, tokenDB.RevokeUser(userID.String(), now))
+	for _, plain := range []string{firstPlain, secondPlain} {
+		_, _, err = tokenDB.Rotate(plain, time.Hour, now)
+		assert.Equal(t, entity.ErrRefreshTokenRevoked, err)
+	}
+	_, _, err = tokenDB.Rotate(otherPlain, time.Hour, now)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"math"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+)
+
+type Review struct {
+	DB *gorm.DB
+}
+
+func NewReview(db *gorm.DB) *Review {
+	return &Review{
+		DB: db,
+	}
+}
+
+func (r *Review) FindByID(id string) (*entity.Review, error) {
+	var review entity.Review
+	if err := r.DB.Where("id = ?", id).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// FindByProductID lists the reviews of a product, newest first. Hidden reviews
+// are only included when includeHidden is set.
+func (r *Review) FindByProductID(productID string, includeHidden bool, page, limit int) ([]entity.Review, error) {
+	var reviews []entity.Review
+	query := r.DB.Where("product_id = ?", productID).Order("created_at desc")
+	if !includeHidden {
+		query = query.Where("hidden = ?", false)
+	}
+	if page != 0 && limit != 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	err := query.Find(&reviews).Error
+	return reviews, err
+}
+
+// Create stores the review and counts it in the rating of the product.
+func (r *Review) Create(review *entity.Review) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&entity.Review{}).
+			Where("product_id = ? AND user_id = ?", review.ProductID, review.UserID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return entity.ErrReviewDuplicated
+		}
+
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		return countRating(tx, review, 1)
+	})
+}
+
+// Update stores the changes of an edited or moderated review, moving its
+// contribution to the rating of the product accordingly.
+func (r *Review) Update(review *entity.Review) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var previous entity.Review
+		if err := tx.Where("id = ?", review.ID).First(&previous).Error; err != nil {
+			return err
+		}
+
+		if err := countRating(tx, &previous, -1); err != nil {
+			return err
+		}
+		if err := tx.Save(review).Error; err != nil {
+			return err
+		}
+		return countRating(tx, review, 1)
+	})
+}
+
+func (r *Review) Delete(id string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var review entity.Review
+		if err := tx.Where("id = ?", id).First(&review).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&review).Error; err != nil {
+			return err
+		}
+		return countRating(tx, &review, -1)
+	})
+}
+
+// countRating adds (delta 1) or removes (delta -1) a visible review from the
+// rating summary of its product with a single in-place update, so concurrent
+// reviews never overwrite each other's counts.
+func countRating(tx *gorm.DB, review *entity.Review, delta int) error {
+	if review.Hidden {
+		return nil
+	}
+
+	stars := entity.StarsColumn(review.Rating)
+	points := delta * review.Rating
+	result := tx.Model(&entity.Product{}).Where("id = ?", review.ProductID).UpdateColumns(map[string]interface{}{
+		"rating_count": gorm.Expr("rating_count + ?", delta),
+		"rating_sum":   gorm.Expr("rating_sum + ?", points),
+		stars:          gorm.Expr(stars+" + ?", delta),
+		"rating_average": gorm.Expr(
+			"CASE WHEN rating_count + ? > 0 THEN ROUND(CAST(rating_sum + ? AS REAL) / (rating_count + ?), 2) ELSE 0 END",
+			delta, points, delta,
+		),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// recountRating recomputes the rating summary of a product from its visible
+// reviews, for when reviews are moved to it in bulk.
+func recountRating(tx *gorm.DB, productID entityPkg.ID) error {
+	var counts []struct {
+		Rating int
+		Count  int
+	}
+	err := tx.Model(&entity.Review{}).
+		Select("rating, COUNT(*) AS count").
+		Where("product_id = ? AND hidden = ?", productID, false).
+		Group("rating").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+
+	columns := map[string]interface{}{}
+	for _, column := range entity.RatingColumns {
+		columns[column] = 0
+	}
+	total, sum := 0, 0
+	for _, c := range counts {
+		columns[entity.StarsColumn(c.Rating)] = c.Count
+		total += c.Count
+		sum += c.Count * c.Rating
+	}
+	columns["rating_count"] = total
+	columns["rating_sum"] = sum
+	if total > 0 {
+		columns["rating_average"] = math.Round(float64(sum)/float64(total)*100) / 100
+	}
+	return tx.Model(&entity.Product{}).Where("id = ?", productID).UpdateColumns(columns).Error
+}
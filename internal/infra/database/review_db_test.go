@@ -0,0 +1,153 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCreateReviewUpdatesRating(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.Review{})
+	productDB := NewProduct(db)
+	reviewDB := NewReview(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, productDB.Save(product))
+
+	first, _ := entity.NewReview(product.ID, entityPkg.NewID(), 5, "Great", "")
+	second, _ := entity.NewReview(product.ID, entityPkg.NewID(), 4, "Good", "")
+	assert.Nil(t, reviewDB.Create(first))
+	assert.Nil(t, reviewDB.Create(second))
+
+	duplicated, _ := entity.NewReview(product.ID, first.UserID, 1, "Again", "")
+	assert.Equal(t, entity.ErrReviewDuplicated, reviewDB.Create(duplicated))
+
+	found, err := productDB.FindByID(product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, found.Rating.Count)
+	assert.Equal(t, 4.5, found.Rating.Average)
+	assert.Equal(t, 1, found.Rating.Histogram.Stars(5))
+	assert.Equal(t, 1, found.Rating.Histogram.Stars(4))
+}
+
+func TestUpdateAndDeleteReviewUpdatesRating(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.Review{})
+	productDB := NewProduct(db)
+	reviewDB := NewReview(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, productDB.Save(product))
+	first, _ := entity.NewReview(product.ID, entityPkg.NewID(), 5, "Great", "")
+	second, _ := entity.NewReview(product.ID, entityPkg.NewID(), 1, "Bad", "")
+	assert.Nil(t, reviewDB.Create(first))
+	assert.Nil(t, reviewDB.Create(second))
+
+	assert.Nil(t, first.Edit(3, "Fine", ""))
+	assert.Nil(t, reviewDB.Update(first))
+	found, _ := productDB.FindByID(product.ID.String())
+	assert.Equal(t, 2.0, found.Rating.Average)
+	assert.Equal(t, 0, found.Rating.Histogram.Stars(5))
+	assert.Equal(t, 1, found.Rating.Histogram.Stars(3))
+
+	assert.Nil(t, second.Moderate(true, "abusive"))
+	assert.Nil(t, reviewDB.Update(second))
+	found, _ = productDB.FindByID(product.ID.String())
+	assert.Equal(t, 1, found.Rating.Count)
+	assert.Equal(t, 3.0, found.Rating.Average)
+	visible, err := reviewDB.FindByProductID(product.ID.String(), false, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, visible, 1)
+
+	assert.Nil(t, reviewDB.Delete(first.ID.String()))
+	assert.Nil(t, reviewDB.Delete(second.ID.String()))
+	found, _ = productDB.FindByID(product.ID.String())
+	assert.Equal(t, 0, found.Rating.Count)
+	assert.Equal(t, 0.0, found.Rating.Average)
+}
+
+func TestUpdateProductKeepsRating(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.Review{})
+	productDB := NewProduct(db)
+	reviewDB := NewReview(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, productDB.Save(product))
+
+	stale, _ := productDB.FindByID(product.ID.String())
+	review, _ := entity.NewReview(product.ID, entityPkg.NewID(), 4, "Good", "")
+	assert.Nil(t, reviewDB.Create(review))
+	stale.Price = 20
+	assert.Nil(t, productDB.Update(stale))
+
+	found, _ := productDB.FindByID(product.ID.String())
+	assert.Equal(t, 20.0, found.Price)
+	assert.Equal(t, 1, found.Rating.Count)
+	assert.Equal(t, 4.0, found.Rating.Average)
+}
+
+func TestFindAllProductsByRating(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.Review{})
+	productDB := NewProduct(db)
+	reviewDB := NewReview(db)
+	ratings := []int{3, 5, 4}
+	for i, rating := range ratings {
+		product, _ := entity.NewProduct("Product "+string(rune('A'+i)), 10)
+		assert.Nil(t, productDB.Save(product))
+		review, _ := entity.NewReview(product.ID, entityPkg.NewID(), rating, "", "")
+		assert.Nil(t, reviewDB.Create(review))
+	}
+
+	products, err := productDB.FindAllBy(ProductFilter{MinRating: 4, OrderBy: ProductOrderRating}, 0, 0, "")
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "Product B", products[0].Name)
+	assert.Equal(t, "Product C", products[1].Name)
+}
+
+func TestCreateReviewsConcurrently(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "reviews.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.Review{})
+	productDB := NewProduct(db)
+	reviewDB := NewReview(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, productDB.Save(product))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(rating int) {
+			defer wg.Done()
+			review, _ := entity.NewReview(product.ID, entityPkg.NewID(), rating, "", "")
+			assert.Nil(t, reviewDB.Create(review))
+		}(i%5 + 1)
+	}
+	wg.Wait()
+
+	found, _ := productDB.FindByID(product.ID.String())
+	assert.Equal(t, 10, found.Rating.Count)
+	assert.Equal(t, 3.0, found.Rating.Average)
+	assert.Equal(t, 2, found.Rating.Histogram.Stars(1))
+}
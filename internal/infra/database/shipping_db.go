@@ -0,0 +1,79 @@
+package database
+
+import (
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Shipping struct {
+	DB *gorm.DB
+}
+
+func NewShipping(db *gorm.DB) *Shipping {
+	return &Shipping{
+		DB: db,
+	}
+}
+
+// CreateZone stores the zone with its methods and their rates.
+func (s *Shipping) CreateZone(zone *entity.ShippingZone) error {
+	return s.DB.Create(zone).Error
+}
+
+func (s *Shipping) FindZones() ([]entity.ShippingZone, error) {
+	var zones []entity.ShippingZone
+	err := s.DB.Preload("Methods.Rates").Order("name").Find(&zones).Error
+	return zones, err
+}
+
+func (s *Shipping) FindZoneByID(id string) (*entity.ShippingZone, error) {
+	var zone entity.ShippingZone
+	if err := s.DB.Preload("Methods.Rates").Where("id = ?", id).First(&zone).Error; err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// UpdateZone stores the zone, replacing its methods and rates.
+func (s *Shipping) UpdateZone(zone *entity.ShippingZone) error {
+	if _, err := s.FindZoneByID(zone.ID.String()); err != nil {
+		return err
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteMethods(tx, zone.ID.String()); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(zone).Error; err != nil {
+			return err
+		}
+		if len(zone.Methods) == 0 {
+			return nil
+		}
+		return tx.Create(&zone.Methods).Error
+	})
+}
+
+func (s *Shipping) DeleteZone(id string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteMethods(tx, id); err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.ShippingZone{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func deleteMethods(tx *gorm.DB, zoneID string) error {
+	methods := tx.Model(&entity.ShippingMethod{}).Select("id").Where("zone_id = ?", zoneID)
+	if err := tx.Where("method_id IN (?)", methods).Delete(&entity.ShippingRate{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("zone_id = ?", zoneID).Delete(&entity.ShippingMethod{}).Error
+}
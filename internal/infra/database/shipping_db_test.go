@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestShippingZones(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.ShippingZone{}, &entity.ShippingMethod{}, &entity.ShippingRate{})
+	shippingDB := NewShipping(db)
+
+	zone, _ := entity.NewShippingZone("Domestic", []entity.ShippingMethod{
+		{Name: "Standard", Rates: []entity.ShippingRate{{UpTo: 1, Price: 5}, {UpTo: 5, Price: 10}}},
+		{Name: "Express", Rates: []entity.ShippingRate{{UpTo: 5, Price: 20}}},
+	})
+	assert.Nil(t, shippingDB.CreateZone(zone))
+
+	found, err := shippingDB.FindZoneByID(zone.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, found.Methods, 2)
+	var rates int64
+	db.Model(&entity.ShippingRate{}).Count(&rates)
+	assert.Equal(t, int64(3), rates)
+
+	found.Name = "Home"
+	found.SetMethods([]entity.ShippingMethod{{Name: "Pickup", Rates: []entity.ShippingRate{{UpTo: 30, Price: 0}}}})
+	assert.Nil(t, shippingDB.UpdateZone(found))
+	zones, err := shippingDB.FindZones()
+	assert.Nil(t, err)
+	assert.Len(t, zones, 1)
+	assert.Equal(t, "Home", zones[0].Name)
+	assert.Len(t, zones[0].Methods, 1)
+	assert.Equal(t, 30.0, zones[0].Methods[0].Rates[0].UpTo)
+	db.Model(&entity.ShippingRate{}).Count(&rates)
+	assert.Equal(t, int64(1), rates)
+
+	assert.Nil(t, shippingDB.DeleteZone(zone.ID.String()))
+	assert.ErrorIs(t, shippingDB.DeleteZone(zone.ID.String()), gorm.ErrRecordNotFound)
+	db.Model(&entity.ShippingRate{}).Count(&rates)
+	assert.Zero(t, rates)
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+)
+
+type StockAlert struct {
+	DB *gorm.DB
+}
+
+func NewStockAlert(db *gorm.DB) *StockAlert {
+	return &StockAlert{
+		DB: db,
+	}
+}
+
+// FindLowStock returns the products with a reorder point whose stock, summed
+// over every warehouse, is below it.
+func (s *StockAlert) FindLowStock() ([]entity.LowStock, error) {
+	var low []entity.LowStock
+	err := s.DB.Model(&entity.Product{}).
+		Select("products.id AS product_id, products.name, products.reorder_point, COALESCE(SUM(stock_levels.quantity), 0) AS on_hand").
+		Joins("LEFT JOIN stock_levels ON stock_levels.product_id = products.id").
+		Where("products.reorder_point > 0").
+		Group("products.id").
+		Having("COALESCE(SUM(stock_levels.quantity), 0) < products.reorder_point").
+		Order("products.name").
+		Scan(&low).Error
+	return low, err
+}
+
+func (s *StockAlert) Create(alert *entity.StockAlert) error {
+	return s.DB.Create(alert).Error
+}
+
+func (s *StockAlert) FindByID(id string) (*entity.StockAlert, error) {
+	var alert entity.StockAlert
+	if err := s.DB.Where("id = ?", id).First(&alert).Error; err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (s *StockAlert) FindAll(status string) ([]entity.StockAlert, error) {
+	var alerts []entity.StockAlert
+	query := s.DB.Order("created_at desc")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&alerts).Error
+	return alerts, err
+}
+
+// FindUnresolved returns the open and acknowledged alerts.
+func (s *StockAlert) FindUnresolved() ([]entity.StockAlert, error) {
+	var alerts []entity.StockAlert
+	err := s.DB.Where("status <> ?", entity.AlertResolved).Order("created_at").Find(&alerts).Error
+	return alerts, err
+}
+
+// MarkDelivered records the channels the alert reached, and sets notifiedAt
+// once it reached all of them.
+func (s *StockAlert) MarkDelivered(id entityPkg.ID, channels entity.StringList, notifiedAt *time.Time) error {
+	return s.DB.Model(&entity.StockAlert{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"delivered_to": channels, "notified_at": notifiedAt}).Error
+}
+
+func (s *StockAlert) Acknowledge(id string, userID entityPkg.ID, now time.Time) (*entity.StockAlert, error) {
+	alert, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := alert.Acknowledge(userID, now); err != nil {
+		return nil, err
+	}
+
+	result := s.DB.Model(&entity.StockAlert{}).
+		Where("id = ? AND status = ?", id, entity.AlertOpen).
+		Updates(map[string]interface{}{"status": alert.Status, "acknowledged_at": now, "acknowledged_by": userID})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, entity.ErrAlertNotOpen
+	}
+	return alert, nil
+}
+
+func (s *StockAlert) Resolve(id entityPkg.ID, now time.Time) error {
+	result := s.DB.Model(&entity.StockAlert{}).
+		Where("id = ? AND status <> ?", id, entity.AlertResolved).
+		Updates(map[string]interface{}{"status": entity.AlertResolved, "resolved_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrAlertResolved
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestFindLowStock(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.StockLevel{}, &entity.StockAlert{})
+	alertDB := NewStockAlert(db)
+	low, _ := entity.NewProduct("Low", 10)
+	low.ReorderPoint = 5
+	stocked, _ := entity.NewProduct("Stocked", 10)
+	stocked.ReorderPoint = 5
+	untracked, _ := entity.NewProduct("Untracked", 10)
+	for _, product := range []*entity.Product{low, stocked, untracked} {
+		assert.Nil(t, db.Create(product).Error)
+	}
+	east, west := entityPkg.NewID(), entityPkg.NewID()
+	db.Create(&entity.StockLevel{ProductID: low.ID, WarehouseID: east, Quantity: 2})
+	db.Create(&entity.StockLevel{ProductID: low.ID, WarehouseID: west, Quantity: 2})
+	db.Create(&entity.StockLevel{ProductID: stocked.ID, WarehouseID: east, Quantity: 3})
+	db.Create(&entity.StockLevel{ProductID: stocked.ID, WarehouseID: west, Quantity: 2})
+
+	found, err := alertDB.FindLowStock()
+	assert.Nil(t, err)
+	assert.Len(t, found, 1)
+	assert.Equal(t, low.ID, found[0].ProductID)
+	assert.Equal(t, "Low", found[0].Name)
+	assert.Equal(t, 4, found[0].OnHand)
+}
+
+func TestAcknowledgeStockAlert(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.StockAlert{})
+	alertDB := NewStockAlert(db)
+	alert := entity.NewStockAlert(entity.LowStock{ProductID: entityPkg.NewID(), ReorderPoint: 5}, time.Now())
+	assert.Nil(t, alertDB.Create(alert))
+
+	acknowledged, err := alertDB.Acknowledge(alert.ID.String(), entityPkg.NewID(), time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.AlertAcknowledged, acknowledged.Status)
+	_, err = alertDB.Acknowledge(alert.ID.String(), entityPkg.NewID(), time.Now())
+	assert.Equal(t, entity.ErrAlertNotOpen, err)
+
+	unresolved, _ := alertDB.FindUnresolved()
+	assert.Len(t, unresolved, 1)
+	assert.Nil(t, alertDB.Resolve(alert.ID, time.Now()))
+	assert.Equal(t, entity.ErrAlertResolved, alertDB.Resolve(alert.ID, time.Now()))
+	unresolved, _ = alertDB.FindUnresolved()
+	assert.Empty(t, unresolved)
+
+	alerts, _ := alertDB.FindAll(entity.AlertResolved)
+	assert.Len(t, alerts, 1)
+}
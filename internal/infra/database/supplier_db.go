@@ -0,0 +1,106 @@
+package database
+
+import (
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Supplier struct {
+	DB *gorm.DB
+}
+
+func NewSupplier(db *gorm.DB) *Supplier {
+	return &Supplier{
+		DB: db,
+	}
+}
+
+func (s *Supplier) Create(supplier *entity.Supplier) error {
+	return s.DB.Create(supplier).Error
+}
+
+func (s *Supplier) FindAll() ([]entity.Supplier, error) {
+	var suppliers []entity.Supplier
+	err := s.DB.Order("name").Find(&suppliers).Error
+	return suppliers, err
+}
+
+func (s *Supplier) FindByID(id string) (*entity.Supplier, error) {
+	var supplier entity.Supplier
+	if err := s.DB.Where("id = ?", id).First(&supplier).Error; err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (s *Supplier) Update(supplier *entity.Supplier) error {
+	if _, err := s.FindByID(supplier.ID.String()); err != nil {
+		return err
+	}
+	return s.DB.Save(supplier).Error
+}
+
+// Delete removes the supplier and its product links. Purchase orders are
+// kept as they are part of the purchasing history.
+func (s *Supplier) Delete(id string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("supplier_id = ?", id).Delete(&entity.ProductSupplier{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.Supplier{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// SaveLink creates or replaces the terms of a supplier for a product. The
+// date of the last receipt is kept.
+func (s *Supplier) SaveLink(link *entity.ProductSupplier) error {
+	return s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "supplier_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"supplier_sku", "cost_price", "lead_time_days", "updated_at"}),
+	}).Create(link).Error
+}
+
+func (s *Supplier) DeleteLink(productID, supplierID string) error {
+	result := s.DB.Where("product_id = ? AND supplier_id = ?", productID, supplierID).Delete(&entity.ProductSupplier{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *Supplier) FindLinksByProductID(productID string) ([]entity.ProductSupplier, error) {
+	var links []entity.ProductSupplier
+	err := s.DB.Where("product_id = ?", productID).Order("cost_price").Find(&links).Error
+	return links, err
+}
+
+func (s *Supplier) FindLinksBySupplierID(supplierID string) ([]entity.ProductSupplier, error) {
+	var links []entity.ProductSupplier
+	err := s.DB.Where("supplier_id = ?", supplierID).Order("supplier_sku").Find(&links).Error
+	return links, err
+}
+
+// FindAllLinks returns the links of every product, keyed by product ID.
+func (s *Supplier) FindAllLinks() (map[string][]entity.ProductSupplier, error) {
+	var links []entity.ProductSupplier
+	if err := s.DB.Order("product_id, cost_price").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string][]entity.ProductSupplier)
+	for _, link := range links {
+		id := link.ProductID.String()
+		result[id] = append(result[id], link)
+	}
+	return result, nil
+}
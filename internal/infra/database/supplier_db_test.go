@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCreateSupplier(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Supplier{}, &entity.ProductSupplier{})
+	supplierDB := NewSupplier(db)
+	supplier, _ := entity.NewSupplier("Acme", "sales@acme.com", "")
+
+	err = supplierDB.Create(supplier)
+	assert.Nil(t, err)
+
+	duplicated, _ := entity.NewSupplier("Acme", "", "")
+	assert.NotNil(t, supplierDB.Create(duplicated))
+
+	found, err := supplierDB.FindByID(supplier.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "sales@acme.com", found.Email)
+}
+
+func TestSaveProductSupplierLink(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Supplier{}, &entity.ProductSupplier{})
+	supplierDB := NewSupplier(db)
+	supplier, _ := entity.NewSupplier("Acme", "", "")
+	assert.Nil(t, supplierDB.Create(supplier))
+	product, _ := entity.NewProduct("Product 1", 10)
+
+	link, _ := entity.NewProductSupplier(product.ID, supplier.ID, "ACME-1", 5, 7)
+	assert.Nil(t, supplierDB.SaveLink(link))
+	link, _ = entity.NewProductSupplier(product.ID, supplier.ID, "ACME-2", 6, 3)
+	assert.Nil(t, supplierDB.SaveLink(link))
+
+	links, err := supplierDB.FindLinksByProductID(product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "ACME-2", links[0].SupplierSKU)
+	assert.Equal(t, 3, links[0].LeadTimeDays)
+
+	assert.Nil(t, supplierDB.Delete(supplier.ID.String()))
+	links, err = supplierDB.FindLinksByProductID(product.ID.String())
+	assert.Nil(t, err)
+	assert.Empty(t, links)
+}
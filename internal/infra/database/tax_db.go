@@ -0,0 +1,218 @@
+package database
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// taxRateColumns is the header expected by ImportRatesCSV.
+var taxRateColumns = []string{"tax_class", "jurisdiction", "name", "rate", "effective_from", "effective_to"}
+
+type Tax struct {
+	DB *gorm.DB
+}
+
+func NewTax(db *gorm.DB) *Tax {
+	return &Tax{
+		DB: db,
+	}
+}
+
+// TaxImportError reports a line of a tax rates CSV that cannot be imported.
+type TaxImportError struct {
+	Line int
+	Err  error
+}
+
+func (e *TaxImportError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *TaxImportError) Unwrap() error {
+	return e.Err
+}
+
+// SaveClass stores the class, refusing a name another class already has.
+func (t *Tax) SaveClass(class *entity.TaxClass) error {
+	return t.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&entity.TaxClass{}).Where("name = ?", class.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return entity.ErrTaxClassTaken
+		}
+		return tx.Create(class).Error
+	})
+}
+
+func (t *Tax) FindClasses() ([]entity.TaxClass, error) {
+	var classes []entity.TaxClass
+	err := t.DB.Order("name").Find(&classes).Error
+	return classes, err
+}
+
+func (t *Tax) FindClassByID(id string) (*entity.TaxClass, error) {
+	var class entity.TaxClass
+	if err := t.DB.Where("id = ?", id).First(&class).Error; err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// SaveRate stores the rate, refusing one with the class, jurisdiction, name
+// and effective_from of a stored rate.
+func (t *Tax) SaveRate(rate *entity.TaxRate) error {
+	return t.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&entity.TaxRate{}).
+			Where("tax_class_id = ? AND jurisdiction = ? AND name = ? AND effective_from = ?", rate.TaxClassID, rate.Jurisdiction, rate.Name, rate.EffectiveFrom).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return entity.ErrTaxRateTaken
+		}
+		return tx.Create(rate).Error
+	})
+}
+
+// FindRates lists rates, optionally restricted to a jurisdiction and a class.
+func (t *Tax) FindRates(jurisdiction, classID string) ([]entity.TaxRate, error) {
+	var rates []entity.TaxRate
+	query := t.DB.Order("jurisdiction, name, effective_from")
+	if jurisdiction != "" {
+		query = query.Where("jurisdiction = ?", entity.NormalizeJurisdiction(jurisdiction))
+	}
+	if classID != "" {
+		query = query.Where("tax_class_id = ?", classID)
+	}
+	err := query.Find(&rates).Error
+	return rates, err
+}
+
+// RemoveDuplicateRates keeps only the first of the rates sharing a class,
+// jurisdiction, name and effective_from, left behind by imports that ran
+// before those columns were unique. It must run before the migration adds the
+// unique index.
+func (t *Tax) RemoveDuplicateRates() error {
+	if !t.DB.Migrator().HasTable(&entity.TaxRate{}) {
+		return nil
+	}
+	return t.DB.Exec(`DELETE FROM tax_rates WHERE rowid NOT IN (
+		SELECT MIN(rowid) FROM tax_rates GROUP BY tax_class_id, jurisdiction, name, effective_from)`).Error
+}
+
+// ImportRatesCSV loads rates from CSV with the columns tax_class,
+// jurisdiction, name, rate, effective_from and effective_to (dates as
+// YYYY-MM-DD, effective_to may be empty). Unknown tax classes are created.
+// A row with the class, jurisdiction, name and effective_from of a stored rate
+// updates that rate, so importing the same file again changes nothing.
+// Nothing is imported if any row is invalid; the line at fault is then
+// reported with a TaxImportError.
+func (t *Tax) ImportRatesCSV(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return 0, &TaxImportError{Line: 1, Err: err}
+	}
+	for i, column := range taxRateColumns {
+		if i >= len(header) || strings.TrimSpace(strings.ToLower(header[i])) != column {
+			return 0, &TaxImportError{Line: 1, Err: fmt.Errorf("invalid header: expected %s", strings.Join(taxRateColumns, ","))}
+		}
+	}
+
+	imported := 0
+	err = t.DB.Transaction(func(tx *gorm.DB) error {
+		classes := map[string]*entity.TaxClass{}
+		for line := 2; ; line++ {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return &TaxImportError{Line: line, Err: err}
+			}
+			if strings.TrimSpace(record[0]) == "" {
+				return &TaxImportError{Line: line, Err: entity.ErrNameRequired}
+			}
+
+			class, err := findOrCreateClass(tx, classes, record[0])
+			if err != nil {
+				return err
+			}
+			rate, err := parseTaxRate(class, record)
+			if err != nil {
+				return &TaxImportError{Line: line, Err: err}
+			}
+			upsert := clause.OnConflict{
+				Columns:   []clause.Column{{Name: "tax_class_id"}, {Name: "jurisdiction"}, {Name: "name"}, {Name: "effective_from"}},
+				DoUpdates: clause.AssignmentColumns([]string{"rate", "effective_to"}),
+			}
+			if err := tx.Clauses(upsert).Create(rate).Error; err != nil {
+				return err
+			}
+			imported++
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
+
+func findOrCreateClass(tx *gorm.DB, cache map[string]*entity.TaxClass, name string) (*entity.TaxClass, error) {
+	name = strings.TrimSpace(name)
+	if class, ok := cache[name]; ok {
+		return class, nil
+	}
+
+	var class entity.TaxClass
+	err := tx.Where("name = ?", name).First(&class).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		created, err := entity.NewTaxClass(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(created).Error; err != nil {
+			return nil, err
+		}
+		class = *created
+	} else if err != nil {
+		return nil, err
+	}
+
+	cache[name] = &class
+	return &class, nil
+}
+
+func parseTaxRate(class *entity.TaxClass, record []string) (*entity.TaxRate, error) {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+	if err != nil {
+		return nil, entity.ErrTaxRateInvalid
+	}
+	from, err := time.Parse(time.DateOnly, strings.TrimSpace(record[4]))
+	if err != nil {
+		return nil, err
+	}
+	var to *time.Time
+	if value := strings.TrimSpace(record[5]); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, err
+		}
+		to = &parsed
+	}
+	return entity.NewTaxRate(class.ID, record[1], record[2], rate, from, to)
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSaveTaxRate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.TaxClass{}, &entity.TaxRate{})
+	taxDB := NewTax(db)
+
+	class, _ := entity.NewTaxClass("standard")
+	assert.Nil(t, taxDB.SaveClass(class))
+	rate, _ := entity.NewTaxRate(class.ID, "SP", "ICMS", 18, time.Now(), nil)
+	assert.Nil(t, taxDB.SaveRate(rate))
+
+	rates, err := taxDB.FindRates("sp", class.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, rates, 1)
+	assert.Equal(t, "ICMS", rates[0].Name)
+
+	duplicateClass, _ := entity.NewTaxClass("standard")
+	assert.Equal(t, entity.ErrTaxClassTaken, taxDB.SaveClass(duplicateClass))
+	duplicateRate, _ := entity.NewTaxRate(class.ID, "sp", "ICMS", 17, rate.EffectiveFrom, nil)
+	assert.Equal(t, entity.ErrTaxRateTaken, taxDB.SaveRate(duplicateRate))
+}
+
+func TestImportTaxRatesCSV(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.TaxClass{}, &entity.TaxRate{})
+	taxDB := NewTax(db)
+
+	csv := `tax_class,jurisdiction,name,rate,effective_from,effective_to
+standard,SP,ICMS,18,2024-01-01,
+standard,RJ,ICMS,20,2024-01-01,
+food,SP,ICMS,7,2024-01-01,2025-01-01
+`
+	imported, err := taxDB.ImportRatesCSV(strings.NewReader(csv))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, imported)
+
+	classes, err := taxDB.FindClasses()
+	assert.Nil(t, err)
+	assert.Len(t, classes, 2)
+
+	rates, err := taxDB.FindRates("SP", "")
+	assert.Nil(t, err)
+	assert.Len(t, rates, 2)
+}
+
+func TestImportTaxRatesCSVIsAllOrNothing(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.TaxClass{}, &entity.TaxRate{})
+	taxDB := NewTax(db)
+
+	csv := `tax_class,jurisdiction,name,rate,effective_from,effective_to
+standard,SP,ICMS,18,2024-01-01,
+standard,RJ,ICMS,abc,2024-01-01,
+`
+	_, err = taxDB.ImportRatesCSV(strings.NewReader(csv))
+	var importErr *TaxImportError
+	assert.ErrorAs(t, err, &importErr)
+	assert.Equal(t, 3, importErr.Line)
+
+	rates, err := taxDB.FindRates("", "")
+	assert.Nil(t, err)
+	assert.Len(t, rates, 0)
+}
+
+func TestImportTaxRatesCSVTwiceKeepsTheTax(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.TaxClass{}, &entity.TaxRate{})
+	taxDB := NewTax(db)
+
+	csv := `tax_class,jurisdiction,name,rate,effective_from,effective_to
+standard,SP,ICMS,18,2024-01-01,
+standard,SP,FCP,2,2024-01-01,
+`
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	_, err = taxDB.ImportRatesCSV(strings.NewReader(csv))
+	assert.Nil(t, err)
+	rates, err := taxDB.FindRates("SP", "")
+	assert.Nil(t, err)
+	first := entity.CalculateTax(100, false, "SP", rates, at)
+	assert.Equal(t, 20.0, first.Tax)
+
+	_, err = taxDB.ImportRatesCSV(strings.NewReader(csv))
+	assert.Nil(t, err)
+	rates, err = taxDB.FindRates("SP", "")
+	assert.Nil(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, first, entity.CalculateTax(100, false, "SP", rates, at))
+}
+
+func TestImportTaxRatesCSVUpdatesExistingRate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.TaxClass{}, &entity.TaxRate{})
+	taxDB := NewTax(db)
+
+	_, err = taxDB.ImportRatesCSV(strings.NewReader("tax_class,jurisdiction,name,rate,effective_from,effective_to\nstandard,SP,ICMS,18,2024-01-01,\n"))
+	assert.Nil(t, err)
+	_, err = taxDB.ImportRatesCSV(strings.NewReader("tax_class,jurisdiction,name,rate,effective_from,effective_to\nstandard,SP,ICMS,17,2024-01-01,2025-01-01\n"))
+	assert.Nil(t, err)
+
+	rates, err := taxDB.FindRates("SP", "")
+	assert.Nil(t, err)
+	assert.Len(t, rates, 1)
+	assert.Equal(t, 17.0, rates[0].Rate)
+	assert.NotNil(t, rates[0].EffectiveTo)
+}
+
+func TestImportTaxRatesCSVStorageError(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.TaxClass{})
+	taxDB := NewTax(db)
+
+	csv := `tax_class,jurisdiction,name,rate,effective_from,effective_to
+standard,SP,ICMS,18,2024-01-01,
+`
+	_, err = taxDB.ImportRatesCSV(strings.NewReader(csv))
+	assert.NotNil(t, err)
+	var importErr *TaxImportError
+	assert.False(t, errors.As(err, &importErr))
+}
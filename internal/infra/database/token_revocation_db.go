@@ -0,0 +1,57 @@
+package database
+
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRevocation struct {
+	DB *gorm.DB
+}
+
+func NewTokenRevocation(db *gorm.DB) *TokenRevocation {
+	return &TokenRevocation{
+		DB: db,
+	}
+}
+
+// RevokeToken denies an access token. Revoking it again changes nothing.
+func (t *TokenRevocation) RevokeToken(token *entity.RevokedToken) error {
+	return t.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// RevokeSessions denies the access tokens issued to the user until now,
+// replacing the previous revocation of the user.
+func (t *TokenRevocation) RevokeSessions(revocation *entity.SessionRevocation) error {
+	return t.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(revocation).Error
+}
+
+// FindRevocations returns the revocations not expired at now.
+func (t *TokenRevocation) FindRevocations(now time.Time) ([]entity.RevokedToken, []entity.SessionRevocation, error) {
+	var tokens []entity.RevokedToken
+	if err := t.DB.Where("expires_at > ?", now).Find(&tokens).Error; err != nil {
+		return nil, nil, err
+	}
+	var sessions []entity.SessionRevocation
+	if err := t.DB.Where("expires_at > ?", now).Find(&sessions).Error; err != nil {
+		return nil, nil, err
+	}
+	return tokens, sessions, nil
+}
+
+// DeleteExpired deletes the revocations expired at now, which no token they
+// deny could pass anyway, and returns how many were deleted.
+func (t *TokenRevocation) DeleteExpired(now time.Time) (int64, error) {
+	tokens := t.DB.Where("expires_at <= ?", now).Delete(&entity.RevokedToken{})
+	if tokens.Error != nil {
+		return 0, tokens.Error
+	}
+	sessions := t.DB.Where("expires_at <= ?", now).Delete(&entity.SessionRevocation{})
+	if sessions.Error != nil {
+		return 0, sessions.Error
+	}
+	return tokens.RowsAffected + sessions.RowsAffected, nil
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTokenRevocations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.RevokedToken{}, &entity.SessionRevocation{})
+	revocationDB := NewTokenRevocation(db)
+	userID := entityPkg.NewID()
+	now := time.Now()
+
+	token := &entity.RevokedToken{JTI: "jti-1", UserID: userID, ExpiresAt: now.Add(time.Minute), CreatedAt: now}
+	assert.Nil(t, revocationDB.RevokeToken(token))
+	assert.Nil(t, revocationDB.RevokeToken(token))
+	assert.Nil(t, revocationDB.RevokeToken(&entity.RevokedToken{JTI: "jti-2", UserID: userID, ExpiresAt: now.Add(-time.Minute)}))
+	assert.Nil(t, revocationDB.RevokeSessions(entity.NewSessionRevocation(userID, time.Minute, now.Add(-time.Hour))))
+	assert.Nil(t, revocationDB.RevokeSessions(entity.NewSessionRevocation(userID, time.Minute, now)))
+
+	tokens, sessions, err := revocationDB.FindRevocations(now)
+	assert.Nil(t, err)
+	assert.Len(t, tokens, 1)
+	assert.Equal(t, "jti-1", tokens[0].JTI)
+	assert.Len(t, sessions, 1)
+	assert.WithinDuration(t, now, sessions[0].RevokedAt, time.Millisecond)
+
+	deleted, err := revocationDB.DeleteExpired(now.Add(30 * time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+	deleted, err = revocationDB.DeleteExpired(now.Add(2 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), deleted)
+}
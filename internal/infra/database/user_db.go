@@ -1,7 +1,10 @@
 package database
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/bhyago/crud-products-go/internal/entity"
 	"gorm.io/gorm"
@@ -17,20 +20,131 @@ func NewUser(db *gorm.DB) *User {
 	}
 }
 
+// CheckDuplicateEmails fails when users share an email in different cases,
+// which was allowed before emails were unique regardless of case. Such
+// accounts must be merged by hand: the migration could not add the unique
+// index. It must run before the migration.
+func (u *User) CheckDuplicateEmails() error {
+	if !u.DB.Migrator().HasTable(&entity.User{}) {
+		return nil
+	}
+	var emails []string
+	err := u.DB.Model(&entity.User{}).
+		Select("LOWER(email)").
+		Group("LOWER(email)").
+		Having("COUNT(*) > 1").
+		Order("LOWER(email)").
+		Pluck("LOWER(email)", &emails).Error
+	if err != nil {
+		return err
+	}
+	if len(emails) > 0 {
+		return fmt.Errorf("%w by several users, regardless of case: %s", entity.ErrEmailTaken, strings.Join(emails, ", "))
+	}
+	return nil
+}
+
+// FindByEmail finds the user by email, ignoring case.
 func (u *User) FindByEmail(email string) (*entity.User, error) {
 	var user entity.User
-	err := u.DB.Where("email = ?", email).First(&user).Error
+	err := u.DB.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (u *User) FindByID(id string) (*entity.User, error) {
+	var user entity.User
+	err := u.DB.Where("id = ?", id).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// Save stores the user. An email already used by another user, even in a
+// different case, is refused.
 func (u *User) Save(user *entity.User) error {
 	log.Println(user)
-	err := u.DB.Save(user).Error
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&entity.User{}).
+			Where("LOWER(email) = LOWER(?) AND id <> ?", user.Email, user.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return entity.ErrEmailTaken
+		}
+		return tx.Save(user).Error
+	})
+}
+
+func (u *User) FindAll() ([]entity.User, error) {
+	var users []entity.User
+	err := u.DB.Order("email").Find(&users).Error
+	return users, err
+}
+
+// UpdateRole assigns the role to the user. The last admin cannot be demoted,
+// lest no one can assign roles anymore.
+func (u *User) UpdateRole(id, role string) (*entity.User, error) {
+	var user entity.User
+	err := u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&user).Error; err != nil {
+			return err
+		}
+		if user.Role == entity.RoleAdmin && role != entity.RoleAdmin {
+			var admins int64
+			if err := tx.Model(&entity.User{}).Where("role = ?", entity.RoleAdmin).Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins <= 1 {
+				return entity.ErrLastAdmin
+			}
+		}
+		user.Role = role
+		return tx.Model(&user).Update("role", role).Error
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return &user, nil
+}
+
+// PromoteFirstAdmin makes the user with the email an admin when there is no
+// admin yet, and reports whether it did. It is meant to run at startup, for
+// an account that already exists: signing up with the email later grants
+// nothing.
+func (u *User) PromoteFirstAdmin(email string) (bool, error) {
+	if strings.TrimSpace(email) == "" {
+		return false, nil
+	}
+	promoted := false
+	err := u.DB.Transaction(func(tx *gorm.DB) error {
+		var admins int64
+		if err := tx.Model(&entity.User{}).Where("role = ?", entity.RoleAdmin).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return nil
+		}
+
+		var user entity.User
+		err := tx.Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.User{}).Where("id = ?", user.ID).Update("role", entity.RoleAdmin).Error; err != nil {
+			return err
+		}
+		promoted = true
+		return nil
+	})
+	return promoted, err
 }
@@ -53,3 +53,105 @@ func TestFindByEmail(t *testing.T) {
 	assert.Equal(t, user.Name, userFound.Name)
 	assert.Equal(t, user.Email, userFound.Email)
 }
+
+func TestFindUserByID(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.User{})
+	user, err := entity.NewUser("John", "123456", "j@j.com")
+	if err != nil {
+		t.Error(err)
+	}
+	userDB := NewUser(db)
+	assert.Nil(t, userDB.Save(user))
+
+	userFound, err := userDB.FindByID(user.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, user.Email, userFound.Email)
+
+	_, err = userDB.FindByID("unknown")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestUserRoles(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.User{})
+	userDB := NewUser(db)
+	alice, _ := entity.NewUser("Alice", "123456", "alice@example.com")
+	bob, _ := entity.NewUser("Bob", "123456", "bob@example.com")
+	assert.Nil(t, userDB.Save(alice))
+	assert.Nil(t, userDB.Save(bob))
+
+	promoted, err := userDB.PromoteFirstAdmin("carol@example.com")
+	assert.Nil(t, err)
+	assert.False(t, promoted)
+	promoted, err = userDB.PromoteFirstAdmin("Alice@Example.com")
+	assert.Nil(t, err)
+	assert.True(t, promoted)
+	// There is an admin now: the bootstrap does nothing anymore.
+	promoted, err = userDB.PromoteFirstAdmin("bob@example.com")
+	assert.Nil(t, err)
+	assert.False(t, promoted)
+
+	_, err = userDB.UpdateRole(alice.ID.String(), entity.RoleEditor)
+	assert.Equal(t, entity.ErrLastAdmin, err)
+	user, err := userDB.UpdateRole(bob.ID.String(), entity.RoleAdmin)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.RoleAdmin, user.Role)
+	_, err = userDB.UpdateRole(alice.ID.String(), entity.RoleEditor)
+	assert.Nil(t, err)
+
+	users, err := userDB.FindAll()
+	assert.Nil(t, err)
+	assert.Equal(t, entity.RoleEditor, users[0].Role)
+	assert.Equal(t, entity.RoleAdmin, users[1].Role)
+}
+
+func TestSaveUserWithTakenEmail(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.User{})
+	userDB := NewUser(db)
+	alice, _ := entity.NewUser("Alice", "123456", "alice@example.com")
+	assert.Nil(t, userDB.Save(alice))
+
+	other, _ := entity.NewUser("Alice", "654321", "Alice@Example.com")
+	assert.Equal(t, entity.ErrEmailTaken, userDB.Save(other))
+	// The index refuses it as well, whatever the path of the write.
+	assert.NotNil(t, db.Create(other).Error)
+
+	alice.Name = "Alice Smith"
+	assert.Nil(t, userDB.Save(alice))
+	found, err := userDB.FindByEmail("ALICE@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, alice.ID, found.ID)
+}
+
+func TestCheckDuplicateEmails(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	userDB := NewUser(db)
+	assert.Nil(t, userDB.CheckDuplicateEmails())
+
+	// A database from before emails were unique regardless of case.
+	db.AutoMigrate(&entity.User{})
+	assert.Nil(t, db.Migrator().DropIndex(&entity.User{}, "idx_users_email"))
+	alice, _ := entity.NewUser("Alice", "123456", "alice@example.com")
+	other, _ := entity.NewUser("Alice", "654321", "Alice@Example.com")
+	assert.Nil(t, db.Create(alice).Error)
+	assert.Nil(t, userDB.CheckDuplicateEmails())
+	assert.Nil(t, db.Create(other).Error)
+
+	err = userDB.CheckDuplicateEmails()
+	assert.ErrorIs(t, err, entity.ErrEmailTaken)
+	assert.Contains(t, err.Error(), "alice@example.com")
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferFilter struct {
+	ProductID   string
+	WarehouseID string
+	Status      string
+}
+
+type Warehouse struct {
+	DB *gorm.DB
+}
+
+func NewWarehouse(db *gorm.DB) *Warehouse {
+	return &Warehouse{
+		DB: db,
+	}
+}
+
+func (w *Warehouse) Create(warehouse *entity.Warehouse) error {
+	return w.DB.Create(warehouse).Error
+}
+
+func (w *Warehouse) FindAll() ([]entity.Warehouse, error) {
+	var warehouses []entity.Warehouse
+	err := w.DB.Order("code").Find(&warehouses).Error
+	return warehouses, err
+}
+
+func (w *Warehouse) FindByID(id string) (*entity.Warehouse, error) {
+	var warehouse entity.Warehouse
+	if err := w.DB.Where("id = ?", id).First(&warehouse).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (w *Warehouse) Update(warehouse *entity.Warehouse) error {
+	if _, err := w.FindByID(warehouse.ID.String()); err != nil {
+		return err
+	}
+	return w.DB.Save(warehouse).Error
+}
+
+// Delete removes a warehouse that holds no stock and has no transfer in
+// transit from or to it.
+func (w *Warehouse) Delete(id string) error {
+	return w.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entity.Warehouse{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var held int64
+		err := tx.Model(&entity.StockLevel{}).Where("warehouse_id = ? AND quantity > 0", id).Count(&held).Error
+		if err != nil {
+			return err
+		}
+		var inTransit int64
+		err = tx.Model(&entity.StockTransfer{}).
+			Where("status = ? AND (from_warehouse_id = ? OR to_warehouse_id = ?)", entity.TransferInTransit, id, id).
+			Count(&inTransit).Error
+		if err != nil {
+			return err
+		}
+		if held > 0 || inTransit > 0 {
+			return entity.ErrWarehouseNotEmpty
+		}
+		return tx.Where("warehouse_id = ?", id).Delete(&entity.StockLevel{}).Error
+	})
+}
+
+func (w *Warehouse) FindStockByWarehouseID(warehouseID string) ([]entity.StockLevel, error) {
+	var levels []entity.StockLevel
+	err := w.DB.Where("warehouse_id = ?", warehouseID).Order("product_id").Find(&levels).Error
+	return levels, err
+}
+
+func (w *Warehouse) FindStockByProductID(productID string) ([]entity.StockLevel, error) {
+	var levels []entity.StockLevel
+	err := w.DB.Where("product_id = ?", productID).Find(&levels).Error
+	return levels, err
+}
+
+// AdjustStock adds delta, which may be negative, to the stock of the product
+// in the warehouse. It returns entity.ErrInsufficientStock rather than let
+// the stock go negative.
+func (w *Warehouse) AdjustStock(productID, warehouseID entityPkg.ID, delta int, now time.Time) (*entity.StockLevel, error) {
+	if delta == 0 {
+		return nil, entity.ErrStockDeltaInvalid
+	}
+
+	var level entity.StockLevel
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		if err := moveStock(tx, productID, warehouseID, delta, now); err != nil {
+			return err
+		}
+		return tx.Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).First(&level).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// CreateTransfer takes the quantity out of the source warehouse and records
+// the transfer as in transit.
+func (w *Warehouse) CreateTransfer(transfer *entity.StockTransfer) error {
+	return w.DB.Transaction(func(tx *gorm.DB) error {
+		if err := moveStock(tx, transfer.ProductID, transfer.FromWarehouseID, -transfer.Quantity, transfer.CreatedAt); err != nil {
+			return err
+		}
+		return tx.Create(transfer).Error
+	})
+}
+
+// ReceiveTransfer adds the quantity of an in transit transfer to the
+// destination warehouse.
+func (w *Warehouse) ReceiveTransfer(id string, now time.Time) (*entity.StockTransfer, error) {
+	return w.closeTransfer(id, entity.TransferReceived, now)
+}
+
+// CancelTransfer returns the quantity of an in transit transfer to the source
+// warehouse.
+func (w *Warehouse) CancelTransfer(id string, now time.Time) (*entity.StockTransfer, error) {
+	return w.closeTransfer(id, entity.TransferCancelled, now)
+}
+
+func (w *Warehouse) FindTransferByID(id string) (*entity.StockTransfer, error) {
+	var transfer entity.StockTransfer
+	if err := w.DB.Where("id = ?", id).First(&transfer).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (w *Warehouse) FindTransfers(filter TransferFilter) ([]entity.StockTransfer, error) {
+	var transfers []entity.StockTransfer
+	query := w.DB.Order("created_at desc")
+	if filter.ProductID != "" {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.WarehouseID != "" {
+		query = query.Where("from_warehouse_id = ? OR to_warehouse_id = ?", filter.WarehouseID, filter.WarehouseID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	err := query.Find(&transfers).Error
+	return transfers, err
+}
+
+// closeTransfer moves the transfer out of in_transit before touching any
+// stock, so that a transfer racing with itself is only applied once.
+func (w *Warehouse) closeTransfer(id, status string, now time.Time) (*entity.StockTransfer, error) {
+	var transfer entity.StockTransfer
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		values := map[string]interface{}{"status": status}
+		if status == entity.TransferReceived {
+			values["received_at"] = now
+		} else {
+			values["cancelled_at"] = now
+		}
+		result := tx.Model(&entity.StockTransfer{}).
+			Where("id = ? AND status = ?", id, entity.TransferInTransit).
+			Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := tx.Where("id = ?", id).First(&transfer).Error; err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrTransferNotInTransit
+		}
+
+		warehouseID := transfer.ToWarehouseID
+		if status == entity.TransferCancelled {
+			warehouseID = transfer.FromWarehouseID
+		}
+		return moveStock(tx, transfer.ProductID, warehouseID, transfer.Quantity, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// moveStock changes a stock level in a single statement. Removing stock only
+// matches a row holding enough of it, which keeps concurrent requests from
+// taking the same units twice.
+func moveStock(tx *gorm.DB, productID, warehouseID entityPkg.ID, delta int, now time.Time) error {
+	if delta > 0 {
+		level := entity.StockLevel{ProductID: productID, WarehouseID: warehouseID, Quantity: delta, UpdatedAt: now}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "product_id"}, {Name: "warehouse_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"quantity":   gorm.Expr("quantity + ?", delta),
+				"updated_at": now,
+			}),
+		}).Create(&level).Error
+	}
+
+	result := tx.Model(&entity.StockLevel{}).
+		Where("product_id = ? AND warehouse_id = ? AND quantity >= ?", productID, warehouseID, -delta).
+		UpdateColumns(map[string]interface{}{"quantity": gorm.Expr("quantity + ?", delta), "updated_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrInsufficientStock
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAdjustStock(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Warehouse{}, &entity.StockLevel{}, &entity.StockTransfer{})
+	warehouseDB := NewWarehouse(db)
+	warehouse, _ := entity.NewWarehouse("EAST", "East", "")
+	assert.Nil(t, warehouseDB.Create(warehouse))
+	productID := entityPkg.NewID()
+
+	_, err = warehouseDB.AdjustStock(productID, warehouse.ID, -1, time.Now())
+	assert.Equal(t, entity.ErrInsufficientStock, err)
+
+	level, err := warehouseDB.AdjustStock(productID, warehouse.ID, 5, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 5, level.Quantity)
+	level, err = warehouseDB.AdjustStock(productID, warehouse.ID, -2, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, level.Quantity)
+	_, err = warehouseDB.AdjustStock(productID, warehouse.ID, -4, time.Now())
+	assert.Equal(t, entity.ErrInsufficientStock, err)
+
+	assert.Equal(t, entity.ErrWarehouseNotEmpty, warehouseDB.Delete(warehouse.ID.String()))
+}
+
+func TestStockTransfer(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Warehouse{}, &entity.StockLevel{}, &entity.StockTransfer{})
+	warehouseDB := NewWarehouse(db)
+	east, _ := entity.NewWarehouse("EAST", "East", "")
+	west, _ := entity.NewWarehouse("WEST", "West", "")
+	assert.Nil(t, warehouseDB.Create(east))
+	assert.Nil(t, warehouseDB.Create(west))
+	productID := entityPkg.NewID()
+	_, err = warehouseDB.AdjustStock(productID, east.ID, 5, time.Now())
+	assert.Nil(t, err)
+
+	transfer, _ := entity.NewStockTransfer(productID, east.ID, west.ID, 6)
+	assert.Equal(t, entity.ErrInsufficientStock, warehouseDB.CreateTransfer(transfer))
+
+	transfer, _ = entity.NewStockTransfer(productID, east.ID, west.ID, 3)
+	assert.Nil(t, warehouseDB.CreateTransfer(transfer))
+	levels, _ := warehouseDB.FindStockByProductID(productID.String())
+	assert.Len(t, levels, 1)
+	assert.Equal(t, 2, levels[0].Quantity)
+
+	received, err := warehouseDB.ReceiveTransfer(transfer.ID.String(), time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.TransferReceived, received.Status)
+	_, err = warehouseDB.ReceiveTransfer(transfer.ID.String(), time.Now())
+	assert.Equal(t, entity.ErrTransferNotInTransit, err)
+	_, err = warehouseDB.CancelTransfer(transfer.ID.String(), time.Now())
+	assert.Equal(t, entity.ErrTransferNotInTransit, err)
+
+	levels, _ = warehouseDB.FindStockByWarehouseID(west.ID.String())
+	assert.Equal(t, 3, levels[0].Quantity)
+
+	transfer, _ = entity.NewStockTransfer(productID, east.ID, west.ID, 2)
+	assert.Nil(t, warehouseDB.CreateTransfer(transfer))
+	_, err = warehouseDB.CancelTransfer(transfer.ID.String(), time.Now())
+	assert.Nil(t, err)
+	levels, _ = warehouseDB.FindStockByWarehouseID(east.ID.String())
+	assert.Equal(t, 2, levels[0].Quantity)
+
+	transfers, err := warehouseDB.FindTransfers(TransferFilter{WarehouseID: west.ID.String(), Status: entity.TransferCancelled})
+	assert.Nil(t, err)
+	assert.Len(t, transfers, 1)
+}
+
+func TestStockNeverGoesNegativeConcurrently(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "stock.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Warehouse{}, &entity.StockLevel{}, &entity.StockTransfer{})
+	warehouseDB := NewWarehouse(db)
+	east, _ := entity.NewWarehouse("EAST", "East", "")
+	west, _ := entity.NewWarehouse("WEST", "West", "")
+	assert.Nil(t, warehouseDB.Create(east))
+	assert.Nil(t, warehouseDB.Create(west))
+	productID := entityPkg.NewID()
+	_, err = warehouseDB.AdjustStock(productID, east.ID, 5, time.Now())
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = warehouseDB.AdjustStock(productID, east.ID, -1, time.Now())
+			} else {
+				transfer, _ := entity.NewStockTransfer(productID, east.ID, west.ID, 1)
+				err = warehouseDB.CreateTransfer(transfer)
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+				return
+			}
+			assert.Equal(t, entity.ErrInsufficientStock, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, succeeded)
+	levels, _ := warehouseDB.FindStockByWarehouseID(east.ID.String())
+	assert.Equal(t, 0, levels[0].Quantity)
+}
+
+func TestDeleteProductWithStock(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{}, &entity.StockLevel{}, &entity.StockTransfer{}, &entity.Warehouse{})
+	productDB := NewProduct(db)
+	warehouseDB := NewWarehouse(db)
+	warehouse, _ := entity.NewWarehouse("EAST", "East", "")
+	assert.Nil(t, warehouseDB.Create(warehouse))
+	product, _ := entity.NewProduct("Product 1", 10)
+	assert.Nil(t, productDB.Save(product))
+
+	_, err = warehouseDB.AdjustStock(product.ID, warehouse.ID, 3, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ErrProductHasStock, productDB.Delete(product.ID.String()))
+
+	_, err = warehouseDB.AdjustStock(product.ID, warehouse.ID, -3, time.Now())
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Delete(product.ID.String()))
+
+	levels, err := warehouseDB.FindStockByProductID(product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, levels, 0)
+	assert.Nil(t, warehouseDB.Delete(warehouse.ID.String()))
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"gorm.io/gorm"
+)
+
+type Wishlist struct {
+	DB *gorm.DB
+}
+
+func NewWishlist(db *gorm.DB) *Wishlist {
+	return &Wishlist{
+		DB: db,
+	}
+}
+
+func (w *Wishlist) FindByID(id string) (*entity.Wishlist, error) {
+	var wishlist entity.Wishlist
+	if err := w.withItems().Where("id = ?", id).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (w *Wishlist) FindByUserID(userID string) ([]entity.Wishlist, error) {
+	var wishlists []entity.Wishlist
+	err := w.withItems().Where("user_id = ?", userID).Order("created_at").Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (w *Wishlist) FindByShareToken(token string) (*entity.Wishlist, error) {
+	var wishlist entity.Wishlist
+	if err := w.withItems().Where("share_token = ?", token).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// Save stores the wishlist and replaces its items.
+func (w *Wishlist) Save(wishlist *entity.Wishlist) error {
+	return w.DB.Transaction(func(tx *gorm.DB) error {
+		wishlist.UpdatedAt = time.Now()
+		if err := tx.Omit("Items").Save(wishlist).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", wishlist.ID).Delete(&entity.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		if len(wishlist.Items) == 0 {
+			return nil
+		}
+		return tx.Create(&wishlist.Items).Error
+	})
+}
+
+func (w *Wishlist) Delete(id string) error {
+	return w.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", id).Delete(&entity.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.Wishlist{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (w *Wishlist) withItems() *gorm.DB {
+	return w.DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("added_at, product_id")
+	})
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSaveWishlist(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Wishlist{}, &entity.WishlistItem{})
+	wishlistDB := NewWishlist(db)
+	userID := entityPkg.NewID()
+	product, _ := entity.NewProduct("Product 1", 10)
+	wishlist, _ := entity.NewWishlist(userID, "Birthday")
+	assert.Nil(t, wishlist.AddItem(product, "size M"))
+
+	err = wishlistDB.Save(wishlist)
+	assert.Nil(t, err)
+	other, _ := entity.NewWishlist(userID, "Christmas")
+	assert.Nil(t, wishlistDB.Save(other))
+
+	wishlists, err := wishlistDB.FindByUserID(userID.String())
+	assert.Nil(t, err)
+	assert.Len(t, wishlists, 2)
+	assert.Len(t, wishlists[0].Items, 1)
+	assert.Equal(t, "size M", wishlists[0].Items[0].Note)
+	assert.Empty(t, wishlists[1].Items)
+}
+
+func TestFindWishlistByShareToken(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Wishlist{}, &entity.WishlistItem{})
+	wishlistDB := NewWishlist(db)
+	shared, _ := entity.NewWishlist(entityPkg.NewID(), "Birthday")
+	assert.Nil(t, shared.Share())
+	assert.Nil(t, wishlistDB.Save(shared))
+	private, _ := entity.NewWishlist(entityPkg.NewID(), "Secret")
+	assert.Nil(t, wishlistDB.Save(private))
+
+	found, err := wishlistDB.FindByShareToken(*shared.ShareToken)
+	assert.Nil(t, err)
+	assert.Equal(t, shared.ID, found.ID)
+
+	found.Unshare()
+	assert.Nil(t, wishlistDB.Save(found))
+	_, err = wishlistDB.FindByShareToken(*shared.ShareToken)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestDeleteWishlist(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Wishlist{}, &entity.WishlistItem{})
+	wishlistDB := NewWishlist(db)
+	product, _ := entity.NewProduct("Product 1", 10)
+	wishlist, _ := entity.NewWishlist(entityPkg.NewID(), "Birthday")
+	assert.Nil(t, wishlist.AddItem(product, ""))
+	assert.Nil(t, wishlistDB.Save(wishlist))
+
+	assert.Nil(t, wishlistDB.Delete(wishlist.ID.String()))
+	assert.Equal(t, gorm.ErrRecordNotFound, wishlistDB.Delete(wishlist.ID.String()))
+
+	var items int64
+	db.Model(&entity.WishlistItem{}).Count(&items)
+	assert.Equal(t, int64(0), items)
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+)
+
+// CartExpiry periodically deletes the carts left untouched for longer than TTL.
+type CartExpiry struct {
+	CartDB   database.CartInterface
+	TTL      time.Duration
+	Interval time.Duration
+	Now      func() time.Time
+}
+
+func NewCartExpiry(cartDB database.CartInterface, ttl, interval time.Duration) *CartExpiry {
+	return &CartExpiry{
+		CartDB:   cartDB,
+		TTL:      ttl,
+		Interval: interval,
+		Now:      time.Now,
+	}
+}
+
+// RunOnce deletes the stale carts and returns how many were deleted.
+func (j *CartExpiry) RunOnce() (int64, error) {
+	return j.CartDB.DeleteStale(j.Now().Add(-j.TTL))
+}
+
+// Run sweeps the carts every Interval until ctx is done. It does nothing when
+// TTL or Interval is not set.
+func (j *CartExpiry) Run(ctx context.Context) {
+	if j.TTL <= 0 || j.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		deleted, err := j.RunOnce()
+		if err != nil {
+			log.Printf("cart expiry: %v", err)
+		} else if deleted > 0 {
+			log.Printf("cart expiry: deleted %d stale carts", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/bhyago/crud-products-go/internal/infra/notify"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// StockAlerts periodically opens an alert for every product below its
+// reorder point and resolves the alerts of products whose stock recovered.
+// A product is not alerted again while it has an alert that is not resolved.
+type StockAlerts struct {
+	AlertDB   database.StockAlertInterface
+	Notifiers []notify.Notifier
+	Interval  time.Duration
+	Now       func() time.Time
+}
+
+func NewStockAlerts(alertDB database.StockAlertInterface, interval time.Duration, notifiers ...notify.Notifier) *StockAlerts {
+	return &StockAlerts{
+		AlertDB:   alertDB,
+		Notifiers: notifiers,
+		Interval:  interval,
+		Now:       time.Now,
+	}
+}
+
+// RunOnce checks the stock and returns how many alerts were opened and
+// resolved. Alerts are notified again through the channels that failed.
+func (j *StockAlerts) RunOnce(ctx context.Context) (opened, resolved int, err error) {
+	low, err := j.AlertDB.FindLowStock()
+	if err != nil {
+		return 0, 0, err
+	}
+	unresolved, err := j.AlertDB.FindUnresolved()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	isLow := make(map[entityPkg.ID]bool, len(low))
+	for _, product := range low {
+		isLow[product.ProductID] = true
+	}
+	alerted := make(map[entityPkg.ID]bool, len(unresolved))
+	pending := []entity.StockAlert{}
+	for _, alert := range unresolved {
+		if !isLow[alert.ProductID] {
+			if err := j.AlertDB.Resolve(alert.ID, j.Now()); err != nil {
+				return opened, resolved, err
+			}
+			resolved++
+			continue
+		}
+		alerted[alert.ProductID] = true
+		if alert.NotifiedAt == nil {
+			pending = append(pending, alert)
+		}
+	}
+
+	for _, product := range low {
+		if alerted[product.ProductID] {
+			continue
+		}
+		alert := entity.NewStockAlert(product, j.Now())
+		if err := j.AlertDB.Create(alert); err != nil {
+			return opened, resolved, err
+		}
+		opened++
+		pending = append(pending, *alert)
+	}
+
+	for _, alert := range pending {
+		j.notify(ctx, alert)
+	}
+	return opened, resolved, nil
+}
+
+// Run checks the stock every Interval until ctx is done. It does nothing when
+// Interval is not set.
+func (j *StockAlerts) Run(ctx context.Context) {
+	if j.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		opened, resolved, err := j.RunOnce(ctx)
+		if err != nil {
+			log.Printf("stock alerts: %v", err)
+		} else if opened > 0 || resolved > 0 {
+			log.Printf("stock alerts: opened %d, resolved %d", opened, resolved)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify sends the alert through the channels it has not reached yet. Each
+// delivery is recorded, so a failed channel is retried on the next run
+// without sending the alert again through the others. The alert is marked as
+// notified once every channel succeeded.
+func (j *StockAlerts) notify(ctx context.Context, alert entity.StockAlert) {
+	delivered := alert.DeliveredTo
+	complete := true
+	for _, notifier := range j.Notifiers {
+		if delivered.Contains(notifier.Name()) {
+			continue
+		}
+		if err := notifier.Notify(ctx, alert); err != nil {
+			log.Printf("stock alerts: notify %s via %s: %v", alert.ID, notifier.Name(), err)
+			complete = false
+			continue
+		}
+		delivered = append(delivered, notifier.Name())
+	}
+
+	var notifiedAt *time.Time
+	if complete {
+		now := j.Now()
+		notifiedAt = &now
+	}
+	if err := j.AlertDB.MarkDelivered(alert.ID, delivered, notifiedAt); err != nil {
+		log.Printf("stock alerts: %v", err)
+	}
+}
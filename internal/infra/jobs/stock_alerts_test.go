@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeNotifier struct {
+	name   string
+	alerts []entity.StockAlert
+	err    error
+}
+
+func (n *fakeNotifier) Name() string {
+	return n.name
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, alert entity.StockAlert) error {
+	n.alerts = append(n.alerts, alert)
+	return n.err
+}
+
+func TestStockAlertsDoNotRealertUntilRecovered(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.StockLevel{}, &entity.StockAlert{})
+	product, _ := entity.NewProduct("Product 1", 10)
+	product.ReorderPoint = 5
+	assert.Nil(t, db.Create(product).Error)
+	level := entity.StockLevel{ProductID: product.ID, WarehouseID: entityPkg.NewID(), Quantity: 2}
+	assert.Nil(t, db.Create(&level).Error)
+
+	notifier := &fakeNotifier{}
+	job := NewStockAlerts(database.NewStockAlert(db), 0, notifier)
+
+	opened, resolved, err := job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, opened)
+	assert.Equal(t, 0, resolved)
+	assert.Len(t, notifier.alerts, 1)
+
+	opened, _, err = job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, opened)
+	assert.Len(t, notifier.alerts, 1)
+
+	db.Model(&level).UpdateColumn("quantity", 5)
+	_, resolved, err = job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, resolved)
+
+	db.Model(&level).UpdateColumn("quantity", 1)
+	opened, _, err = job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, opened)
+	assert.Len(t, notifier.alerts, 2)
+}
+
+func TestStockAlertsRetryFailedNotifications(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.StockLevel{}, &entity.StockAlert{})
+	product, _ := entity.NewProduct("Product 1", 10)
+	product.ReorderPoint = 5
+	assert.Nil(t, db.Create(product).Error)
+
+	notifier := &fakeNotifier{err: errors.New("unreachable")}
+	alertDB := database.NewStockAlert(db)
+	job := NewStockAlerts(alertDB, 0, notifier)
+
+	_, _, err = job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	notifier.err = nil
+	opened, _, err := job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, opened)
+	assert.Len(t, notifier.alerts, 2)
+
+	alerts, _ := alertDB.FindAll(entity.AlertOpen)
+	assert.Len(t, alerts, 1)
+	assert.NotNil(t, alerts[0].NotifiedAt)
+}
+
+func TestStockAlertsRetryOnlyFailedChannels(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.StockLevel{}, &entity.StockAlert{})
+	product, _ := entity.NewProduct("Product 1", 10)
+	product.ReorderPoint = 5
+	assert.Nil(t, db.Create(product).Error)
+
+	working := &fakeNotifier{name: "log"}
+	failing := &fakeNotifier{name: "webhook", err: errors.New("unreachable")}
+	alertDB := database.NewStockAlert(db)
+	job := NewStockAlerts(alertDB, 0, working, failing)
+
+	_, _, err = job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	_, _, err = job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, working.alerts, 1)
+	assert.Len(t, failing.alerts, 2)
+
+	alerts, _ := alertDB.FindAll(entity.AlertOpen)
+	assert.Len(t, alerts, 1)
+	assert.Nil(t, alerts[0].NotifiedAt)
+	assert.Equal(t, entity.StringList{"log"}, alerts[0].DeliveredTo)
+
+	failing.err = nil
+	_, _, err = job.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, working.alerts, 1)
+	assert.Len(t, failing.alerts, 3)
+
+	alerts, _ = alertDB.FindAll(entity.AlertOpen)
+	assert.NotNil(t, alerts[0].NotifiedAt)
+	assert.Equal(t, entity.StringList{"log", "webhook"}, alerts[0].DeliveredTo)
+}
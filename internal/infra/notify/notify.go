@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+)
+
+// EventLowStock is the event sent when a stock alert is opened.
+const EventLowStock = "stock.low"
+
+// Notifier delivers stock alerts through a channel. Name identifies the
+// channel in the deliveries recorded on an alert.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, alert entity.StockAlert) error
+}
+
+// Message is the payload sent for a stock alert.
+type Message struct {
+	Event string            `json:"event"`
+	Alert entity.StockAlert `json:"alert"`
+}
+
+// Webhook posts the alerts as JSON to URL and expects a 2xx response.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (n *Webhook) Name() string {
+	return "webhook"
+}
+
+func (n *Webhook) Notify(ctx context.Context, alert entity.StockAlert) error {
+	body, err := json.Marshal(Message{Event: EventLowStock, Alert: alert})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Log writes the alerts as JSON lines, to a file or to the standard logger.
+type Log struct {
+	Logger *log.Logger
+}
+
+func NewLog(w io.Writer) *Log {
+	if w == nil {
+		return &Log{Logger: log.Default()}
+	}
+	return &Log{Logger: log.New(w, "", log.LstdFlags)}
+}
+
+func (n *Log) Name() string {
+	return "log"
+}
+
+func (n *Log) Notify(ctx context.Context, alert entity.StockAlert) error {
+	body, err := json.Marshal(Message{Event: EventLowStock, Alert: alert})
+	if err != nil {
+		return err
+	}
+	n.Logger.Printf("%s", body)
+	return nil
+}
@@ -0,0 +1,66 @@
+package recommend
+
+import (
+	"sync"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/pkg/tfidf"
+)
+
+// SimilarIndex keeps the TF-IDF vectors of the product texts, with their
+// prices and tags, in memory to recommend similar products. Set as the Index
+// of database.Product, it follows the products saved and deleted without
+// being rebuilt.
+type SimilarIndex struct {
+	mu       sync.Mutex
+	text     *tfidf.Index
+	products map[string]entity.Product
+}
+
+func NewSimilarIndex() *SimilarIndex {
+	return &SimilarIndex{
+		text:     tfidf.New(),
+		products: make(map[string]entity.Product),
+	}
+}
+
+// Load adds products to the index, typically all of them on start up.
+func (s *SimilarIndex) Load(products []entity.Product) {
+	for _, product := range products {
+		s.Put(product)
+	}
+}
+
+// Put adds the product to the index, or replaces it.
+func (s *SimilarIndex) Put(product entity.Product) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := product.ID.String()
+	s.text.Put(id, product.SimilarText())
+	s.products[id] = product
+}
+
+// Remove removes the product from the index.
+func (s *SimilarIndex) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.text.Remove(id)
+	delete(s.products, id)
+}
+
+// Similar returns the limit products most similar to the product id, and
+// false when it is not indexed. The products are as they were last indexed.
+func (s *SimilarIndex) Similar(id string, limit int) ([]entity.SimilarProduct, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	product, ok := s.products[id]
+	if !ok {
+		return nil, false
+	}
+
+	candidates := make([]entity.Product, 0, len(s.products))
+	for _, candidate := range s.products {
+		candidates = append(candidates, candidate)
+	}
+	return entity.RankSimilar(product, candidates, s.text.Similar(id), limit), true
+}
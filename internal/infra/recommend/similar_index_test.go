@@ -0,0 +1,48 @@
+package recommend
+
+import (
+	"testing"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSimilarIndexFollowsProductChanges(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.Product{}, &entity.ProductSlug{}, &entity.BundleComponent{}, &entity.ProductTranslation{}, &entity.StockLevel{}, &entity.StockTransfer{})
+	productDB := database.NewProduct(db)
+	index := NewSimilarIndex()
+	productDB.Index = index
+
+	espresso, _ := entity.NewProduct("Espresso machine", 200)
+	espresso.Description = "Pump espresso machine with a milk frother"
+	capsule, _ := entity.NewProduct("Capsule coffee maker", 100)
+	kettle, _ := entity.NewProduct("Electric kettle", 40)
+	for _, product := range []*entity.Product{espresso, capsule, kettle} {
+		assert.Nil(t, productDB.Save(product))
+	}
+
+	similar, ok := index.Similar(espresso.ID.String(), 10)
+	assert.True(t, ok)
+	assert.Empty(t, similar)
+
+	capsule.Description = "Makes espresso from capsules"
+	assert.Nil(t, productDB.Update(capsule))
+	similar, _ = index.Similar(espresso.ID.String(), 10)
+	assert.Len(t, similar, 1)
+	assert.Equal(t, capsule.ID, similar[0].Product.ID)
+	assert.Greater(t, similar[0].TextScore, 0.0)
+	assert.Equal(t, 0.5, similar[0].PriceScore)
+
+	assert.Nil(t, productDB.Delete(capsule.ID.String()))
+	similar, _ = index.Similar(espresso.ID.String(), 10)
+	assert.Empty(t, similar)
+	_, ok = index.Similar(capsule.ID.String(), 10)
+	assert.False(t, ok)
+}
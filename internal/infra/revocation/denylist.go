@@ -0,0 +1,124 @@
+package revocation
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+)
+
+// Denylist holds the revoked access tokens in memory so that checking a
+// request never waits on the database. Revocations are written through to
+// the store; Refresh reloads them, picking up those of other instances, and
+// deletes the expired ones.
+type Denylist struct {
+	Store database.TokenRevocationInterface
+	// AccessTTL is the lifetime of the access tokens, for which a session
+	// revocation is kept.
+	AccessTTL time.Duration
+	Interval  time.Duration
+	Now       func() time.Time
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time
+	sessions map[string]entity.SessionRevocation
+}
+
+func NewDenylist(store database.TokenRevocationInterface, accessTTL, interval time.Duration) *Denylist {
+	return &Denylist{
+		Store:     store,
+		AccessTTL: accessTTL,
+		Interval:  interval,
+		Now:       time.Now,
+		tokens:    make(map[string]time.Time),
+		sessions:  make(map[string]entity.SessionRevocation),
+	}
+}
+
+// RevokeToken denies the access token jti of the user until it expires.
+func (d *Denylist) RevokeToken(jti string, userID entityPkg.ID, expiresAt time.Time) error {
+	err := d.Store.RevokeToken(&entity.RevokedToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt, CreatedAt: d.Now()})
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.tokens[jti] = expiresAt
+	d.mu.Unlock()
+	return nil
+}
+
+// RevokeSessions denies every access token issued to the user until now.
+func (d *Denylist) RevokeSessions(userID entityPkg.ID) error {
+	revocation := entity.NewSessionRevocation(userID, d.AccessTTL, d.Now())
+	if err := d.Store.RevokeSessions(revocation); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.sessions[userID.String()] = *revocation
+	d.mu.Unlock()
+	return nil
+}
+
+// IsRevoked reports whether the access token jti, issued to the user at
+// issuedAt, is revoked.
+func (d *Denylist) IsRevoked(jti, userID string, issuedAt time.Time) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if _, ok := d.tokens[jti]; ok && jti != "" {
+		return true
+	}
+	session, ok := d.sessions[userID]
+	return ok && session.Covers(issuedAt)
+}
+
+// Refresh deletes the expired revocations and reloads the others.
+func (d *Denylist) Refresh() error {
+	now := d.Now()
+	if _, err := d.Store.DeleteExpired(now); err != nil {
+		return err
+	}
+	revokedTokens, revokedSessions, err := d.Store.FindRevocations(now)
+	if err != nil {
+		return err
+	}
+
+	tokens := make(map[string]time.Time, len(revokedTokens))
+	for _, token := range revokedTokens {
+		tokens[token.JTI] = token.ExpiresAt
+	}
+	sessions := make(map[string]entity.SessionRevocation, len(revokedSessions))
+	for _, session := range revokedSessions {
+		sessions[session.UserID.String()] = session
+	}
+	d.mu.Lock()
+	d.tokens = tokens
+	d.sessions = sessions
+	d.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the denylist every Interval until ctx is done.
+func (d *Denylist) Run(ctx context.Context) {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := d.Refresh(); err != nil {
+			log.Printf("token denylist: %v", err)
+		}
+	}
+}
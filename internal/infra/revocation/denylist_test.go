@@ -0,0 +1,47 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDenylist(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+	db.AutoMigrate(&entity.RevokedToken{}, &entity.SessionRevocation{})
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	denylist := NewDenylist(database.NewTokenRevocation(db), 5*time.Minute, time.Minute)
+	denylist.Now = func() time.Time { return now }
+	alice, bob := entityPkg.NewID(), entityPkg.NewID()
+
+	assert.Nil(t, denylist.RevokeToken("jti-1", alice, now.Add(time.Minute)))
+	assert.True(t, denylist.IsRevoked("jti-1", alice.String(), now))
+	assert.False(t, denylist.IsRevoked("jti-2", alice.String(), now))
+	assert.False(t, denylist.IsRevoked("", alice.String(), now))
+
+	assert.Nil(t, denylist.RevokeSessions(bob))
+	assert.True(t, denylist.IsRevoked("jti-3", bob.String(), now.Add(-time.Minute)))
+	assert.False(t, denylist.IsRevoked("jti-4", bob.String(), now.Add(time.Second)))
+
+	// Another instance sees the revocations once refreshed, until they
+	// expire.
+	other := NewDenylist(database.NewTokenRevocation(db), 5*time.Minute, time.Minute)
+	other.Now = func() time.Time { return now }
+	assert.Nil(t, other.Refresh())
+	assert.True(t, other.IsRevoked("jti-1", alice.String(), now))
+	assert.True(t, other.IsRevoked("jti-3", bob.String(), now))
+
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, other.Refresh())
+	assert.False(t, other.IsRevoked("jti-1", alice.String(), now))
+	assert.True(t, other.IsRevoked("jti-3", bob.String(), now.Add(-3*time.Minute)))
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/revocation"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/go-chi/jwtauth"
+)
+
+// userIDFromRequest returns the authenticated user, taken from the sub claim
+// of the JWT verified by jwtauth.
+func userIDFromRequest(r *http.Request) (entityPkg.ID, error) {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return entityPkg.ID{}, err
+	}
+	sub, _ := claims["sub"].(string)
+	return entityPkg.ParseID(sub)
+}
+
+// optionalUserIDFromRequest is userIDFromRequest for routes open to anonymous
+// visitors: it returns nil when no token was sent, but still fails when the
+// token sent is invalid or expired.
+func optionalUserIDFromRequest(r *http.Request) (*entityPkg.ID, error) {
+	_, _, err := jwtauth.FromContext(r.Context())
+	if errors.Is(err, jwtauth.ErrNoTokenFound) {
+		return nil, nil
+	}
+	id, err := userIDFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// roleFromRequest returns the role claim of the JWT of the request.
+func roleFromRequest(r *http.Request) string {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
+
+// isAdmin reports whether the JWT of the request carries the admin role.
+func isAdmin(r *http.Request) bool {
+	return entity.HasRole(roleFromRequest(r), entity.RoleAdmin)
+}
+
+// RequireRole rejects the requests whose JWT role does not grant role. It
+// must run after jwtauth.Verifier and jwtauth.Authenticator.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !entity.HasRole(roleFromRequest(r), role) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RejectRevoked fails the verification of the tokens revoked by a logout,
+// as if they had expired. It must run after jwtauth.Verifier.
+func RejectRevoked(denylist *revocation.Denylist) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, _, err := jwtauth.FromContext(r.Context())
+			if err == nil && token != nil && denylist.IsRevoked(token.JwtID(), token.Subject(), token.IssuedAt()) {
+				r = r.WithContext(jwtauth.NewContext(r.Context(), token, entity.ErrTokenRevoked))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
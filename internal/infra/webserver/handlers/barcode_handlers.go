@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/bhyago/crud-products-go/pkg/barcode"
+	"github.com/go-chi/chi"
+)
+
+const (
+	// maxBarcodeScale bounds the width in pixels of a barcode module.
+	maxBarcodeScale = 10
+	// barcodeHeight is the height of the bars, in modules.
+	barcodeHeight = 50
+)
+
+// GetProductByGTIN godoc
+// @Summary Get a product by GTIN
+// @Description Get a product by its EAN-8, UPC-A, EAN-13 or GTIN-14 code, with or without leading zeros
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param code path string true "GTIN"
+// @Param Accept-Language header string false "Preferred locales"
+// @Success 200 {object} entity.Product
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/by-gtin/{code} [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetProductByGTIN(w http.ResponseWriter, r *http.Request) {
+	code := barcode.Normalize(chi.URLParam(r, "code"))
+	if !barcode.Valid(code) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: barcode.ErrInvalid.Error()})
+		return
+	}
+
+	product, err := h.ProductDB.FindByGTIN(code)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	h.recordView(r, product)
+	h.writeProduct(w, r, product)
+}
+
+// GetProductBarcodePNG godoc
+// @Summary Product barcode as PNG
+// @Description Draw the GTIN of a product as a PNG image: EAN-8, UPC-A, EAN-13 or ITF-14 depending on its length
+// @Tags products
+// @Produce  png
+// @Param id path string true "Product ID"
+// @Param scale query int false "Width in pixels of a module, from 1 to 10 (default 2)"
+// @Success 200 {file} file
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500
+// @Router /products/{id}/barcode.png [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetProductBarcodePNG(w http.ResponseWriter, r *http.Request) {
+	h.writeBarcode(w, r, "image/png", barcode.PNG)
+}
+
+// GetProductBarcodeSVG godoc
+// @Summary Product barcode as SVG
+// @Description Draw the GTIN of a product as an SVG image: EAN-8, UPC-A, EAN-13 or ITF-14 depending on its length
+// @Tags products
+// @Produce  image/svg+xml
+// @Param id path string true "Product ID"
+// @Param scale query int false "Width of a module, from 1 to 10 (default 2)"
+// @Success 200 {string} string
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500
+// @Router /products/{id}/barcode.svg [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetProductBarcodeSVG(w http.ResponseWriter, r *http.Request) {
+	h.writeBarcode(w, r, "image/svg+xml", barcode.SVG)
+}
+
+func (h *ProductHandle) writeBarcode(w http.ResponseWriter, r *http.Request, contentType string, render func(io.Writer, []bool, int, int) error) {
+	scale := 2
+	if value := r.URL.Query().Get("scale"); value != "" {
+		var err error
+		scale, err = strconv.Atoi(value)
+		if err != nil || scale < 1 || scale > maxBarcodeScale {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: "scale must be between 1 and 10"})
+			return
+		}
+	}
+
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if product.GTIN == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(Error{Message: "product has no gtin"})
+		return
+	}
+	modules, err := barcode.Encode(*product.GTIN)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	render(w, modules, scale, barcodeHeight*scale)
+}
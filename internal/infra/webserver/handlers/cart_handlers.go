@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+// CartHeader carries the ID of an anonymous cart. It is returned on every
+// response about an anonymous cart and must be sent back to keep using it.
+const CartHeader = "X-Cart-ID"
+
+type CartHandle struct {
+	CartDB      database.CartInterface
+	ProductDB   database.ProductInterface
+	PromotionDB database.PromotionInterface
+	TTL         time.Duration
+	Now         func() time.Time
+}
+
+func NewCartHandle(cartDB database.CartInterface, productDB database.ProductInterface, promotionDB database.PromotionInterface, ttl time.Duration) *CartHandle {
+	return &CartHandle{
+		CartDB:      cartDB,
+		ProductDB:   productDB,
+		PromotionDB: promotionDB,
+		TTL:         ttl,
+		Now:         time.Now,
+	}
+}
+
+// GetCart godoc
+// @Summary Get the cart
+// @Description Get the cart of the authenticated user, or the anonymous cart given in X-Cart-ID, flagging lines whose price changed since they were added
+// @Tags cart
+// @Accept  json
+// @Produce  json
+// @Param X-Cart-ID header string false "Anonymous cart ID"
+// @Success 200 {object} entity.Cart
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /cart [get]
+// @Security ApiKeyAuth
+func (h *CartHandle) GetCart(w http.ResponseWriter, r *http.Request) {
+	cart, ok := h.openCart(w, r, false)
+	if !ok {
+		return
+	}
+	h.writeCart(w, http.StatusOK, cart)
+}
+
+// AddCartLine godoc
+// @Summary Add a product to the cart
+// @Description Add a product to the cart, creating the cart when needed. Anonymous visitors get the new cart ID in X-Cart-ID
+// @Tags cart
+// @Accept  json
+// @Produce  json
+// @Param X-Cart-ID header string false "Anonymous cart ID"
+// @Param request body dto.AddCartLineInput true "Line request"
+// @Success 200 {object} entity.Cart
+// @Failure 400 {object} Error
+// @Failure 401
+// @Failure 422 {object} Error
+// @Failure 500
+// @Router /cart/lines [post]
+// @Security ApiKeyAuth
+func (h *CartHandle) AddCartLine(w http.ResponseWriter, r *http.Request) {
+	var input dto.AddCartLineInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, ok := h.currentProduct(w, input.ProductID)
+	if !ok {
+		return
+	}
+	cart, ok := h.openCart(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := cart.AddProduct(product, input.Quantity); err != nil {
+		writeCartError(w, err)
+		return
+	}
+	h.saveCart(w, cart)
+}
+
+// UpdateCartLine godoc
+// @Summary Change the quantity of a cart line
+// @Description Change the quantity of a product in the cart. The line takes the current price of the product
+// @Tags cart
+// @Accept  json
+// @Produce  json
+// @Param X-Cart-ID header string false "Anonymous cart ID"
+// @Param product_id path string true "Product ID"
+// @Param request body dto.UpdateCartLineInput true "Line request"
+// @Success 200 {object} entity.Cart
+// @Failure 400 {object} Error
+// @Failure 401
+// @Failure 404
+// @Failure 422 {object} Error
+// @Failure 500
+// @Router /cart/lines/{product_id} [put]
+// @Security ApiKeyAuth
+func (h *CartHandle) UpdateCartLine(w http.ResponseWriter, r *http.Request) {
+	var input dto.UpdateCartLineInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cart, ok := h.openCart(w, r, false)
+	if !ok {
+		return
+	}
+	product, ok := h.currentProduct(w, chi.URLParam(r, "product_id"))
+	if !ok {
+		return
+	}
+
+	if err := cart.SetProduct(product, input.Quantity); err != nil {
+		writeCartError(w, err)
+		return
+	}
+	h.saveCart(w, cart)
+}
+
+// RemoveCartLine godoc
+// @Summary Remove a product from the cart
+// @Description Remove a product from the cart
+// @Tags cart
+// @Accept  json
+// @Produce  json
+// @Param X-Cart-ID header string false "Anonymous cart ID"
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} entity.Cart
+// @Failure 400 {object} Error
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /cart/lines/{product_id} [delete]
+// @Security ApiKeyAuth
+func (h *CartHandle) RemoveCartLine(w http.ResponseWriter, r *http.Request) {
+	productID, err := entityPkg.ParseID(chi.URLParam(r, "product_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrInvalidID.Error()})
+		return
+	}
+
+	cart, ok := h.openCart(w, r, false)
+	if !ok {
+		return
+	}
+
+	if err := cart.RemoveProduct(productID); err != nil {
+		writeCartError(w, err)
+		return
+	}
+	h.saveCart(w, cart)
+}
+
+// AcceptCartPrices godoc
+// @Summary Accept the current prices
+// @Description Take the current price of every product as the price of its cart line
+// @Tags cart
+// @Accept  json
+// @Produce  json
+// @Param X-Cart-ID header string false "Anonymous cart ID"
+// @Success 200 {object} entity.Cart
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /cart/accept_prices [post]
+// @Security ApiKeyAuth
+func (h *CartHandle) AcceptCartPrices(w http.ResponseWriter, r *http.Request) {
+	cart, ok := h.openCart(w, r, false)
+	if !ok {
+		return
+	}
+
+	if err := h.reconcile(cart); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	cart.AcceptPrices()
+	h.saveCart(w, cart)
+}
+
+// openCart loads the cart of the caller: the user cart when a token was sent,
+// the anonymous cart in CartHeader otherwise. When create is set, a missing
+// cart is created instead of answering 404.
+func (h *CartHandle) openCart(w http.ResponseWriter, r *http.Request, create bool) (*entity.Cart, bool) {
+	userID, err := optionalUserIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	var cart *entity.Cart
+	switch {
+	case userID != nil:
+		cart, err = h.CartDB.FindByUserID(userID.String())
+	case r.Header.Get(CartHeader) != "":
+		cart, err = h.CartDB.FindByID(r.Header.Get(CartHeader))
+		if err == nil && cart.UserID != nil {
+			cart, err = nil, gorm.ErrRecordNotFound
+		}
+	default:
+		err = gorm.ErrRecordNotFound
+	}
+
+	if err == nil && cart.IsExpired(h.TTL, h.Now()) {
+		if err := h.CartDB.Delete(cart.ID.String()); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return nil, false
+		}
+		cart, err = nil, gorm.ErrRecordNotFound
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if !create {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+		return entity.NewCart(userID), true
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	return cart, true
+}
+
+// currentProduct loads the product with the promotions running now applied,
+// which is the price a new cart line snapshots.
+func (h *CartHandle) currentProduct(w http.ResponseWriter, id string) (*entity.Product, bool) {
+	product, err := h.ProductDB.FindByID(id)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: "product not found: " + id})
+		return nil, false
+	}
+
+	now := h.Now()
+	promotions, err := h.PromotionDB.FindRunning(now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	product.ApplyPromotions(promotions, now)
+	return product, true
+}
+
+// reconcile compares the cart lines with the current products.
+func (h *CartHandle) reconcile(cart *entity.Cart) error {
+	now := h.Now()
+	promotions, err := h.PromotionDB.FindRunning(now)
+	if err != nil {
+		return err
+	}
+
+	products := make(map[string]entity.Product, len(cart.Lines))
+	for _, line := range cart.Lines {
+		product, err := h.ProductDB.FindByID(line.ProductID.String())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		product.ApplyPromotions(promotions, now)
+		products[product.ID.String()] = *product
+	}
+	cart.Reconcile(products)
+	return nil
+}
+
+func (h *CartHandle) saveCart(w http.ResponseWriter, cart *entity.Cart) {
+	if err := h.CartDB.Save(cart); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.writeCart(w, http.StatusOK, cart)
+}
+
+func (h *CartHandle) writeCart(w http.ResponseWriter, status int, cart *entity.Cart) {
+	if err := h.reconcile(cart); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if cart.UserID == nil {
+		w.Header().Set(CartHeader, cart.ID.String())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(cart)
+}
+
+func writeCartError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrQuantityInvalid):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, entity.ErrCartLineNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, entity.ErrProductUnavailable):
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
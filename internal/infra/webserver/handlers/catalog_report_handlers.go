@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+)
+
+// GetCatalogReport godoc
+// @Summary Catalog report
+// @Description Count the products matching the filters, with price statistics, a price histogram and the number of products created per period. Download it as JSON or CSV
+// @Tags reports
+// @Accept  json
+// @Produce  json,text/csv
+// @Param type query string false "Product type"
+// @Param available query bool false "Availability"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param created_from query string false "Created on or after, as 2006-01-02 or RFC 3339"
+// @Param created_to query string false "Created before, as 2006-01-02 or RFC 3339"
+// @Param buckets query string false "Lower bounds of the price buckets, e.g. 0,10,50,100,500 (default)"
+// @Param interval query string false "Creation period: day, week or month (default)"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} entity.CatalogReport
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /reports/catalog [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetCatalogReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := catalogFilter(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	buckets, err := entity.ParsePriceBuckets(query.Get("buckets"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	interval := query.Get("interval")
+	if interval == "" {
+		interval = entity.ReportIntervalMonth
+	}
+	if !entity.IsReportInterval(interval) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrIntervalInvalid.Error()})
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: "format must be json or csv"})
+		return
+	}
+
+	report, err := h.ProductDB.CatalogReport(filter, buckets, interval)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="catalog-report.csv"`)
+		w.WriteHeader(http.StatusOK)
+		csv.NewWriter(w).WriteAll(report.Rows())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if format == "json" {
+		w.Header().Set("Content-Disposition", `attachment; filename="catalog-report.json"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+func catalogFilter(query url.Values) (database.ProductFilter, error) {
+	filter := database.ProductFilter{Type: query.Get("type")}
+
+	if value := query.Get("available"); value != "" {
+		available, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New("available must be true or false")
+		}
+		filter.Available = &available
+	}
+	for name, target := range map[string]**float64{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice} {
+		if value := query.Get(name); value != "" {
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return filter, errors.New(name + " must be a number")
+			}
+			*target = &price
+		}
+	}
+	for name, target := range map[string]**time.Time{"created_from": &filter.CreatedFrom, "created_to": &filter.CreatedTo} {
+		if value := query.Get(name); value != "" {
+			date, err := parseReportDate(value)
+			if err != nil {
+				return filter, errors.New(name + " must be a date (2006-01-02) or an RFC 3339 time")
+			}
+			*target = &date
+		}
+	}
+	return filter, nil
+}
+
+func parseReportDate(value string) (time.Time, error) {
+	if date, err := time.Parse(entity.DayLayout, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+type CouponHandle struct {
+	CouponDB    database.CouponInterface
+	ProductDB   database.ProductInterface
+	PromotionDB database.PromotionInterface
+	Now         func() time.Time
+}
+
+func NewCouponHandle(couponDB database.CouponInterface, productDB database.ProductInterface, promotionDB database.PromotionInterface) *CouponHandle {
+	return &CouponHandle{
+		CouponDB:    couponDB,
+		ProductDB:   productDB,
+		PromotionDB: promotionDB,
+		Now:         time.Now,
+	}
+}
+
+// GenerateCoupons godoc
+// @Summary Generate coupons
+// @Description Generate a batch of coupons with random unique codes sharing the same rules
+// @Tags coupons
+// @Accept  json
+// @Produce  json
+// @Param request body dto.GenerateCouponsInput true "Batch request"
+// @Success 201 {object} []entity.Coupon
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /coupons [post]
+// @Security ApiKeyAuth
+func (h *CouponHandle) GenerateCoupons(w http.ResponseWriter, r *http.Request) {
+	var input dto.GenerateCouponsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	productIDs := entity.StringList{}
+	for _, id := range input.ProductIDs {
+		parsed, err := entityPkg.ParseID(id)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: entity.ErrInvalidID.Error()})
+			return
+		}
+		productIDs = append(productIDs, parsed.String())
+	}
+
+	template := entity.Coupon{
+		Kind:           input.Kind,
+		Value:          input.Value,
+		MinPrice:       input.MinPrice,
+		ProductIDs:     productIDs,
+		Tags:           entity.NormalizeTags(input.Tags),
+		MaxRedemptions: input.MaxRedemptions,
+		MaxPerUser:     input.MaxPerUser,
+		ExpiresAt:      input.ExpiresAt,
+	}
+	coupons, err := entity.NewCouponBatch(template, input.Count, input.Prefix, input.Length)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	err = h.CouponDB.SaveBatch(coupons, func() (string, error) {
+		return entity.GenerateCouponCode(input.Prefix, input.Length)
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(coupons)
+}
+
+// GetCoupons godoc
+// @Summary List coupons
+// @Description List coupons, optionally from a single batch
+// @Tags coupons
+// @Accept  json
+// @Produce  json
+// @Param batch_id query string false "Batch ID"
+// @Success 200 {object} []entity.Coupon
+// @Failure 500
+// @Router /coupons [get]
+// @Security ApiKeyAuth
+func (h *CouponHandle) GetCoupons(w http.ResponseWriter, r *http.Request) {
+	coupons, err := h.CouponDB.FindAll(r.URL.Query().Get("batch_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(coupons)
+}
+
+// ValidateCoupon godoc
+// @Summary Validate a coupon
+// @Description Check a coupon against products and quantities and return the discounted lines
+// @Tags coupons
+// @Accept  json
+// @Produce  json
+// @Param code path string true "Coupon code"
+// @Param request body dto.ValidateCouponInput true "Lines"
+// @Success 200 {object} entity.CouponResult
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 422 {object} Error
+// @Failure 500
+// @Router /coupons/{code}/validate [post]
+// @Security ApiKeyAuth
+func (h *CouponHandle) ValidateCoupon(w http.ResponseWriter, r *http.Request) {
+	coupon, result, ok := h.evaluate(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if coupon.MaxRedemptions > 0 && coupon.Redemptions >= coupon.MaxRedemptions {
+		writeCouponError(w, entity.ErrCouponLimitReached)
+		return
+	}
+	if coupon.MaxPerUser > 0 {
+		used, err := h.CouponDB.CountRedemptions(coupon.Code, userID.String())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if used >= int64(coupon.MaxPerUser) {
+			writeCouponError(w, entity.ErrCouponUserLimitReached)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// RedeemCoupon godoc
+// @Summary Redeem a coupon
+// @Description Validate a coupon and record its redemption by the authenticated user
+// @Tags coupons
+// @Accept  json
+// @Produce  json
+// @Param code path string true "Coupon code"
+// @Param request body dto.ValidateCouponInput true "Lines"
+// @Success 201 {object} dto.RedeemCouponOutput
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 422 {object} Error
+// @Failure 500
+// @Router /coupons/{code}/redeem [post]
+// @Security ApiKeyAuth
+func (h *CouponHandle) RedeemCoupon(w http.ResponseWriter, r *http.Request) {
+	coupon, result, ok := h.evaluate(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	redemption, err := h.CouponDB.Redeem(coupon.Code, userID, result.Discount, h.Now())
+	if err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.RedeemCouponOutput{
+		RedemptionID: redemption.ID.String(),
+		CouponResult: *result,
+	})
+}
+
+// evaluate loads the coupon and the requested products and applies the
+// coupon to them. It writes the error response itself and returns false when
+// the request cannot go on.
+func (h *CouponHandle) evaluate(w http.ResponseWriter, r *http.Request) (*entity.Coupon, *entity.CouponResult, bool) {
+	coupon, err := h.CouponDB.FindByCode(entity.NormalizeCouponCode(chi.URLParam(r, "code")))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	var input dto.ValidateCouponInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || len(input.Lines) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	now := h.Now()
+	promotions, err := h.PromotionDB.FindRunning(now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	lines := make([]entity.CouponLine, len(input.Lines))
+	for i, line := range input.Lines {
+		product, err := h.ProductDB.FindByID(line.ProductID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: "product not found: " + line.ProductID})
+			return nil, nil, false
+		}
+		product.ApplyPromotions(promotions, now)
+		lines[i] = entity.CouponLine{Product: *product, Quantity: line.Quantity}
+	}
+
+	result, err := coupon.Apply(lines, now)
+	if errors.Is(err, entity.ErrQuantityInvalid) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return nil, nil, false
+	}
+	if err != nil {
+		writeCouponError(w, err)
+		return nil, nil, false
+	}
+	return coupon, result, true
+}
+
+func writeCouponError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrCouponExpired),
+		errors.Is(err, entity.ErrCouponLimitReached),
+		errors.Is(err, entity.ErrCouponUserLimitReached),
+		errors.Is(err, entity.ErrCouponMinimumNotReached),
+		errors.Is(err, entity.ErrCouponNotApplicable):
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+)
+
+// GetDuplicates godoc
+// @Summary Probable duplicate products
+// @Description List the clusters of products whose names are similar, the closest first
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param threshold query number false "Name similarity from which products are duplicates, between 0 and 1"
+// @Success 200 {array} entity.DuplicateCluster
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /products/duplicates [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	threshold := h.DuplicateThreshold
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || !entity.IsValidThreshold(threshold) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: entity.ErrThresholdInvalid.Error()})
+			return
+		}
+	}
+
+	names, err := h.ProductDB.FindNames()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entity.ClusterDuplicates(names, threshold))
+}
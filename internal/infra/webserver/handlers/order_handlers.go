@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+)
+
+// IdempotencyKeyHeader carries the client-supplied key of an order. Sending
+// the same key again returns the order already placed with it.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type OrderHandle struct {
+	OrderDB     database.OrderInterface
+	PromotionDB database.PromotionInterface
+	Now         func() time.Time
+}
+
+func NewOrderHandle(orderDB database.OrderInterface, promotionDB database.PromotionInterface) *OrderHandle {
+	return &OrderHandle{
+		OrderDB:     orderDB,
+		PromotionDB: promotionDB,
+		Now:         time.Now,
+	}
+}
+
+// CreateOrder godoc
+// @Summary Place an order
+// @Description Place an order for the authenticated user at the current prices. Lines with unit_price fail with 409 when the price moved. Retrying with the same Idempotency-Key returns the order already placed
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param Idempotency-Key header string true "Client-supplied key of the order"
+// @Param request body dto.CreateOrderInput true "Order request"
+// @Success 200 {object} entity.Order
+// @Success 201 {object} entity.Order
+// @Failure 400 {object} Error
+// @Failure 401
+// @Failure 409 {object} Error
+// @Failure 422 {object} Error
+// @Failure 500
+// @Router /orders [post]
+// @Security ApiKeyAuth
+func (h *OrderHandle) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var input dto.CreateOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	items := make([]entity.OrderItem, len(input.Lines))
+	for i, line := range input.Lines {
+		items[i] = entity.OrderItem{ProductID: line.ProductID, Quantity: line.Quantity, ExpectedPrice: line.UnitPrice}
+	}
+
+	now := h.Now()
+	promotions, err := h.PromotionDB.FindRunning(now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	order, created, err := h.OrderDB.Checkout(userID, r.Header.Get(IdempotencyKeyHeader), items, promotions, now)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetOrders godoc
+// @Summary List my orders
+// @Description List the orders of the authenticated user, newest first
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param status query string false "Status (pending, paid, shipped or cancelled)"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} []entity.Order
+// @Failure 401
+// @Failure 500
+// @Router /orders [get]
+// @Security ApiKeyAuth
+func (h *OrderHandle) GetOrders(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	h.listOrders(w, r, database.OrderFilter{UserID: userID.String(), Status: r.URL.Query().Get("status")})
+}
+
+// GetAllOrders godoc
+// @Summary List all orders
+// @Description List the orders of every user, newest first. Admin only
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param status query string false "Status (pending, paid, shipped or cancelled)"
+// @Param user_id query string false "User ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} []entity.Order
+// @Failure 403
+// @Failure 500
+// @Router /admin/orders [get]
+// @Security ApiKeyAuth
+func (h *OrderHandle) GetAllOrders(w http.ResponseWriter, r *http.Request) {
+	h.listOrders(w, r, database.OrderFilter{UserID: r.URL.Query().Get("user_id"), Status: r.URL.Query().Get("status")})
+}
+
+// GetOrder godoc
+// @Summary Get an order
+// @Description Get an order of the authenticated user. Admins can get any order
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Success 200 {object} entity.Order
+// @Failure 404
+// @Router /orders/{id} [get]
+// @Security ApiKeyAuth
+func (h *OrderHandle) GetOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.visibleOrder(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+// UpdateOrderStatus godoc
+// @Summary Change the status of an order
+// @Description Move an order through pending, paid, shipped and cancelled. Customers can only cancel their own orders while they are pending; admins can apply any allowed transition
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param request body dto.UpdateOrderStatusInput true "Status request"
+// @Success 200 {object} entity.Order
+// @Failure 400 {object} Error
+// @Failure 403
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /orders/{id}/status [put]
+// @Security ApiKeyAuth
+func (h *OrderHandle) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.visibleOrder(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateOrderStatusInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var err error
+	switch {
+	case isAdmin(r):
+		order, err = h.OrderDB.UpdateStatus(order.ID.String(), input.Status)
+	case input.Status == entity.OrderCancelled:
+		order, err = h.OrderDB.Cancel(order.ID.String())
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+// visibleOrder loads the order in the URL when the caller owns it or is an
+// admin. Orders of other users answer 404, as if they did not exist.
+func (h *OrderHandle) visibleOrder(w http.ResponseWriter, r *http.Request) (*entity.Order, bool) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	order, err := h.OrderDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil || (order.UserID != userID && !isAdmin(r)) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+	return order, true
+}
+
+func (h *OrderHandle) listOrders(w http.ResponseWriter, r *http.Request, filter database.OrderFilter) {
+	if filter.Status != "" && !entity.IsOrderStatus(filter.Status) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrOrderStatusInvalid.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil {
+		page = 0
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	orders, err := h.OrderDB.FindAll(filter, page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(orders)
+}
+
+func writeOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrIdempotencyKeyRequired),
+		errors.Is(err, entity.ErrIdempotencyKeyTooLong),
+		errors.Is(err, entity.ErrOrderEmpty),
+		errors.Is(err, entity.ErrOrderStatusInvalid),
+		errors.Is(err, entity.ErrQuantityInvalid),
+		errors.Is(err, database.ErrProductNotFound):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, entity.ErrOrderPriceChanged),
+		errors.Is(err, entity.ErrOrderStatusTransition),
+		errors.Is(err, entity.ErrOrderNotCancellable):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, entity.ErrProductUnavailable):
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
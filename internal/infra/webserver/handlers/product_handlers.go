@@ -2,23 +2,46 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/bhyago/crud-products-go/internal/dto"
 	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/analytics"
 	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/bhyago/crud-products-go/internal/infra/recommend"
 	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
 	"github.com/go-chi/chi"
+	"gorm.io/gorm"
 )
 
 type ProductHandle struct {
-	ProductDB database.ProductInterface
+	ProductDB     database.ProductInterface
+	TranslationDB database.ProductTranslationInterface
+	PromotionDB   database.PromotionInterface
+	TemplateDB    database.ProductTemplateInterface
+	DefaultLocale string
+	Views         *analytics.ViewCounter
+	Similar       *recommend.SimilarIndex
+	// DuplicateThreshold is the name similarity from which a new product is
+	// refused as a probable duplicate.
+	DuplicateThreshold float64
+	Now                func() time.Time
 }
 
-func NewProductHandle(db database.ProductInterface) *ProductHandle {
+func NewProductHandle(db database.ProductInterface, translationDB database.ProductTranslationInterface, promotionDB database.PromotionInterface, defaultLocale string) *ProductHandle {
 	return &ProductHandle{
-		ProductDB: db,
+		ProductDB:          db,
+		TranslationDB:      translationDB,
+		PromotionDB:        promotionDB,
+		DefaultLocale:      defaultLocale,
+		DuplicateThreshold: entity.DefaultDuplicateThreshold,
+		Now:                time.Now,
 	}
 }
 
@@ -29,12 +52,35 @@ func NewProductHandle(db database.ProductInterface) *ProductHandle {
 // @Accept  json
 // @Produce  json
 // @Param request body dto.CreateProductInput true "Product request"
+// @Param template query string false "ID of the template pre-filling the price, description, availability and tags"
+// @Param force query bool false "Create the product even when its name looks like an existing one"
 // @Success 201
+// @Failure 400 {object} Error
+// @Failure 409 {object} dto.DuplicateProductsOutput "the name looks like existing products, or the gtin is already used"
 // @Failure 500
 // @Router /products [post]
 // @Security ApiKeyAuth
 func (h *ProductHandle) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	var product dto.CreateProductInput
+	if templateID := r.URL.Query().Get("template"); templateID != "" {
+		template, err := h.findTemplate(templateID)
+		if errors.Is(err, entity.ErrTemplateNotFound) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: err.Error()})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// The fields of the body are decoded over the defaults.
+		product = dto.CreateProductInput{
+			Price:       template.Price,
+			Description: template.Description,
+			Available:   template.Available,
+			Tags:        template.Tags,
+		}
+	}
 
 	err := json.NewDecoder(r.Body).Decode(&product)
 	if err != nil {
@@ -42,19 +88,184 @@ func (h *ProductHandle) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newProduct, err := entity.NewProduct(product.Name, product.Price)
+	var newProduct *entity.Product
+	switch product.Type {
+	case "", entity.ProductTypeSimple:
+		newProduct, err = entity.NewProduct(product.Name, product.Price)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if product.Available != nil {
+			newProduct.Available = *product.Available
+		}
+	case entity.ProductTypeBundle:
+		components, err := bundleComponents(product.Components)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: err.Error()})
+			return
+		}
+		newProduct, err = entity.NewBundle(product.Name, product.Price, product.Pricing, product.Discount, components)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: err.Error()})
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrTypeInvalid.Error()})
+		return
+	}
+
+	newProduct.Tags = entity.NormalizeTags(product.Tags)
+	if err := newProduct.SetDescription(product.Description); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := newProduct.SetGTIN(product.GTIN); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := setPackage(newProduct, product); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	if r.URL.Query().Get("force") != "true" {
+		names, err := h.ProductDB.FindNames()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if candidates := entity.FindDuplicates(newProduct.Name, names, h.DuplicateThreshold); len(candidates) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(dto.DuplicateProductsOutput{
+				Message:    "product name looks like existing products, pass force=true to create it anyway",
+				Candidates: candidates,
+			})
+			return
+		}
+	}
+
+	err = h.ProductDB.Save(newProduct)
+	if errors.Is(err, entity.ErrComponentInvalid) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrGTINTaken) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	err = h.ProductDB.Save(newProduct)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// setPackage sets the weight and dimensions of the product from the input.
+// Left out, they are unknown.
+func setPackage(product *entity.Product, input dto.CreateProductInput) error {
+	weight, dimensions := entity.Weight{}, entity.Dimensions{}
+	if input.Weight != nil {
+		weight = *input.Weight
+	}
+	if input.Dimensions != nil {
+		dimensions = *input.Dimensions
+	}
+	if err := product.SetWeight(weight); err != nil {
+		return err
+	}
+	return product.SetDimensions(dimensions)
+}
+
+func (h *ProductHandle) findTemplate(id string) (*entity.ProductTemplate, error) {
+	if h.TemplateDB == nil {
+		return nil, entity.ErrTemplateNotFound
+	}
+	template, err := h.TemplateDB.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, entity.ErrTemplateNotFound
+	}
+	return template, err
+}
+
+// CloneProduct godoc
+// @Summary Clone a product
+// @Description Create a copy of a product under a new ID and slug, with the fields of the request overriding the copied ones. Reviews, cost and stock are not copied
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param request body dto.CloneProductInput false "Overridden fields"
+// @Success 201 {object} entity.Product
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/clone [post]
+// @Security ApiKeyAuth
+func (h *ProductHandle) CloneProduct(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.CloneProductInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	clone := product.Clone()
+	if input.Name != nil {
+		clone.Name = *input.Name
+	}
+	if input.Price != nil {
+		clone.Price = *input.Price
+	}
+	if input.Available != nil {
+		clone.Available = *input.Available
+	}
+	if input.Tags != nil {
+		clone.Tags = entity.NormalizeTags(*input.Tags)
+	}
+	if input.Description != nil {
+		err = clone.SetDescription(*input.Description)
+	}
+	if err == nil && input.ReorderPoint != nil {
+		err = clone.SetReorderPoint(*input.ReorderPoint)
+	}
+	if err == nil {
+		err = clone.Validate()
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	err = h.ProductDB.Save(clone)
+	if errors.Is(err, entity.ErrComponentInvalid) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(clone)
 }
 
 // GetProduct godoc
@@ -64,7 +275,9 @@ func (h *ProductHandle) CreateProduct(w http.ResponseWriter, r *http.Request) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Product ID"
-// @Success 200 {object} entity.Product
+// @Param Accept-Language header string false "Preferred locales"
+// @Success 200 {object} entity.Product "price is the list price; effective_price includes running promotions"
+// @Success 301 "the product was merged into another one"
 // @Failure 404
 // @Failure 500
 // @Router /products/{id} [get]
@@ -78,12 +291,52 @@ func (h *ProductHandle) GetProduct(w http.ResponseWriter, r *http.Request) {
 
 	product, err := h.ProductDB.FindByID(id)
 	if err != nil {
+		if redirect, err := h.ProductDB.FindRedirect(id); err == nil {
+			redirectPermanently(w, r, "/products/"+redirect.ToID.String())
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(product)
+
+	h.recordView(r, product)
+	h.writeProduct(w, r, product)
+}
+
+// GetProductBySlug godoc
+// @Summary Get a product by slug
+// @Description Get a product by its slug. Retired slugs redirect to the current one.
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param slug path string true "Product slug"
+// @Param Accept-Language header string false "Preferred locales"
+// @Success 200 {object} entity.Product
+// @Success 301
+// @Failure 404
+// @Failure 500
+// @Router /products/by-slug/{slug} [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetProductBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	if slug == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.ProductDB.FindBySlug(slug)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if product.Slug != slug {
+		redirectPermanently(w, r, "/products/by-slug/"+url.PathEscape(product.Slug))
+		return
+	}
+
+	h.recordView(r, product)
+	h.writeProduct(w, r, product)
 }
 
 // GetProducts godoc
@@ -95,7 +348,12 @@ func (h *ProductHandle) GetProduct(w http.ResponseWriter, r *http.Request) {
 // @Param page query int false "Page"
 // @Param limit query int false "Limit"
 // @Param sort query string false "Sort"
+// @Param type query string false "Product type (simple or bundle)"
+// @Param min_rating query number false "Only products rated at least this average; lists the best rated first"
+// @Param order_by query string false "Set to rating to list the best rated products first"
+// @Param Accept-Language header string false "Preferred locales"
 // @Success 200 {object} []entity.Product
+// @Failure 400
 // @Failure 500
 // @Router /products [get]
 // @Security ApiKeyAuth
@@ -115,13 +373,32 @@ func (h *ProductHandle) GetProducts(w http.ResponseWriter, r *http.Request) {
 
 	sort := r.URL.Query().Get("sort")
 
-	products, err := h.ProductDB.FindAll(pageInt, limitInt, sort)
+	filter := database.ProductFilter{
+		Type:    r.URL.Query().Get("type"),
+		OrderBy: r.URL.Query().Get("order_by"),
+	}
+	if minRating := r.URL.Query().Get("min_rating"); minRating != "" {
+		filter.MinRating, err = strconv.ParseFloat(minRating, 64)
+		if err != nil || filter.MinRating < 0 || filter.MinRating > entity.MaxRating {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		filter.OrderBy = database.ProductOrderRating
+	}
+
+	products, err := h.ProductDB.FindAllBy(filter, pageInt, limitInt, sort)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	if err := h.present(r, products); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Vary", "Accept-Language")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(products)
 }
@@ -137,6 +414,7 @@ func (h *ProductHandle) GetProducts(w http.ResponseWriter, r *http.Request) {
 // @Success 200
 // @Failure 400
 // @Failure 404
+// @Failure 409 {object} Error
 // @Failure 500
 // @Router /products/{id} [put]
 // @Security ApiKeyAuth
@@ -147,26 +425,79 @@ func (h *ProductHandle) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var product entity.Product
+	var input dto.CreateProductInput
 
-	err := json.NewDecoder(r.Body).Decode(&product)
+	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	product.ID, err = entityPkg.ParseID(id)
-	if err != nil {
+	if _, err = entityPkg.ParseID(id); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	_, err = h.ProductDB.FindByID(id)
+	product, err := h.ProductDB.FindByID(id)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
-	err = h.ProductDB.Update(&product)
+
+	if input.Type != "" && input.Type != product.Type {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrTypeInvalid.Error()})
+		return
+	}
+
+	product.Name = input.Name
+	product.Price = input.Price
+	product.Tags = entity.NormalizeTags(input.Tags)
+	if product.IsBundle() {
+		components, err := bundleComponents(input.Components)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: err.Error()})
+			return
+		}
+		product.Pricing = input.Pricing
+		product.Discount = input.Discount
+		product.SetComponents(components)
+	} else if input.Available != nil {
+		product.Available = *input.Available
+	}
+	if err := product.SetDescription(input.Description); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := product.SetGTIN(input.GTIN); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := setPackage(product, input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := product.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	err = h.ProductDB.Update(product)
+	if errors.Is(err, entity.ErrComponentInvalid) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrGTINTaken) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -185,6 +516,7 @@ func (h *ProductHandle) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 // @Success 200
 // @Failure 400
 // @Failure 404
+// @Failure 409 {object} Error
 // @Failure 500
 // @Router /products/{id} [delete]
 // @Security ApiKeyAuth
@@ -202,10 +534,94 @@ func (h *ProductHandle) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = h.ProductDB.Delete(id)
+	if errors.Is(err, entity.ErrProductInBundle) || errors.Is(err, entity.ErrProductHasStock) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// redirectPermanently redirects to path, keeping the query string of the
+// request.
+func redirectPermanently(w http.ResponseWriter, r *http.Request, path string) {
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, path, http.StatusMovedPermanently)
+}
+
+// recordView counts the view of the product by the authenticated user, or by
+// the client address for anonymous requests.
+func (h *ProductHandle) recordView(r *http.Request, product *entity.Product) {
+	if h.Views == nil {
+		return
+	}
+
+	viewer, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
+		viewer = r.RemoteAddr
+	}
+	if userID, err := userIDFromRequest(r); err == nil {
+		viewer = userID.String()
+	}
+	h.Views.Record(product.ID, viewer, r.UserAgent())
+}
+
+// writeProduct presents a single product and writes it as the response.
+func (h *ProductHandle) writeProduct(w http.ResponseWriter, r *http.Request, product *entity.Product) {
+	products := []entity.Product{*product}
+	if err := h.present(r, products); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", products[0].Locale)
+	w.Header().Set("Vary", "Accept-Language")
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(products[0])
+}
+
+// present localizes products for the request and applies the promotions
+// running now.
+func (h *ProductHandle) present(r *http.Request, products []entity.Product) error {
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.ID.String()
+	}
+	translations, err := h.TranslationDB.FindByProductIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	now := h.Now()
+	promotions, err := h.PromotionDB.FindRunning(now)
+	if err != nil {
+		return err
+	}
+
+	acceptLanguage := r.Header.Get("Accept-Language")
+	for i := range products {
+		products[i].Localize(acceptLanguage, h.DefaultLocale, translations[ids[i]])
+		products[i].ApplyPromotions(promotions, now)
+	}
+	return nil
+}
+
+func bundleComponents(input []dto.BundleComponentInput) ([]entity.BundleComponent, error) {
+	components := make([]entity.BundleComponent, len(input))
+	for i, c := range input {
+		id, err := entityPkg.ParseID(c.ProductID)
+		if err != nil {
+			return nil, entity.ErrComponentInvalid
+		}
+		components[i] = entity.BundleComponent{ProductID: id, Quantity: c.Quantity}
+	}
+	return components, nil
 }
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+// MergeProduct godoc
+// @Summary Merge products
+// @Description Merge the source products into the product. Rules resolve each field (name, description, price, available, tags, reorder_point, tax_class_id, gtin) with target, the ID of a merged product, longest, min, max, any or union; tags default to union and the other fields to target. Reviews, stock, stock transfers in transit, carts, wishlists, suppliers, translations, views, slugs, promotions and coupons move to the product, the sources are deleted and their IDs redirect to it.
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Target product ID"
+// @Param request body dto.MergeProductsInput true "Merge request"
+// @Success 200 {object} dto.MergeProductsOutput
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /products/{id}/merge [post]
+// @Security ApiKeyAuth
+func (h *ProductHandle) MergeProduct(w http.ResponseWriter, r *http.Request) {
+	var input dto.MergeProductsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	product, merge, err := h.ProductDB.Merge(chi.URLParam(r, "id"), input.SourceIDs, input.Rules, &userID)
+	if err != nil {
+		writeMergeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.MergeProductsOutput{Product: product, Merge: merge})
+}
+
+// GetProductMerges godoc
+// @Summary List the merges into a product
+// @Description List the merges of other products into the product, newest first
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {array} entity.ProductMerge
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/merges [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetProductMerges(w http.ResponseWriter, r *http.Request) {
+	merges, err := h.ProductDB.FindMerges(chi.URLParam(r, "id"))
+	if err != nil {
+		writeMergeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(merges)
+}
+
+func writeMergeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case errors.Is(err, database.ErrSlugUnavailable):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, entity.ErrMergeSourcesRequired),
+		errors.Is(err, entity.ErrMergeSourceInvalid),
+		errors.Is(err, entity.ErrMergeRuleInvalid):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
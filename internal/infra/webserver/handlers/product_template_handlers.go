@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+type ProductTemplateHandle struct {
+	TemplateDB database.ProductTemplateInterface
+}
+
+func NewProductTemplateHandle(templateDB database.ProductTemplateInterface) *ProductTemplateHandle {
+	return &ProductTemplateHandle{
+		TemplateDB: templateDB,
+	}
+}
+
+// CreateProductTemplate godoc
+// @Summary Create a product template
+// @Description Create a template whose price, description, availability and tags pre-fill the products created with POST /products?template={id}. Names are unique
+// @Tags product-templates
+// @Accept  json
+// @Produce  json
+// @Param request body dto.ProductTemplateInput true "Template request"
+// @Success 201 {object} entity.ProductTemplate
+// @Failure 400 {object} Error
+// @Failure 409
+// @Router /product-templates [post]
+// @Security ApiKeyAuth
+func (h *ProductTemplateHandle) CreateProductTemplate(w http.ResponseWriter, r *http.Request) {
+	var input dto.ProductTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	template, err := entity.NewProductTemplate(input.Name, input.Price, input.Description, input.Available, input.Tags)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.TemplateDB.Create(template); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// GetProductTemplates godoc
+// @Summary List product templates
+// @Description List all product templates by name
+// @Tags product-templates
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.ProductTemplate
+// @Failure 500
+// @Router /product-templates [get]
+// @Security ApiKeyAuth
+func (h *ProductTemplateHandle) GetProductTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.TemplateDB.FindAll()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
+
+// GetProductTemplate godoc
+// @Summary Get a product template
+// @Description Get a product template
+// @Tags product-templates
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Template ID"
+// @Success 200 {object} entity.ProductTemplate
+// @Failure 404
+// @Router /product-templates/{id} [get]
+// @Security ApiKeyAuth
+func (h *ProductTemplateHandle) GetProductTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := h.TemplateDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// UpdateProductTemplate godoc
+// @Summary Update a product template
+// @Description Update a product template. Products already created from it are left as they are
+// @Tags product-templates
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Template ID"
+// @Param request body dto.ProductTemplateInput true "Template request"
+// @Success 200 {object} entity.ProductTemplate
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409
+// @Router /product-templates/{id} [put]
+// @Security ApiKeyAuth
+func (h *ProductTemplateHandle) UpdateProductTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := h.TemplateDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.ProductTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	template.Name = input.Name
+	template.Price = input.Price
+	template.Description = input.Description
+	template.Available = input.Available
+	template.Tags = entity.NormalizeTags(input.Tags)
+	if err := template.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.TemplateDB.Update(template); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// DeleteProductTemplate godoc
+// @Summary Delete a product template
+// @Description Delete a product template. Products created from it are kept
+// @Tags product-templates
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Template ID"
+// @Success 200
+// @Failure 404
+// @Failure 500
+// @Router /product-templates/{id} [delete]
+// @Security ApiKeyAuth
+func (h *ProductTemplateHandle) DeleteProductTemplate(w http.ResponseWriter, r *http.Request) {
+	err := h.TemplateDB.Delete(chi.URLParam(r, "id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+)
+
+const (
+	defaultPopularWindow = "7d"
+	defaultStatsWindow   = "30d"
+	defaultPopularLimit  = 10
+	maxPopularLimit      = 100
+)
+
+type ProductViewHandle struct {
+	ViewDB    database.ProductViewInterface
+	ProductDB database.ProductInterface
+	Now       func() time.Time
+}
+
+func NewProductViewHandle(viewDB database.ProductViewInterface, productDB database.ProductInterface) *ProductViewHandle {
+	return &ProductViewHandle{
+		ViewDB:    viewDB,
+		ProductDB: productDB,
+		Now:       time.Now,
+	}
+}
+
+// GetPopularProducts godoc
+// @Summary List popular products
+// @Description List the most viewed products over the last days. Views are counted in memory and written in batches, so the latest ones may not be included yet
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param window query string false "Number of days, e.g. 7d (default) or 30d"
+// @Param limit query int false "Number of products, 10 by default and at most 100"
+// @Success 200 {object} []entity.PopularProduct
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /products/popular [get]
+// @Security ApiKeyAuth
+func (h *ProductViewHandle) GetPopularProducts(w http.ResponseWriter, r *http.Request) {
+	days, ok := parseViewWindow(w, r, defaultPopularWindow)
+	if !ok {
+		return
+	}
+
+	limit := defaultPopularLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: "limit must be a positive number"})
+			return
+		}
+		limit = min(parsed, maxPopularLimit)
+	}
+
+	popular, err := h.ViewDB.FindPopular(entity.WindowStart(h.Now(), days), limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(popular)
+}
+
+// GetProductStats godoc
+// @Summary Get the view statistics of a product
+// @Description Get the daily views of a product over the last days, bots and repeat views excluded
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param window query string false "Number of days, e.g. 30d (default)"
+// @Success 200 {object} entity.ViewStats
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/stats [get]
+// @Security ApiKeyAuth
+func (h *ProductViewHandle) GetProductStats(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	days, ok := parseViewWindow(w, r, defaultStatsWindow)
+	if !ok {
+		return
+	}
+
+	now := h.Now()
+	daily, err := h.ViewDB.FindDaily(product.ID.String(), entity.WindowStart(now, days))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entity.NewViewStats(product.ID, daily, now, days))
+}
+
+func parseViewWindow(w http.ResponseWriter, r *http.Request, fallback string) (int, bool) {
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = fallback
+	}
+	days, err := entity.ParseViewWindow(window)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return 0, false
+	}
+	return days, true
+}
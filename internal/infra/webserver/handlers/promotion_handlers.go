@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/go-chi/chi"
+)
+
+type PromotionHandle struct {
+	PromotionDB database.PromotionInterface
+}
+
+func NewPromotionHandle(db database.PromotionInterface) *PromotionHandle {
+	return &PromotionHandle{
+		PromotionDB: db,
+	}
+}
+
+// CreatePromotion godoc
+// @Summary Create a promotion
+// @Description Create an automatic discount for products matching its targets
+// @Tags promotions
+// @Accept  json
+// @Produce  json
+// @Param request body dto.CreatePromotionInput true "Promotion request"
+// @Success 201 {object} entity.Promotion
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /promotions [post]
+// @Security ApiKeyAuth
+func (h *PromotionHandle) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreatePromotionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	promotion, err := entity.NewPromotion(input.Name, input.Kind, input.Value, input.StartsAt, input.EndsAt)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := applyPromotionInput(promotion, input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	if err := h.PromotionDB.Save(promotion); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(promotion)
+}
+
+// GetPromotions godoc
+// @Summary List promotions
+// @Description List all promotions, running or not
+// @Tags promotions
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.Promotion
+// @Failure 500
+// @Router /promotions [get]
+// @Security ApiKeyAuth
+func (h *PromotionHandle) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.PromotionDB.FindAll()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(promotions)
+}
+
+// GetPromotion godoc
+// @Summary Get a promotion
+// @Description Get a promotion
+// @Tags promotions
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} entity.Promotion
+// @Failure 404
+// @Router /promotions/{id} [get]
+// @Security ApiKeyAuth
+func (h *PromotionHandle) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	promotion, err := h.PromotionDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(promotion)
+}
+
+// UpdatePromotion godoc
+// @Summary Update a promotion
+// @Description Update a promotion
+// @Tags promotions
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Promotion ID"
+// @Param request body dto.CreatePromotionInput true "Promotion request"
+// @Success 200 {object} entity.Promotion
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /promotions/{id} [put]
+// @Security ApiKeyAuth
+func (h *PromotionHandle) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	promotion, err := h.PromotionDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.CreatePromotionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	promotion.Name = input.Name
+	promotion.Kind = input.Kind
+	promotion.Value = input.Value
+	promotion.SetWindow(input.StartsAt, input.EndsAt)
+	if err := applyPromotionInput(promotion, input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	if err := h.PromotionDB.Update(promotion); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(promotion)
+}
+
+// DeletePromotion godoc
+// @Summary Delete a promotion
+// @Description Delete a promotion
+// @Tags promotions
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Promotion ID"
+// @Success 200
+// @Failure 404
+// @Router /promotions/{id} [delete]
+// @Security ApiKeyAuth
+func (h *PromotionHandle) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	if err := h.PromotionDB.Delete(chi.URLParam(r, "id")); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func applyPromotionInput(promotion *entity.Promotion, input dto.CreatePromotionInput) error {
+	productIDs := entity.StringList{}
+	for _, id := range input.ProductIDs {
+		parsed, err := entityPkg.ParseID(id)
+		if err != nil {
+			return entity.ErrInvalidID
+		}
+		productIDs = append(productIDs, parsed.String())
+	}
+
+	promotion.ProductIDs = productIDs
+	promotion.Tags = entity.NormalizeTags(input.Tags)
+	promotion.MinPrice = input.MinPrice
+	promotion.MaxPrice = input.MaxPrice
+	promotion.Priority = input.Priority
+	promotion.Stackable = input.Stackable
+	return promotion.Validate()
+}
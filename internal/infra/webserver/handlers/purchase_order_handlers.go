@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+type PurchaseOrderHandle struct {
+	PurchaseOrderDB database.PurchaseOrderInterface
+	SupplierDB      database.SupplierInterface
+	ProductDB       database.ProductInterface
+	Now             func() time.Time
+}
+
+func NewPurchaseOrderHandle(purchaseOrderDB database.PurchaseOrderInterface, supplierDB database.SupplierInterface, productDB database.ProductInterface) *PurchaseOrderHandle {
+	return &PurchaseOrderHandle{
+		PurchaseOrderDB: purchaseOrderDB,
+		SupplierDB:      supplierDB,
+		ProductDB:       productDB,
+		Now:             time.Now,
+	}
+}
+
+// CreatePurchaseOrder godoc
+// @Summary Create a purchase order
+// @Description Create a draft purchase order with a supplier
+// @Tags purchase orders
+// @Accept  json
+// @Produce  json
+// @Param request body dto.PurchaseOrderInput true "Purchase order request"
+// @Success 201 {object} entity.PurchaseOrder
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500
+// @Router /purchase-orders [post]
+// @Security ApiKeyAuth
+func (h *PurchaseOrderHandle) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	var input dto.PurchaseOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	supplier, err := h.SupplierDB.FindByID(input.SupplierID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(Error{Message: "supplier not found"})
+		return
+	}
+
+	order := entity.NewPurchaseOrder(supplier.ID, input.Notes)
+	if err := h.setLines(order, input.Lines); err != nil {
+		writePurchaseOrderError(w, err)
+		return
+	}
+	if err := h.PurchaseOrderDB.Save(order); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetPurchaseOrders godoc
+// @Summary List purchase orders
+// @Description List purchase orders, newest first
+// @Tags purchase orders
+// @Accept  json
+// @Produce  json
+// @Param supplier_id query string false "Supplier ID"
+// @Param status query string false "Status: draft, sent, partially_received or received"
+// @Success 200 {object} []entity.PurchaseOrder
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /purchase-orders [get]
+// @Security ApiKeyAuth
+func (h *PurchaseOrderHandle) GetPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	filter := database.PurchaseOrderFilter{
+		SupplierID: r.URL.Query().Get("supplier_id"),
+		Status:     r.URL.Query().Get("status"),
+	}
+	if filter.Status != "" && !entity.IsPurchaseOrderStatus(filter.Status) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrPurchaseOrderStatus.Error()})
+		return
+	}
+
+	orders, err := h.PurchaseOrderDB.FindAll(filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(orders)
+}
+
+// GetPurchaseOrder godoc
+// @Summary Get a purchase order
+// @Description Get a purchase order with its lines
+// @Tags purchase orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} entity.PurchaseOrder
+// @Failure 404
+// @Router /purchase-orders/{id} [get]
+// @Security ApiKeyAuth
+func (h *PurchaseOrderHandle) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := h.PurchaseOrderDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+// UpdatePurchaseOrder godoc
+// @Summary Update a purchase order
+// @Description Replace the notes and lines of a draft purchase order. The supplier cannot be changed
+// @Tags purchase orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Purchase order ID"
+// @Param request body dto.PurchaseOrderInput true "Purchase order request"
+// @Success 200 {object} entity.PurchaseOrder
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /purchase-orders/{id} [put]
+// @Security ApiKeyAuth
+func (h *PurchaseOrderHandle) UpdatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := h.PurchaseOrderDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.PurchaseOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := order.ClearLines(); err != nil {
+		writePurchaseOrderError(w, err)
+		return
+	}
+	order.Notes = input.Notes
+	order.UpdatedAt = h.Now()
+	if err := h.setLines(order, input.Lines); err != nil {
+		writePurchaseOrderError(w, err)
+		return
+	}
+	if err := h.PurchaseOrderDB.Save(order); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+// SendPurchaseOrder godoc
+// @Summary Send a purchase order
+// @Description Mark a draft purchase order as sent to the supplier. Its lines cannot be changed afterwards
+// @Tags purchase orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} entity.PurchaseOrder
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /purchase-orders/{id}/send [post]
+// @Security ApiKeyAuth
+func (h *PurchaseOrderHandle) SendPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := h.PurchaseOrderDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := order.Send(h.Now()); err != nil {
+		writePurchaseOrderError(w, err)
+		return
+	}
+	if err := h.PurchaseOrderDB.Save(order); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+// ReceivePurchaseOrder godoc
+// @Summary Receive a purchase order
+// @Description Record the quantities delivered for a sent purchase order. The unit cost of each received line becomes the cost price of the product and of its supplier link
+// @Tags purchase orders
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Purchase order ID"
+// @Param request body dto.ReceivePurchaseOrderInput true "Receive request"
+// @Success 200 {object} entity.PurchaseOrder
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /purchase-orders/{id}/receive [post]
+// @Security ApiKeyAuth
+func (h *PurchaseOrderHandle) ReceivePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	var input dto.ReceivePurchaseOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	quantities := make(map[entityPkg.ID]int, len(input.Lines))
+	for _, line := range input.Lines {
+		productID, err := entityPkg.ParseID(line.ProductID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: entity.ErrPurchaseOrderLineNotFound.Error()})
+			return
+		}
+		quantities[productID] += line.Quantity
+	}
+
+	order, err := h.PurchaseOrderDB.Receive(chi.URLParam(r, "id"), quantities, h.Now())
+	if err != nil {
+		writePurchaseOrderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+func (h *PurchaseOrderHandle) setLines(order *entity.PurchaseOrder, lines []dto.PurchaseOrderLineInput) error {
+	for _, line := range lines {
+		product, err := h.ProductDB.FindByID(line.ProductID)
+		if err != nil {
+			return database.ErrProductNotFound
+		}
+		if err := order.SetLine(product.ID, line.Quantity, line.UnitCost); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePurchaseOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case errors.Is(err, database.ErrProductNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, entity.ErrPurchaseOrderNotDraft),
+		errors.Is(err, entity.ErrPurchaseOrderNotSent),
+		errors.Is(err, entity.ErrPurchaseOrderStatus):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, entity.ErrPurchaseOrderEmpty),
+		errors.Is(err, entity.ErrPurchaseOrderLineNotFound),
+		errors.Is(err, entity.ErrReceiveExceedsOrdered),
+		errors.Is(err, entity.ErrQuantityInvalid),
+		errors.Is(err, entity.ErrCostInvalid):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+)
+
+type ReviewHandle struct {
+	ReviewDB  database.ReviewInterface
+	ProductDB database.ProductInterface
+}
+
+func NewReviewHandle(reviewDB database.ReviewInterface, productDB database.ProductInterface) *ReviewHandle {
+	return &ReviewHandle{
+		ReviewDB:  reviewDB,
+		ProductDB: productDB,
+	}
+}
+
+// CreateReview godoc
+// @Summary Review a product
+// @Description Review a product as the authenticated user. Each user reviews a product once
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param request body dto.ReviewInput true "Review request"
+// @Success 201 {object} entity.Review
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /products/{id}/reviews [post]
+// @Security ApiKeyAuth
+func (h *ReviewHandle) CreateReview(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.ReviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	review, err := entity.NewReview(product.ID, userID, input.Rating, input.Title, input.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.ReviewDB.Create(review); err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(review)
+}
+
+// GetReviews godoc
+// @Summary List the reviews of a product
+// @Description List the reviews of a product, newest first. Hidden reviews are only listed to admins
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} []entity.Review
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/reviews [get]
+// @Security ApiKeyAuth
+func (h *ReviewHandle) GetReviews(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil {
+		page = 0
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	reviews, err := h.ReviewDB.FindByProductID(product.ID.String(), isAdmin(r), page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// UpdateReview godoc
+// @Summary Edit a review
+// @Description Edit a review written by the authenticated user
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param review_id path string true "Review ID"
+// @Param request body dto.ReviewInput true "Review request"
+// @Success 200 {object} entity.Review
+// @Failure 400 {object} Error
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/reviews/{review_id} [put]
+// @Security ApiKeyAuth
+func (h *ReviewHandle) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	review, ok := h.authoredReview(w, r, false)
+	if !ok {
+		return
+	}
+
+	var input dto.ReviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := review.Edit(input.Rating, input.Title, input.Body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.ReviewDB.Update(review); err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(review)
+}
+
+// DeleteReview godoc
+// @Summary Delete a review
+// @Description Delete a review written by the authenticated user. Admins can delete any review
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param review_id path string true "Review ID"
+// @Success 200
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/reviews/{review_id} [delete]
+// @Security ApiKeyAuth
+func (h *ReviewHandle) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	review, ok := h.authoredReview(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.ReviewDB.Delete(review.ID.String()); err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// ModerateReview godoc
+// @Summary Moderate a review
+// @Description Hide an abusive review, or show it again. Hidden reviews do not count in the product rating. Admin only
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Param review_id path string true "Review ID"
+// @Param request body dto.ModerateReviewInput true "Moderation request"
+// @Success 200 {object} entity.Review
+// @Failure 400 {object} Error
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /admin/reviews/{review_id}/moderation [put]
+// @Security ApiKeyAuth
+func (h *ReviewHandle) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	review, err := h.ReviewDB.FindByID(chi.URLParam(r, "review_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.ModerateReviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := review.Moderate(input.Hidden, input.Reason); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.ReviewDB.Update(review); err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(review)
+}
+
+// authoredReview loads the review in the URL and checks that the
+// authenticated user wrote it, or is an admin when allowAdmin is set.
+func (h *ReviewHandle) authoredReview(w http.ResponseWriter, r *http.Request, allowAdmin bool) (*entity.Review, bool) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	review, err := h.ReviewDB.FindByID(chi.URLParam(r, "review_id"))
+	if err != nil || review.ProductID.String() != chi.URLParam(r, "id") {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+	if review.UserID != userID && !(allowAdmin && isAdmin(r)) {
+		w.WriteHeader(http.StatusForbidden)
+		return nil, false
+	}
+	return review, true
+}
+
+func writeReviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrReviewDuplicated):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, database.ErrProductNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
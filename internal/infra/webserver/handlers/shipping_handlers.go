@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+type ShippingHandle struct {
+	ShippingDB database.ShippingInterface
+	ProductDB  database.ProductInterface
+}
+
+func NewShippingHandle(shippingDB database.ShippingInterface, productDB database.ProductInterface) *ShippingHandle {
+	return &ShippingHandle{
+		ShippingDB: shippingDB,
+		ProductDB:  productDB,
+	}
+}
+
+// CreateShippingZone godoc
+// @Summary Create a shipping zone
+// @Description Create a shipping zone with its methods. Each method prices shipments by chargeable weight, the greater of the actual weight and the volume divided by volumetric_divisor (cm³ per kg, default 5000), with rates up to a weight in weight_unit (kg, the default, or lb). Names are unique
+// @Tags shipping
+// @Accept  json
+// @Produce  json
+// @Param request body dto.ShippingZoneInput true "Zone request"
+// @Success 201 {object} entity.ShippingZone
+// @Failure 400 {object} Error
+// @Failure 409
+// @Router /shipping/zones [post]
+// @Security ApiKeyAuth
+func (h *ShippingHandle) CreateShippingZone(w http.ResponseWriter, r *http.Request) {
+	var input dto.ShippingZoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	zone, err := entity.NewShippingZone(input.Name, shippingMethods(input.Methods))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.ShippingDB.CreateZone(zone); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(zone)
+}
+
+// GetShippingZones godoc
+// @Summary List shipping zones
+// @Description List all shipping zones by name, with their methods and rates
+// @Tags shipping
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.ShippingZone
+// @Failure 500
+// @Router /shipping/zones [get]
+// @Security ApiKeyAuth
+func (h *ShippingHandle) GetShippingZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := h.ShippingDB.FindZones()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zones)
+}
+
+// GetShippingZone godoc
+// @Summary Get a shipping zone
+// @Description Get a shipping zone with its methods and rates
+// @Tags shipping
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Zone ID"
+// @Success 200 {object} entity.ShippingZone
+// @Failure 404
+// @Router /shipping/zones/{id} [get]
+// @Security ApiKeyAuth
+func (h *ShippingHandle) GetShippingZone(w http.ResponseWriter, r *http.Request) {
+	zone, err := h.ShippingDB.FindZoneByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zone)
+}
+
+// UpdateShippingZone godoc
+// @Summary Update a shipping zone
+// @Description Update a shipping zone, replacing its methods and rates
+// @Tags shipping
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Zone ID"
+// @Param request body dto.ShippingZoneInput true "Zone request"
+// @Success 200 {object} entity.ShippingZone
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409
+// @Router /shipping/zones/{id} [put]
+// @Security ApiKeyAuth
+func (h *ShippingHandle) UpdateShippingZone(w http.ResponseWriter, r *http.Request) {
+	zone, err := h.ShippingDB.FindZoneByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.ShippingZoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	zone.Name = input.Name
+	zone.SetMethods(shippingMethods(input.Methods))
+	if err := zone.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.ShippingDB.UpdateZone(zone); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zone)
+}
+
+// DeleteShippingZone godoc
+// @Summary Delete a shipping zone
+// @Description Delete a shipping zone with its methods and rates
+// @Tags shipping
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Zone ID"
+// @Success 200
+// @Failure 404
+// @Failure 500
+// @Router /shipping/zones/{id} [delete]
+// @Security ApiKeyAuth
+func (h *ShippingHandle) DeleteShippingZone(w http.ResponseWriter, r *http.Request) {
+	err := h.ShippingDB.DeleteZone(chi.URLParam(r, "id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// QuoteShipping godoc
+// @Summary Quote shipping
+// @Description Price the shipment of products to a zone with each of its methods, cheapest first. Products are weighed and measured in their own units and converted; methods whose rates stop below the chargeable weight are left out
+// @Tags shipping
+// @Accept  json
+// @Produce  json
+// @Param request body dto.ShippingQuoteInput true "Quote request"
+// @Success 200 {object} entity.ShippingQuote
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /shipping/quote [post]
+// @Security ApiKeyAuth
+func (h *ShippingHandle) QuoteShipping(w http.ResponseWriter, r *http.Request) {
+	var input dto.ShippingQuoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || len(input.Lines) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	zone, err := h.ShippingDB.FindZoneByID(input.ZoneID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	lines := make([]entity.ShippingLine, len(input.Lines))
+	for i, line := range input.Lines {
+		product, err := h.ProductDB.FindByID(line.ProductID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: "product not found: " + line.ProductID})
+			return
+		}
+		if product.Weight.Value == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: entity.ErrWeightMissing.Error() + ": " + line.ProductID})
+			return
+		}
+		lines[i] = entity.ShippingLine{Product: *product, Quantity: line.Quantity}
+	}
+
+	quote, err := zone.Quote(lines)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(quote)
+}
+
+func shippingMethods(inputs []dto.ShippingMethodInput) []entity.ShippingMethod {
+	methods := make([]entity.ShippingMethod, len(inputs))
+	for i, input := range inputs {
+		rates := make([]entity.ShippingRate, len(input.Rates))
+		for j, rate := range input.Rates {
+			rates[j] = entity.ShippingRate{UpTo: rate.UpTo, Price: rate.Price}
+		}
+		methods[i] = entity.ShippingMethod{
+			Name:              input.Name,
+			DeliveryDays:      input.DeliveryDays,
+			WeightUnit:        input.WeightUnit,
+			VolumetricDivisor: input.VolumetricDivisor,
+			Rates:             rates,
+		}
+	}
+	return methods
+}
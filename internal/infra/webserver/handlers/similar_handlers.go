@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/go-chi/chi"
+)
+
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+)
+
+// GetSimilarProducts godoc
+// @Summary Similar products
+// @Description List the products most similar to a product, the closest first. The score weighs the TF-IDF similarity of the names and descriptions for 0.6, the proximity of the prices for 0.2 and the shared tags for 0.2; products sharing neither words nor tags are left out
+// @Tags products
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param limit query int false "Number of products, 10 by default and at most 50"
+// @Success 200 {array} entity.SimilarProduct
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/similar [get]
+// @Security ApiKeyAuth
+func (h *ProductHandle) GetSimilarProducts(w http.ResponseWriter, r *http.Request) {
+	limit := defaultSimilarLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: "limit must be a positive number"})
+			return
+		}
+		limit = min(parsed, maxSimilarLimit)
+	}
+
+	if h.Similar == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	similar, ok := h.Similar.Similar(chi.URLParam(r, "id"), limit)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	// The index only holds what it compares: reload the products for their
+	// current ratings, then present them as the other product listings do.
+	ids := make([]string, len(similar))
+	for i, candidate := range similar {
+		ids[i] = candidate.Product.ID.String()
+	}
+	found, err := h.ProductDB.FindByIDs(ids)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	byID := make(map[string]entity.Product, len(found))
+	for _, product := range found {
+		byID[product.ID.String()] = product
+	}
+	loaded := make([]entity.SimilarProduct, 0, len(similar))
+	products := make([]entity.Product, 0, len(similar))
+	for _, candidate := range similar {
+		if product, ok := byID[candidate.Product.ID.String()]; ok {
+			loaded = append(loaded, candidate)
+			products = append(products, product)
+		}
+	}
+	if err := h.present(r, products); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	for i := range loaded {
+		loaded[i].Product = products[i]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(loaded)
+}
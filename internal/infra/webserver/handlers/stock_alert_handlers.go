@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+type StockAlertHandle struct {
+	AlertDB   database.StockAlertInterface
+	ProductDB database.ProductInterface
+	Now       func() time.Time
+}
+
+func NewStockAlertHandle(alertDB database.StockAlertInterface, productDB database.ProductInterface) *StockAlertHandle {
+	return &StockAlertHandle{
+		AlertDB:   alertDB,
+		ProductDB: productDB,
+		Now:       time.Now,
+	}
+}
+
+// SetReorderPoint godoc
+// @Summary Set the reorder point of a product
+// @Description Set the stock level under which the product is alerted as low on stock. Zero disables the alerts
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param request body dto.ReorderPointInput true "Reorder point"
+// @Success 200
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/reorder_point [put]
+// @Security ApiKeyAuth
+func (h *StockAlertHandle) SetReorderPoint(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.ReorderPointInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := product.SetReorderPoint(input.ReorderPoint); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.ProductDB.Update(product); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetAlerts godoc
+// @Summary List stock alerts
+// @Description List low-stock alerts, newest first
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Param status query string false "Status: open, acknowledged or resolved"
+// @Success 200 {object} []entity.StockAlert
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /alerts [get]
+// @Security ApiKeyAuth
+func (h *StockAlertHandle) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && !entity.IsAlertStatus(status) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrAlertStatusInvalid.Error()})
+		return
+	}
+
+	alerts, err := h.AlertDB.FindAll(status)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(alerts)
+}
+
+// GetAlert godoc
+// @Summary Get a stock alert
+// @Description Get a low-stock alert
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} entity.StockAlert
+// @Failure 404
+// @Router /alerts/{id} [get]
+// @Security ApiKeyAuth
+func (h *StockAlertHandle) GetAlert(w http.ResponseWriter, r *http.Request) {
+	alert, err := h.AlertDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(alert)
+}
+
+// AcknowledgeAlert godoc
+// @Summary Acknowledge a stock alert
+// @Description Acknowledge an open alert as the authenticated user. It is resolved once the stock recovers
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} entity.StockAlert
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /alerts/{id}/acknowledge [post]
+// @Security ApiKeyAuth
+func (h *StockAlertHandle) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	alert, err := h.AlertDB.Acknowledge(chi.URLParam(r, "id"), userID, h.Now())
+	if err != nil {
+		writeStockAlertError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(alert)
+}
+
+func writeStockAlertError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case errors.Is(err, entity.ErrAlertNotOpen):
+		w.WriteHeader(http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
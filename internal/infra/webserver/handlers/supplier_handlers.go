@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+type SupplierHandle struct {
+	SupplierDB database.SupplierInterface
+	ProductDB  database.ProductInterface
+}
+
+func NewSupplierHandle(supplierDB database.SupplierInterface, productDB database.ProductInterface) *SupplierHandle {
+	return &SupplierHandle{
+		SupplierDB: supplierDB,
+		ProductDB:  productDB,
+	}
+}
+
+// CreateSupplier godoc
+// @Summary Create a supplier
+// @Description Create a supplier. Names are unique
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param request body dto.SupplierInput true "Supplier request"
+// @Success 201 {object} entity.Supplier
+// @Failure 400 {object} Error
+// @Failure 409
+// @Router /suppliers [post]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	var input dto.SupplierInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	supplier, err := entity.NewSupplier(input.Name, input.Email, input.Phone)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.SupplierDB.Create(supplier); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// GetSuppliers godoc
+// @Summary List suppliers
+// @Description List all suppliers by name
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.Supplier
+// @Failure 500
+// @Router /suppliers [get]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) GetSuppliers(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.SupplierDB.FindAll()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(suppliers)
+}
+
+// GetSupplier godoc
+// @Summary Get a supplier
+// @Description Get a supplier
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} entity.Supplier
+// @Failure 404
+// @Router /suppliers/{id} [get]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	supplier, err := h.SupplierDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// UpdateSupplier godoc
+// @Summary Update a supplier
+// @Description Update a supplier
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Supplier ID"
+// @Param request body dto.SupplierInput true "Supplier request"
+// @Success 200 {object} entity.Supplier
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409
+// @Router /suppliers/{id} [put]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	supplier, err := h.SupplierDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.SupplierInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	supplier.Name = input.Name
+	supplier.Email = input.Email
+	supplier.Phone = input.Phone
+	if err := supplier.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.SupplierDB.Update(supplier); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// DeleteSupplier godoc
+// @Summary Delete a supplier
+// @Description Delete a supplier and its product links. Its purchase orders are kept
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Supplier ID"
+// @Success 200
+// @Failure 404
+// @Failure 500
+// @Router /suppliers/{id} [delete]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+	if err := h.SupplierDB.Delete(chi.URLParam(r, "id")); err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetSupplierProducts godoc
+// @Summary List the products of a supplier
+// @Description List the products a supplier sells, with its SKU, cost price and lead time
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} []entity.ProductSupplier
+// @Failure 404
+// @Failure 500
+// @Router /suppliers/{id}/products [get]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) GetSupplierProducts(w http.ResponseWriter, r *http.Request) {
+	supplier, err := h.SupplierDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	links, err := h.SupplierDB.FindLinksBySupplierID(supplier.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+// GetProductSuppliers godoc
+// @Summary List the suppliers of a product
+// @Description List the suppliers of a product, cheapest first
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} []entity.ProductSupplier
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/suppliers [get]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) GetProductSuppliers(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	links, err := h.SupplierDB.FindLinksByProductID(product.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+// PutProductSupplier godoc
+// @Summary Link a supplier to a product
+// @Description Create or replace the SKU, cost price and lead time of a supplier for a product
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Param request body dto.ProductSupplierInput true "Product supplier request"
+// @Success 200 {object} entity.ProductSupplier
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/suppliers/{supplier_id} [put]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) PutProductSupplier(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	supplier, err := h.SupplierDB.FindByID(chi.URLParam(r, "supplier_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.ProductSupplierInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	link, err := entity.NewProductSupplier(product.ID, supplier.ID, input.SupplierSKU, input.CostPrice, input.LeadTimeDays)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.SupplierDB.SaveLink(link); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(link)
+}
+
+// DeleteProductSupplier godoc
+// @Summary Unlink a supplier from a product
+// @Description Unlink a supplier from a product
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Success 200
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/suppliers/{supplier_id} [delete]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) DeleteProductSupplier(w http.ResponseWriter, r *http.Request) {
+	if err := h.SupplierDB.DeleteLink(chi.URLParam(r, "id"), chi.URLParam(r, "supplier_id")); err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetMargins godoc
+// @Summary Margin report
+// @Description Compare the price of every product to its cost: the cost of the last received purchase order, or the cheapest supplier cost when nothing was received yet
+// @Tags suppliers
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.Margin
+// @Failure 500
+// @Router /reports/margins [get]
+// @Security ApiKeyAuth
+func (h *SupplierHandle) GetMargins(w http.ResponseWriter, r *http.Request) {
+	products, err := h.ProductDB.FindAll(0, 0, "")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	links, err := h.SupplierDB.FindAllLinks()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	margins := make([]entity.Margin, 0, len(products))
+	for _, product := range products {
+		margins = append(margins, entity.ComputeMargin(product, links[product.ID.String()]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(margins)
+}
+
+func writeSupplierError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+}
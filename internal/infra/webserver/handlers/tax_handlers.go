@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+)
+
+type TaxHandle struct {
+	TaxDB            database.TaxInterface
+	ProductDB        database.ProductInterface
+	PromotionDB      database.PromotionInterface
+	PricesIncludeTax bool
+	Now              func() time.Time
+}
+
+func NewTaxHandle(taxDB database.TaxInterface, productDB database.ProductInterface, promotionDB database.PromotionInterface, pricesIncludeTax bool) *TaxHandle {
+	return &TaxHandle{
+		TaxDB:            taxDB,
+		ProductDB:        productDB,
+		PromotionDB:      promotionDB,
+		PricesIncludeTax: pricesIncludeTax,
+		Now:              time.Now,
+	}
+}
+
+// CreateTaxClass godoc
+// @Summary Create a tax class
+// @Description Create a tax class that can be assigned to products
+// @Tags taxes
+// @Accept  json
+// @Produce  json
+// @Param request body dto.CreateTaxClassInput true "Tax class request"
+// @Success 201 {object} entity.TaxClass
+// @Failure 400 {object} Error
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /tax/classes [post]
+// @Security ApiKeyAuth
+func (h *TaxHandle) CreateTaxClass(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateTaxClassInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	class, err := entity.NewTaxClass(input.Name)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	err = h.TaxDB.SaveClass(class)
+	if errors.Is(err, entity.ErrTaxClassTaken) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(class)
+}
+
+// GetTaxClasses godoc
+// @Summary List tax classes
+// @Description List tax classes
+// @Tags taxes
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.TaxClass
+// @Failure 500
+// @Router /tax/classes [get]
+// @Security ApiKeyAuth
+func (h *TaxHandle) GetTaxClasses(w http.ResponseWriter, r *http.Request) {
+	classes, err := h.TaxDB.FindClasses()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(classes)
+}
+
+// CreateTaxRate godoc
+// @Summary Create a tax rate
+// @Description Add a rate for a tax class in a jurisdiction
+// @Tags taxes
+// @Accept  json
+// @Produce  json
+// @Param request body dto.CreateTaxRateInput true "Tax rate request"
+// @Success 201 {object} entity.TaxRate
+// @Failure 400 {object} Error
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /tax/rates [post]
+// @Security ApiKeyAuth
+func (h *TaxHandle) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateTaxRateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	class, err := h.TaxDB.FindClassByID(input.TaxClassID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: "tax class not found"})
+		return
+	}
+
+	rate, err := entity.NewTaxRate(class.ID, input.Jurisdiction, input.Name, input.Rate, input.EffectiveFrom, input.EffectiveTo)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	err = h.TaxDB.SaveRate(rate)
+	if errors.Is(err, entity.ErrTaxRateTaken) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rate)
+}
+
+// GetTaxRates godoc
+// @Summary List tax rates
+// @Description List tax rates, optionally filtered by region and tax class
+// @Tags taxes
+// @Accept  json
+// @Produce  json
+// @Param region query string false "Jurisdiction"
+// @Param tax_class_id query string false "Tax class ID"
+// @Success 200 {object} []entity.TaxRate
+// @Failure 500
+// @Router /tax/rates [get]
+// @Security ApiKeyAuth
+func (h *TaxHandle) GetTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.TaxDB.FindRates(r.URL.Query().Get("region"), r.URL.Query().Get("tax_class_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(rates)
+}
+
+// ImportTaxRates godoc
+// @Summary Import tax rates
+// @Description Import rates from CSV with the header tax_class,jurisdiction,name,rate,effective_from,effective_to
+// @Tags taxes
+// @Accept  text/csv
+// @Produce  json
+// @Success 201 {object} dto.ImportTaxRatesOutput
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /tax/rates/import [post]
+// @Security ApiKeyAuth
+func (h *TaxHandle) ImportTaxRates(w http.ResponseWriter, r *http.Request) {
+	imported, err := h.TaxDB.ImportRatesCSV(r.Body)
+	var importErr *database.TaxImportError
+	if errors.As(err, &importErr) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.ImportTaxRatesOutput{Imported: imported})
+}
+
+// AssignTaxClass godoc
+// @Summary Assign a tax class to a product
+// @Description Set or clear (with null) the tax class of a product
+// @Tags taxes
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param request body dto.AssignTaxClassInput true "Tax class"
+// @Success 200
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/tax_class [put]
+// @Security ApiKeyAuth
+func (h *TaxHandle) AssignTaxClass(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.AssignTaxClassInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product.TaxClassID = nil
+	if input.TaxClassID != nil {
+		class, err := h.TaxDB.FindClassByID(*input.TaxClassID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: "tax class not found"})
+			return
+		}
+		product.TaxClassID = &class.ID
+	}
+
+	if err := h.ProductDB.Update(product); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetProductPrice godoc
+// @Summary Get the price of a product with taxes
+// @Description Break the effective price of a product down into net, tax and gross for a region
+// @Tags taxes
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param region query string true "Jurisdiction, e.g. SP"
+// @Param inclusive query bool false "Whether the catalog price includes taxes (defaults to configuration)"
+// @Param at query string false "Date of the calculation (RFC 3339), defaults to now"
+// @Success 200 {object} dto.ProductPriceOutput
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 422 {object} Error
+// @Failure 500
+// @Router /products/{id}/price [get]
+// @Security ApiKeyAuth
+func (h *TaxHandle) GetProductPrice(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	region := r.URL.Query().Get("region")
+	if region == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrJurisdictionRequired.Error()})
+		return
+	}
+
+	inclusive := h.PricesIncludeTax
+	if value := r.URL.Query().Get("inclusive"); value != "" {
+		inclusive, err = strconv.ParseBool(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	at := h.Now()
+	if value := r.URL.Query().Get("at"); value != "" {
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	if product.TaxClassID == nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrTaxClassRequired.Error()})
+		return
+	}
+
+	promotions, err := h.PromotionDB.FindRunning(at)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	product.ApplyPromotions(promotions, at)
+
+	rates, err := h.TaxDB.FindRates(region, product.TaxClassID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ProductPriceOutput{
+		ProductID:      product.ID.String(),
+		TaxClassID:     product.TaxClassID.String(),
+		Price:          product.Price,
+		EffectivePrice: product.EffectivePrice,
+		TaxBreakdown:   entity.CalculateTax(product.EffectivePrice, inclusive, region, rates, at),
+	})
+}
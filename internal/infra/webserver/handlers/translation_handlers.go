@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/bhyago/crud-products-go/pkg/locale"
+	"github.com/go-chi/chi"
+)
+
+type TranslationHandle struct {
+	ProductDB        database.ProductInterface
+	TranslationDB    database.ProductTranslationInterface
+	SupportedLocales []string
+	DefaultLocale    string
+}
+
+func NewTranslationHandle(productDB database.ProductInterface, translationDB database.ProductTranslationInterface, supportedLocales []string, defaultLocale string) *TranslationHandle {
+	return &TranslationHandle{
+		ProductDB:        productDB,
+		TranslationDB:    translationDB,
+		SupportedLocales: supportedLocales,
+		DefaultLocale:    defaultLocale,
+	}
+}
+
+// GetTranslations godoc
+// @Summary List product translations
+// @Description List the translations of a product
+// @Tags translations
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} []entity.ProductTranslation
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/translations [get]
+// @Security ApiKeyAuth
+func (h *TranslationHandle) GetTranslations(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := h.ProductDB.FindByID(id); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	translations, err := h.TranslationDB.FindByProductID(id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(translations)
+}
+
+// PutTranslation godoc
+// @Summary Create or replace a product translation
+// @Description Create or replace the translation of a product for a locale
+// @Tags translations
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param locale path string true "Locale, e.g. pt-BR"
+// @Param request body dto.UpsertProductTranslationInput true "Translation request"
+// @Success 200 {object} entity.ProductTranslation
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/translations/{locale} [put]
+// @Security ApiKeyAuth
+func (h *TranslationHandle) PutTranslation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	product, err := h.ProductDB.FindByID(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.UpsertProductTranslationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	tag := locale.Normalize(chi.URLParam(r, "locale"))
+	if tag == locale.Normalize(h.DefaultLocale) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: "the default locale is edited on the product itself"})
+		return
+	}
+
+	translation, err := entity.NewProductTranslation(product.ID, tag, input.Name, input.Description)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+
+	if err := h.TranslationDB.Save(translation); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(translation)
+}
+
+// DeleteTranslation godoc
+// @Summary Delete a product translation
+// @Description Delete the translation of a product for a locale
+// @Tags translations
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param locale path string true "Locale, e.g. pt-BR"
+// @Success 200
+// @Failure 404
+// @Router /products/{id}/translations/{locale} [delete]
+// @Security ApiKeyAuth
+func (h *TranslationHandle) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	tag := locale.Normalize(chi.URLParam(r, "locale"))
+
+	if err := h.TranslationDB.Delete(id, tag); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetMissingTranslations godoc
+// @Summary Report missing translations
+// @Description List the products that lack a translation for one or more supported locales
+// @Tags translations
+// @Accept  json
+// @Produce  json
+// @Param locale query string false "Only report this locale"
+// @Success 200 {object} []dto.MissingTranslationsOutput
+// @Failure 500
+// @Router /products/translations/missing [get]
+// @Security ApiKeyAuth
+func (h *TranslationHandle) GetMissingTranslations(w http.ResponseWriter, r *http.Request) {
+	supported := h.SupportedLocales
+	if tag := r.URL.Query().Get("locale"); tag != "" {
+		supported = []string{tag}
+	}
+
+	products, err := h.ProductDB.FindAll(0, 0, "")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.ID.String()
+	}
+	translations, err := h.TranslationDB.FindByProductIDs(ids)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	report := []dto.MissingTranslationsOutput{}
+	for i, product := range products {
+		missing := entity.MissingLocales(supported, h.DefaultLocale, translations[ids[i]])
+		if len(missing) == 0 {
+			continue
+		}
+		report = append(report, dto.MissingTranslationsOutput{
+			ProductID:      ids[i],
+			Name:           product.Name,
+			MissingLocales: missing,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
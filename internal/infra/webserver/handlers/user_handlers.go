@@ -2,38 +2,58 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/bhyago/crud-products-go/internal/dto"
 	"github.com/bhyago/crud-products-go/internal/entity"
 	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/bhyago/crud-products-go/internal/infra/revocation"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/go-chi/chi"
 	"github.com/go-chi/jwtauth"
+	"gorm.io/gorm"
 )
 
 type UserHandle struct {
 	UserDB        database.UserInterface
+	CartDB        database.CartInterface
 	Jwt           *jwtauth.JWTAuth
 	JwtExpiriesIn int
+	// RefreshTokenDB, when set, has a refresh token issued along with each
+	// access token.
+	RefreshTokenDB      database.RefreshTokenInterface
+	RefreshTokenTTL     time.Duration
+	RefreshTokenIdleTTL time.Duration
+	// Denylist, when set, is where logouts revoke access tokens.
+	Denylist *revocation.Denylist
+	Now      func() time.Time
 }
 
 type Error struct {
 	Message string `json:"message"`
 }
 
-func NewUserHandle(db database.UserInterface, JwtExpiriesIn int) *UserHandle {
+func NewUserHandle(db database.UserInterface, cartDB database.CartInterface, JwtExpiriesIn int) *UserHandle {
 	return &UserHandle{
-		UserDB: db,
+		UserDB:              db,
+		CartDB:              cartDB,
+		RefreshTokenTTL:     entity.DefaultRefreshTokenTTL,
+		RefreshTokenIdleTTL: entity.DefaultRefreshTokenIdleTTL,
+		Now:                 time.Now,
 	}
 }
 
 // GetJWT godoc
 // @Summary Get JWT
-// @Description Get JWT
+// @Description Get JWT, with a refresh token to get the next ones from /users/refresh_token. An anonymous cart given in cart_id or X-Cart-ID is merged into the cart of the user
 // @Tags users
 // @Accept  json
 // @Produce  json
 // @Param request body dto.GetJWTInput true "User credentials"
+// @Param X-Cart-ID header string false "Anonymous cart ID"
 // @Success 200 {object} dto.GetJWrOutput
 // @Failure 404 {object} Error
 // @Failure 500 {object} Error
@@ -62,25 +82,275 @@ func (h *UserHandle) GetJWT(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, token, _ := jwt.Encode(map[string]interface{}{
-		"sub": u.ID.String(),
-		"exp": time.Now().Add(time.Duration(jwtExpiriesIn) * time.Second).Unix(),
-	})
+	var acessToken dto.GetJWrOutput
+	sessionID := ""
+	if h.RefreshTokenDB != nil {
+		refreshToken, plain, err := entity.NewRefreshToken(u.ID, h.RefreshTokenTTL, h.RefreshTokenIdleTTL, h.Now())
+		if err == nil {
+			err = h.RefreshTokenDB.Create(refreshToken)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(Error{Message: err.Error()})
+			return
+		}
+		acessToken.RefreshToken = plain
+		sessionID = refreshToken.FamilyID.String()
+	}
+	acessToken.AccessToken = h.accessToken(jwt, jwtExpiriesIn, u, sessionID)
+
+	cartID := user.CartID
+	if cartID == "" {
+		cartID = r.Header.Get(CartHeader)
+	}
+	if cartID != "" {
+		// A cart that cannot be merged must not prevent the login.
+		cart, err := h.CartDB.Merge(cartID, u.ID)
+		if err != nil {
+			log.Printf("merge cart %s: %v", cartID, err)
+		} else {
+			acessToken.CartID = cart.ID.String()
+		}
+	}
 
-	acessToken := dto.GetJWrOutput{AccessToken: token}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(acessToken)
 }
 
+// RefreshToken godoc
+// @Summary Refresh JWT
+// @Description Exchange a refresh token for a new JWT and a new refresh token. A refresh token can be used once: using it again revokes every token rotated from the same login. Refresh tokens expire when unused for REFRESH_TOKEN_IDLE_TTL and REFRESH_TOKEN_TTL after the login
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param request body dto.RefreshTokenInput true "Refresh token"
+// @Success 200 {object} dto.GetJWrOutput
+// @Failure 400
+// @Failure 401 {object} Error
+// @Failure 500
+// @Router /users/refresh_token [post]
+func (h *UserHandle) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	jwt := r.Context().Value("jwt").(*jwtauth.JWTAuth)
+	jwtExpiriesIn := r.Context().Value("jwtExpiresIn").(int)
+	var input dto.RefreshTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.RefreshToken == "" || h.RefreshTokenDB == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, plain, err := h.RefreshTokenDB.Rotate(input.RefreshToken, h.RefreshTokenIdleTTL, h.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = entity.ErrRefreshTokenInvalid
+	}
+	switch {
+	case errors.Is(err, entity.ErrRefreshTokenInvalid), errors.Is(err, entity.ErrRefreshTokenExpired),
+		errors.Is(err, entity.ErrRefreshTokenRevoked), errors.Is(err, entity.ErrRefreshTokenReused):
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	u, err := h.UserDB.FindByID(token.UserID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrRefreshTokenInvalid.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.GetJWrOutput{AccessToken: h.accessToken(jwt, jwtExpiriesIn, u, token.FamilyID.String()), RefreshToken: plain})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the JWT of the request and the refresh tokens of its login
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Success 204
+// @Failure 401
+// @Failure 500
+// @Router /users/logout [post]
+// @Security ApiKeyAuth
+func (h *UserHandle) Logout(w http.ResponseWriter, r *http.Request) {
+	token, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil || token == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userID, err := entityPkg.ParseID(token.Subject())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if h.Denylist != nil && token.JwtID() != "" {
+		if err := h.Denylist.RevokeToken(token.JwtID(), userID, token.Expiration()); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	if sessionID, _ := claims["sid"].(string); sessionID != "" && h.RefreshTokenDB != nil {
+		if err := h.RefreshTokenDB.RevokeFamily(sessionID, h.Now()); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary Log out everywhere
+// @Description Revoke every JWT and refresh token issued to the user of the request, the JWT of the request included
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Success 204
+// @Failure 401
+// @Failure 500
+// @Router /users/logout_all [post]
+// @Security ApiKeyAuth
+func (h *UserHandle) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if err := h.revokeSessions(userID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke the sessions of a user
+// @Description Revoke every JWT and refresh token issued to a user, e.g. when the account is compromised
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /admin/users/{id}/revoke_sessions [post]
+// @Security ApiKeyAuth
+func (h *UserHandle) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	u, err := h.UserDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := h.revokeSessions(u.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUsers godoc
+// @Summary List users
+// @Description List the users with their roles
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} entity.User
+// @Failure 403
+// @Failure 500
+// @Router /admin/users [get]
+// @Security ApiKeyAuth
+func (h *UserHandle) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.UserDB.FindAll()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(users)
+}
+
+// UpdateUserRole godoc
+// @Summary Assign a role
+// @Description Assign a role to a user: viewer, editor or admin. The JWTs of the user are revoked so that the role applies from the next refresh. The last admin cannot be demoted
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param request body dto.UpdateRoleInput true "Role"
+// @Success 200 {object} entity.User
+// @Failure 400 {object} Error
+// @Failure 403
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /admin/users/{id}/role [put]
+// @Security ApiKeyAuth
+func (h *UserHandle) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	var input dto.UpdateRoleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !entity.IsValidRole(input.Role) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrRoleInvalid.Error()})
+		return
+	}
+
+	u, err := h.UserDB.UpdateRole(chi.URLParam(r, "id"), input.Role)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case errors.Is(err, entity.ErrLastAdmin):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if h.Denylist != nil {
+		if err := h.Denylist.RevokeSessions(u.ID); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(u)
+}
+
+func (h *UserHandle) revokeSessions(userID entityPkg.ID) error {
+	if h.Denylist != nil {
+		if err := h.Denylist.RevokeSessions(userID); err != nil {
+			return err
+		}
+	}
+	if h.RefreshTokenDB != nil {
+		return h.RefreshTokenDB.RevokeUser(userID.String(), h.Now())
+	}
+	return nil
+}
+
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user
+// @Description Create a new user with the viewer role
 // @Tags users
 // @Accept  json
 // @Produce  json
 // @Param request body dto.CreateUserInput true "User request"
 // @Success 201
+// @Failure 409 {object} Error
 // @Failure 500
 // @Router /users [post]
 func (h *UserHandle) CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -101,6 +371,11 @@ func (h *UserHandle) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = h.UserDB.Save(u)
+	if errors.Is(err, entity.ErrEmailTaken) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		error := Error{Message: err.Error()}
@@ -110,3 +385,21 @@ func (h *UserHandle) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusCreated)
 }
+
+// accessToken signs a JWT for the user. Its jti identifies it for a logout,
+// and sid, when set, is the family of the refresh tokens of its login.
+func (h *UserHandle) accessToken(jwt *jwtauth.JWTAuth, expiresIn int, u *entity.User, sessionID string) string {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"sub":  u.ID.String(),
+		"jti":  entityPkg.NewID().String(),
+		"iat":  now.Unix(),
+		"exp":  now.Add(time.Duration(expiresIn) * time.Second).Unix(),
+		"role": u.Role,
+	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+	_, token, _ := jwt.Encode(claims)
+	return token
+}
@@ -0,0 +1,475 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+)
+
+type WarehouseHandle struct {
+	WarehouseDB database.WarehouseInterface
+	ProductDB   database.ProductInterface
+	Now         func() time.Time
+}
+
+func NewWarehouseHandle(warehouseDB database.WarehouseInterface, productDB database.ProductInterface) *WarehouseHandle {
+	return &WarehouseHandle{
+		WarehouseDB: warehouseDB,
+		ProductDB:   productDB,
+		Now:         time.Now,
+	}
+}
+
+// CreateWarehouse godoc
+// @Summary Create a warehouse
+// @Description Create a stock location. Codes are unique and stored in upper case
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param request body dto.WarehouseInput true "Warehouse request"
+// @Success 201 {object} entity.Warehouse
+// @Failure 400 {object} Error
+// @Failure 409
+// @Router /warehouses [post]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) CreateWarehouse(w http.ResponseWriter, r *http.Request) {
+	var input dto.WarehouseInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	warehouse, err := entity.NewWarehouse(input.Code, input.Name, input.Address)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.WarehouseDB.Create(warehouse); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(warehouse)
+}
+
+// GetWarehouses godoc
+// @Summary List warehouses
+// @Description List all warehouses by code
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.Warehouse
+// @Failure 500
+// @Router /warehouses [get]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) GetWarehouses(w http.ResponseWriter, r *http.Request) {
+	warehouses, err := h.WarehouseDB.FindAll()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(warehouses)
+}
+
+// GetWarehouse godoc
+// @Summary Get a warehouse
+// @Description Get a warehouse
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Warehouse ID"
+// @Success 200 {object} entity.Warehouse
+// @Failure 404
+// @Router /warehouses/{id} [get]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) GetWarehouse(w http.ResponseWriter, r *http.Request) {
+	warehouse, err := h.WarehouseDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(warehouse)
+}
+
+// UpdateWarehouse godoc
+// @Summary Update a warehouse
+// @Description Update a warehouse
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Warehouse ID"
+// @Param request body dto.WarehouseInput true "Warehouse request"
+// @Success 200 {object} entity.Warehouse
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409
+// @Router /warehouses/{id} [put]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) UpdateWarehouse(w http.ResponseWriter, r *http.Request) {
+	warehouse, err := h.WarehouseDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.WarehouseInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	updated, err := entity.NewWarehouse(input.Code, input.Name, input.Address)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	warehouse.Code = updated.Code
+	warehouse.Name = updated.Name
+	warehouse.Address = updated.Address
+	if err := h.WarehouseDB.Update(warehouse); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(warehouse)
+}
+
+// DeleteWarehouse godoc
+// @Summary Delete a warehouse
+// @Description Delete a warehouse that holds no stock and has no transfer in transit
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Warehouse ID"
+// @Success 200
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /warehouses/{id} [delete]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) DeleteWarehouse(w http.ResponseWriter, r *http.Request) {
+	if err := h.WarehouseDB.Delete(chi.URLParam(r, "id")); err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetWarehouseStock godoc
+// @Summary List the stock of a warehouse
+// @Description List the stock level of every product held in a warehouse
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Warehouse ID"
+// @Success 200 {object} []entity.StockLevel
+// @Failure 404
+// @Failure 500
+// @Router /warehouses/{id}/stock [get]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) GetWarehouseStock(w http.ResponseWriter, r *http.Request) {
+	warehouse, err := h.WarehouseDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	levels, err := h.WarehouseDB.FindStockByWarehouseID(warehouse.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(levels)
+}
+
+// AdjustWarehouseStock godoc
+// @Summary Adjust the stock of a product
+// @Description Add a positive or negative delta to the stock of a product in a warehouse. Stock never goes below zero
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Warehouse ID"
+// @Param request body dto.AdjustStockInput true "Adjustment request"
+// @Success 200 {object} entity.StockLevel
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /warehouses/{id}/stock [post]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) AdjustWarehouseStock(w http.ResponseWriter, r *http.Request) {
+	warehouse, err := h.WarehouseDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var input dto.AdjustStockInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.ProductDB.FindByID(input.ProductID)
+	if err != nil {
+		writeWarehouseError(w, database.ErrProductNotFound)
+		return
+	}
+
+	level, err := h.WarehouseDB.AdjustStock(product.ID, warehouse.ID, input.Delta, h.Now())
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(level)
+}
+
+// CreateTransfer godoc
+// @Summary Transfer stock between warehouses
+// @Description Take stock out of the source warehouse and put it in transit to the destination
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param request body dto.CreateTransferInput true "Transfer request"
+// @Success 201 {object} entity.StockTransfer
+// @Failure 400 {object} Error
+// @Failure 404 {object} Error
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /transfers [post]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateTransferInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.ProductDB.FindByID(input.ProductID)
+	if err != nil {
+		writeWarehouseError(w, database.ErrProductNotFound)
+		return
+	}
+	from, err := h.WarehouseDB.FindByID(input.FromWarehouseID)
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+	to, err := h.WarehouseDB.FindByID(input.ToWarehouseID)
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	transfer, err := entity.NewStockTransfer(product.ID, from.ID, to.ID, input.Quantity)
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+	transfer.CreatedAt = h.Now()
+	if err := h.WarehouseDB.CreateTransfer(transfer); err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// GetTransfers godoc
+// @Summary List transfers
+// @Description List stock transfers, newest first
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param product_id query string false "Product ID"
+// @Param warehouse_id query string false "Source or destination warehouse ID"
+// @Param status query string false "Status: in_transit, received or cancelled"
+// @Success 200 {object} []entity.StockTransfer
+// @Failure 400 {object} Error
+// @Failure 500
+// @Router /transfers [get]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	filter := database.TransferFilter{
+		ProductID:   r.URL.Query().Get("product_id"),
+		WarehouseID: r.URL.Query().Get("warehouse_id"),
+		Status:      r.URL.Query().Get("status"),
+	}
+	if filter.Status != "" && !entity.IsTransferStatus(filter.Status) {
+		writeWarehouseError(w, entity.ErrTransferStatusInvalid)
+		return
+	}
+
+	transfers, err := h.WarehouseDB.FindTransfers(filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transfers)
+}
+
+// GetTransfer godoc
+// @Summary Get a transfer
+// @Description Get a stock transfer
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} entity.StockTransfer
+// @Failure 404
+// @Router /transfers/{id} [get]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) GetTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, err := h.WarehouseDB.FindTransferByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// ReceiveTransfer godoc
+// @Summary Receive a transfer
+// @Description Add the quantity of an in transit transfer to the destination warehouse
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} entity.StockTransfer
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /transfers/{id}/receive [post]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) ReceiveTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, err := h.WarehouseDB.ReceiveTransfer(chi.URLParam(r, "id"), h.Now())
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// CancelTransfer godoc
+// @Summary Cancel a transfer
+// @Description Return the quantity of an in transit transfer to the source warehouse
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} entity.StockTransfer
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /transfers/{id}/cancel [post]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	transfer, err := h.WarehouseDB.CancelTransfer(chi.URLParam(r, "id"), h.Now())
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// GetProductAvailability godoc
+// @Summary Get the availability of a product
+// @Description Break down the stock of a product by warehouse, with the quantity in transit towards each one
+// @Tags warehouses
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} entity.Availability
+// @Failure 404
+// @Failure 500
+// @Router /products/{id}/availability [get]
+// @Security ApiKeyAuth
+func (h *WarehouseHandle) GetProductAvailability(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	warehouses, err := h.WarehouseDB.FindAll()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	levels, err := h.WarehouseDB.FindStockByProductID(product.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	transfers, err := h.WarehouseDB.FindTransfers(database.TransferFilter{ProductID: product.ID.String(), Status: entity.TransferInTransit})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entity.ComputeAvailability(product.ID, warehouses, levels, transfers))
+}
+
+func writeWarehouseError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case errors.Is(err, database.ErrProductNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, entity.ErrInsufficientStock),
+		errors.Is(err, entity.ErrTransferNotInTransit),
+		errors.Is(err, entity.ErrWarehouseNotEmpty):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, entity.ErrSameWarehouse),
+		errors.Is(err, entity.ErrQuantityInvalid),
+		errors.Is(err, entity.ErrStockDeltaInvalid),
+		errors.Is(err, entity.ErrTransferStatusInvalid):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
@@ -0,0 +1,468 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bhyago/crud-products-go/internal/dto"
+	"github.com/bhyago/crud-products-go/internal/entity"
+	"github.com/bhyago/crud-products-go/internal/infra/database"
+	entityPkg "github.com/bhyago/crud-products-go/pkg/entity"
+	"github.com/go-chi/chi"
+)
+
+type WishlistHandle struct {
+	WishlistDB database.WishlistInterface
+	ProductDB  database.ProductInterface
+}
+
+func NewWishlistHandle(wishlistDB database.WishlistInterface, productDB database.ProductInterface) *WishlistHandle {
+	return &WishlistHandle{
+		WishlistDB: wishlistDB,
+		ProductDB:  productDB,
+	}
+}
+
+// CreateWishlist godoc
+// @Summary Create a wishlist
+// @Description Create a named wishlist for the authenticated user
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param request body dto.WishlistInput true "Wishlist request"
+// @Success 201 {object} entity.Wishlist
+// @Failure 400 {object} Error
+// @Failure 401
+// @Failure 500
+// @Router /users/me/wishlists [post]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) CreateWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var input dto.WishlistInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	wishlist, err := entity.NewWishlist(userID, input.Name)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	if err := h.WishlistDB.Save(wishlist); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(wishlist)
+}
+
+// GetWishlists godoc
+// @Summary List my wishlists
+// @Description List the wishlists of the authenticated user
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} []entity.Wishlist
+// @Failure 401
+// @Failure 500
+// @Router /users/me/wishlists [get]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) GetWishlists(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	wishlists, err := h.WishlistDB.FindByUserID(userID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	for i := range wishlists {
+		if err := h.resolve(&wishlists[i]); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(wishlists)
+}
+
+// GetWishlist godoc
+// @Summary Get a wishlist
+// @Description Get a wishlist of the authenticated user. Items whose product was deleted are flagged with product_deleted
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} entity.Wishlist
+// @Failure 404
+// @Failure 500
+// @Router /users/me/wishlists/{id} [get]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+	h.writeWishlist(w, wishlist)
+}
+
+// UpdateWishlist godoc
+// @Summary Rename a wishlist
+// @Description Rename a wishlist of the authenticated user
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Param request body dto.WishlistInput true "Wishlist request"
+// @Success 200 {object} entity.Wishlist
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /users/me/wishlists/{id} [put]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) UpdateWishlist(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.WishlistInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	wishlist.Name = input.Name
+	if err := wishlist.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: err.Error()})
+		return
+	}
+	h.saveWishlist(w, wishlist)
+}
+
+// DeleteWishlist godoc
+// @Summary Delete a wishlist
+// @Description Delete a wishlist of the authenticated user
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Success 200
+// @Failure 404
+// @Failure 500
+// @Router /users/me/wishlists/{id} [delete]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.WishlistDB.Delete(wishlist.ID.String()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// AddWishlistItem godoc
+// @Summary Add a product to a wishlist
+// @Description Add a product, with an optional note, to a wishlist of the authenticated user
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Param request body dto.AddWishlistItemInput true "Item request"
+// @Success 200 {object} entity.Wishlist
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 409 {object} Error
+// @Failure 500
+// @Router /users/me/wishlists/{id}/items [post]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) AddWishlistItem(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.AddWishlistItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.ProductDB.FindByID(input.ProductID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: "product not found: " + input.ProductID})
+		return
+	}
+
+	if err := wishlist.AddItem(product, input.Note); err != nil {
+		writeWishlistError(w, err)
+		return
+	}
+	h.saveWishlist(w, wishlist)
+}
+
+// UpdateWishlistItem godoc
+// @Summary Change the note of a wishlist item
+// @Description Change the note of a product in a wishlist of the authenticated user
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Param product_id path string true "Product ID"
+// @Param request body dto.UpdateWishlistItemInput true "Item request"
+// @Success 200 {object} entity.Wishlist
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /users/me/wishlists/{id}/items/{product_id} [put]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) UpdateWishlistItem(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+	productID, err := entityPkg.ParseID(chi.URLParam(r, "product_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrInvalidID.Error()})
+		return
+	}
+
+	var input dto.UpdateWishlistItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := wishlist.SetNote(productID, input.Note); err != nil {
+		writeWishlistError(w, err)
+		return
+	}
+	h.saveWishlist(w, wishlist)
+}
+
+// RemoveWishlistItem godoc
+// @Summary Remove a product from a wishlist
+// @Description Remove a product from a wishlist of the authenticated user, including products that were deleted
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} entity.Wishlist
+// @Failure 400 {object} Error
+// @Failure 404
+// @Failure 500
+// @Router /users/me/wishlists/{id}/items/{product_id} [delete]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) RemoveWishlistItem(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+	productID, err := entityPkg.ParseID(chi.URLParam(r, "product_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Message: entity.ErrInvalidID.Error()})
+		return
+	}
+
+	if err := wishlist.RemoveItem(productID); err != nil {
+		writeWishlistError(w, err)
+		return
+	}
+	h.saveWishlist(w, wishlist)
+}
+
+// ShareWishlist godoc
+// @Summary Share a wishlist
+// @Description Create a new share token for a wishlist of the authenticated user. The previous token stops working
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} entity.Wishlist
+// @Failure 404
+// @Failure 500
+// @Router /users/me/wishlists/{id}/share [post]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) ShareWishlist(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := wishlist.Share(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.saveWishlist(w, wishlist)
+}
+
+// UnshareWishlist godoc
+// @Summary Stop sharing a wishlist
+// @Description Revoke the share token of a wishlist of the authenticated user
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} entity.Wishlist
+// @Failure 404
+// @Failure 500
+// @Router /users/me/wishlists/{id}/share [delete]
+// @Security ApiKeyAuth
+func (h *WishlistHandle) UnshareWishlist(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.ownWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	wishlist.Unshare()
+	h.saveWishlist(w, wishlist)
+}
+
+// GetSharedWishlist godoc
+// @Summary Get a shared wishlist
+// @Description Read-only view of a wishlist through its share token. No authentication required
+// @Tags wishlists
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Share token"
+// @Success 200 {object} dto.SharedWishlistOutput
+// @Failure 404
+// @Failure 500
+// @Router /wishlists/shared/{token} [get]
+func (h *WishlistHandle) GetSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	wishlist, err := h.WishlistDB.FindByShareToken(chi.URLParam(r, "token"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := h.resolve(wishlist); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.SharedWishlistOutput{
+		Name:      wishlist.Name,
+		Items:     sharedItems(wishlist.Items),
+		UpdatedAt: wishlist.UpdatedAt,
+	})
+}
+
+// sharedItems presents the items to anonymous readers, with only the public
+// fields of their products.
+func sharedItems(items []entity.WishlistItem) []dto.SharedWishlistItemOutput {
+	output := make([]dto.SharedWishlistItemOutput, len(items))
+	for i, item := range items {
+		output[i] = dto.SharedWishlistItemOutput{
+			ProductID:      item.ProductID.String(),
+			ProductName:    item.ProductName,
+			Note:           item.Note,
+			AddedAt:        item.AddedAt,
+			ProductDeleted: item.ProductDeleted,
+		}
+		if item.Product != nil {
+			output[i].Product = &dto.SharedProductOutput{
+				ID:        item.Product.ID.String(),
+				Name:      item.Product.Name,
+				Slug:      item.Product.Slug,
+				Price:     item.Product.Price,
+				Available: item.Product.Available,
+			}
+		}
+	}
+	return output
+}
+
+// ownWishlist loads the wishlist in the URL when it belongs to the
+// authenticated user. Wishlists of other users answer 404.
+func (h *WishlistHandle) ownWishlist(w http.ResponseWriter, r *http.Request) (*entity.Wishlist, bool) {
+	userID, err := userIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	wishlist, err := h.WishlistDB.FindByID(chi.URLParam(r, "id"))
+	if err != nil || wishlist.UserID != userID {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+	return wishlist, true
+}
+
+// resolve attaches the current products to the wishlist items.
+func (h *WishlistHandle) resolve(wishlist *entity.Wishlist) error {
+	ids := make([]string, len(wishlist.Items))
+	for i, item := range wishlist.Items {
+		ids[i] = item.ProductID.String()
+	}
+	found, err := h.ProductDB.FindByIDs(ids)
+	if err != nil {
+		return err
+	}
+	products := make(map[string]entity.Product, len(found))
+	for _, product := range found {
+		products[product.ID.String()] = product
+	}
+	wishlist.Resolve(products)
+	return nil
+}
+
+func (h *WishlistHandle) saveWishlist(w http.ResponseWriter, wishlist *entity.Wishlist) {
+	if err := h.WishlistDB.Save(wishlist); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.writeWishlist(w, wishlist)
+}
+
+func (h *WishlistHandle) writeWishlist(w http.ResponseWriter, wishlist *entity.Wishlist) {
+	if err := h.resolve(wishlist); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(wishlist)
+}
+
+func writeWishlistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrWishlistNoteTooLong):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, entity.ErrWishlistItemNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, entity.ErrWishlistItemDuplicate):
+		w.WriteHeader(http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(Error{Message: err.Error()})
+}
@@ -0,0 +1,197 @@
+// Package barcode validates GTINs and draws them as EAN-8, EAN-13, UPC-A or
+// ITF-14 symbols.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// QuietZone is the number of blank modules drawn on each side of the bars.
+const QuietZone = 11
+
+var ErrInvalid = errors.New("gtin must have 8, 12, 13 or 14 digits and a valid check digit")
+
+// Normalize removes the spaces and hyphens often used to group the digits.
+func Normalize(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+}
+
+// Valid reports whether code is a GTIN-8, GTIN-12 (UPC-A), GTIN-13 (EAN-13)
+// or GTIN-14 with a correct check digit.
+func Valid(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return CheckDigit(code[:len(code)-1]) == code[len(code)-1]
+}
+
+// CheckDigit computes the GS1 check digit of digits: weighting them 3 and 1
+// alternately from the right, it is what brings the sum to a multiple of 10.
+func CheckDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// Variants returns the GTINs equal to code once padded with leading zeros to
+// 14 digits, code included: a UPC-A is also an EAN-13 starting with 0.
+func Variants(code string) []string {
+	if len(code) > 14 {
+		return []string{code}
+	}
+	padded := strings.Repeat("0", 14-len(code)) + code
+	variants := []string{}
+	for _, n := range []int{8, 12, 13, 14} {
+		if strings.Trim(padded[:14-n], "0") == "" {
+			variants = append(variants, padded[14-n:])
+		}
+	}
+	return variants
+}
+
+// EAN digit patterns, one module per character. The R patterns are the
+// complement of the L ones and the G patterns the R ones reversed.
+var (
+	eanL = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	eanG = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	eanR = [10]string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+
+	// ean13Parity tells, from the first digit of an EAN-13, which of the
+	// next six digits use the L or G patterns.
+	ean13Parity = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+
+	// itfWidths are the narrow (N) and wide (W) elements of each digit in
+	// Interleaved 2 of 5.
+	itfWidths = [10]string{"NNWWN", "WNNNW", "NWNNW", "WWNNN", "NNWNW", "WNWNN", "NWWNN", "NNNWW", "WNNWN", "NWNWN"}
+)
+
+// itfWide is the number of modules of a wide ITF-14 element.
+const itfWide = 3
+
+// Encode returns the modules of the symbol of code, true for a bar. GTIN-8
+// is drawn as EAN-8, GTIN-12 as UPC-A, GTIN-13 as EAN-13 and GTIN-14 as
+// ITF-14.
+func Encode(code string) ([]bool, error) {
+	if !Valid(code) {
+		return nil, ErrInvalid
+	}
+	var b strings.Builder
+	switch len(code) {
+	case 8:
+		encodeEAN(&b, code[:4], "LLLL", code[4:])
+	case 12:
+		encodeEAN(&b, code[:6], ean13Parity[0], code[6:])
+	case 13:
+		encodeEAN(&b, code[1:7], ean13Parity[code[0]-'0'], code[7:])
+	case 14:
+		encodeITF(&b, code)
+	}
+
+	modules := make([]bool, b.Len())
+	for i, c := range b.String() {
+		modules[i] = c == '1'
+	}
+	return modules, nil
+}
+
+func encodeEAN(b *strings.Builder, left, parity, right string) {
+	b.WriteString("101")
+	for i := 0; i < len(left); i++ {
+		if parity[i] == 'G' {
+			b.WriteString(eanG[left[i]-'0'])
+		} else {
+			b.WriteString(eanL[left[i]-'0'])
+		}
+	}
+	b.WriteString("01010")
+	for i := 0; i < len(right); i++ {
+		b.WriteString(eanR[right[i]-'0'])
+	}
+	b.WriteString("101")
+}
+
+func encodeITF(b *strings.Builder, code string) {
+	b.WriteString("1010")
+	for i := 0; i < len(code); i += 2 {
+		bars, spaces := itfWidths[code[i]-'0'], itfWidths[code[i+1]-'0']
+		for j := 0; j < 5; j++ {
+			writeITFElement(b, bars[j], '1')
+			writeITFElement(b, spaces[j], '0')
+		}
+	}
+	writeITFElement(b, 'W', '1')
+	b.WriteString("01")
+}
+
+func writeITFElement(b *strings.Builder, width byte, module byte) {
+	n := 1
+	if width == 'W' {
+		n = itfWide
+	}
+	for i := 0; i < n; i++ {
+		b.WriteByte(module)
+	}
+}
+
+// PNG draws modules as a black on white PNG image, each module scale pixels
+// wide, with the quiet zones.
+func PNG(w io.Writer, modules []bool, scale, height int) error {
+	width := (len(modules) + 2*QuietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for i, bar := range modules {
+		if !bar {
+			continue
+		}
+		for x := (QuietZone + i) * scale; x < (QuietZone+i+1)*scale; x++ {
+			for y := 0; y < height; y++ {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// SVG draws modules as an SVG image, each module scale units wide, with the
+// quiet zones. Adjacent bars are merged into one rectangle.
+func SVG(w io.Writer, modules []bool, scale, height int) error {
+	width := (len(modules) + 2*QuietZone) * scale
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, width, height)
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		fmt.Fprintf(&b, `<rect x="%d" width="%d" height="%d" fill="#000"/>`, (QuietZone+start)*scale, (i-start)*scale, height)
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
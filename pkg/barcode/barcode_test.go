@@ -0,0 +1,82 @@
+package barcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	for _, code := range []string{"96385074", "036000291452", "4006381333931", "10012345000017"} {
+		assert.True(t, Valid(code), code)
+	}
+	for _, code := range []string{"", "96385075", "4006381333932", "400638133393", "40063813339a1", "123456789012345"} {
+		assert.False(t, Valid(code), code)
+	}
+	assert.Equal(t, "4006381333931", Normalize(" 4 006381-333931 "))
+}
+
+func TestVariants(t *testing.T) {
+	assert.Equal(t, []string{"036000291452", "0036000291452", "00036000291452"}, Variants("036000291452"))
+	assert.Equal(t, []string{"4006381333931", "04006381333931"}, Variants("4006381333931"))
+	assert.Equal(t, []string{"10012345000017"}, Variants("10012345000017"))
+}
+
+func modules(s string) string {
+	var b strings.Builder
+	bars, _ := Encode(s)
+	for _, bar := range bars {
+		if bar {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+func TestEncode(t *testing.T) {
+	ean13 := modules("4006381333931")
+	assert.Len(t, ean13, 95)
+	// 4 uses the LGLLGG parity: the first 0 is drawn with L, the second with G.
+	assert.Equal(t, "101"+"0001101"+"0100111", ean13[:17])
+	assert.Equal(t, "01010", ean13[45:50])
+	assert.Equal(t, "1100110"+"101", ean13[85:])
+
+	assert.Len(t, modules("96385074"), 67)
+	// A UPC-A is the EAN-13 of the same digits preceded by 0.
+	assert.Equal(t, modules("0036000291452"), modules("036000291452"))
+
+	// ITF-14: start, 7 pairs of 5 bars and 5 spaces, 2 of each wide of 3
+	// modules, and stop.
+	itf := modules("10012345000017")
+	assert.Len(t, itf, 4+7*2*(3+2*3)+5)
+	assert.True(t, strings.HasPrefix(itf, "1010"))
+	assert.True(t, strings.HasSuffix(itf, "11101"))
+
+	_, err := Encode("4006381333932")
+	assert.Equal(t, ErrInvalid, err)
+}
+
+func TestRender(t *testing.T) {
+	bars, _ := Encode("96385074")
+
+	var buf bytes.Buffer
+	assert.Nil(t, PNG(&buf, bars, 2, 50))
+	img, err := png.Decode(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, (67+2*QuietZone)*2, img.Bounds().Dx())
+	r, _, _, _ := img.At(QuietZone*2, 10).RGBA()
+	assert.Zero(t, r)
+	r, _, _, _ = img.At(0, 10).RGBA()
+	assert.NotZero(t, r)
+
+	buf.Reset()
+	assert.Nil(t, SVG(&buf, bars, 1, 50))
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="89" height="50"`))
+	assert.Contains(t, svg, `<rect x="11" width="1" height="50" fill="#000"/>`)
+}
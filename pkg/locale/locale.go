@@ -0,0 +1,120 @@
+package locale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Normalize returns the canonical form of a language tag, e.g. "pt_br" -> "pt-BR".
+func Normalize(tag string) string {
+	parts := strings.FieldsFunc(strings.TrimSpace(tag), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// IsValid reports whether tag looks like a BCP 47 language tag.
+func IsValid(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for i, part := range strings.Split(Normalize(tag), "-") {
+		if len(part) == 0 || len(part) > 8 || (i == 0 && (len(part) < 2 || len(part) > 3)) {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Fallbacks returns the chain of tags to try for a tag, from the most to the
+// least specific: "pt-BR" -> ["pt-BR", "pt"].
+func Fallbacks(tag string) []string {
+	tag = Normalize(tag)
+	if tag == "" {
+		return nil
+	}
+	chain := []string{tag}
+	for i := strings.LastIndex(tag, "-"); i > 0; i = strings.LastIndex(tag, "-") {
+		tag = tag[:i]
+		chain = append(chain, tag)
+	}
+	return chain
+}
+
+// ParseAcceptLanguage returns the tags of an Accept-Language header ordered
+// by quality. Wildcards and tags with q=0 are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, item := range strings.Split(header, ",") {
+		fields := strings.Split(item, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" || !IsValid(tag) {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: Normalize(tag), q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, t.tag)
+	}
+	return result
+}
+
+// Negotiate picks the best locale in available for an Accept-Language header,
+// walking the fallback chain of each preferred tag in order. It returns false
+// when none of the preferences can be served, in which case the caller should
+// use its default locale.
+func Negotiate(header string, available []string) (string, bool) {
+	index := make(map[string]string, len(available))
+	for _, tag := range available {
+		index[strings.ToLower(Normalize(tag))] = tag
+	}
+	for _, preferred := range ParseAcceptLanguage(header) {
+		for _, tag := range Fallbacks(preferred) {
+			if match, ok := index[strings.ToLower(tag)]; ok {
+				return match, true
+			}
+		}
+	}
+	return "", false
+}
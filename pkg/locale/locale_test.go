@@ -0,0 +1,31 @@
+package locale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "pt-BR", Normalize("pt_br"))
+	assert.Equal(t, "zh-Hant-TW", Normalize("ZH-hant-tw"))
+}
+
+func TestFallbacks(t *testing.T) {
+	assert.Equal(t, []string{"pt-BR", "pt"}, Fallbacks("pt-BR"))
+	assert.Equal(t, []string{"zh-Hant-TW", "zh-Hant", "zh"}, Fallbacks("zh-Hant-TW"))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tags := ParseAcceptLanguage("en;q=0.5, pt-BR, *;q=0.1, fr;q=0")
+	assert.Equal(t, []string{"pt-BR", "en"}, tags)
+}
+
+func TestNegotiate(t *testing.T) {
+	tag, ok := Negotiate("pt-BR,en;q=0.8", []string{"en-US", "pt"})
+	assert.True(t, ok)
+	assert.Equal(t, "pt", tag)
+
+	_, ok = Negotiate("fr", []string{"en-US", "pt"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,27 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	html, err := Render("# Title\n\nSome *text*")
+	assert.Nil(t, err)
+	assert.Equal(t, "<h1>Title</h1>\n<p>Some <em>text</em></p>\n", html)
+}
+
+func TestRenderStripsScripts(t *testing.T) {
+	html, err := Render("<script>alert(1)</script><img src=x onerror=alert(1)>hello")
+	assert.Nil(t, err)
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "onerror")
+}
+
+func TestRenderSanitizesLinks(t *testing.T) {
+	html, err := Render("[bad](javascript:alert(1)) [good](https://example.com)")
+	assert.Nil(t, err)
+	assert.NotContains(t, html, "javascript:")
+	assert.Contains(t, html, `<a href="https://example.com" rel="nofollow noreferrer noopener" target="_blank">good</a>`)
+}
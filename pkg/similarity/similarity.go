@@ -0,0 +1,58 @@
+// Package similarity compares short names, such as product names, with
+// trigrams.
+package similarity
+
+import (
+	"strings"
+
+	"github.com/bhyago/crud-products-go/pkg/translit"
+)
+
+// Normalize lowercases and transliterates s and keeps only its letters and
+// digits, so that "iPhone 15" and "Iphone15 " compare equal.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(translit.Fold(s)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Trigrams returns the set of trigrams of the normalized s, padded so that
+// its start and end weigh more.
+func Trigrams(s string) map[string]struct{} {
+	normalized := Normalize(s)
+	trigrams := make(map[string]struct{})
+	if normalized == "" {
+		return trigrams
+	}
+
+	padded := []rune("  " + normalized + " ")
+	for i := 0; i+3 <= len(padded); i++ {
+		trigrams[string(padded[i:i+3])] = struct{}{}
+	}
+	return trigrams
+}
+
+// Similarity returns the share of trigrams a and b have in common, from 0
+// for nothing in common to 1 for names equal once normalized.
+func Similarity(a, b string) float64 {
+	return Jaccard(Trigrams(a), Trigrams(b))
+}
+
+// Jaccard returns the size of the intersection of the sets over the size of
+// their union.
+func Jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range a {
+		if _, ok := b[trigram]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
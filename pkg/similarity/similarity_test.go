@@ -0,0 +1,22 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "iphone15", Normalize("iPhone 15"))
+	assert.Equal(t, "iphone15", Normalize("Iphone15 "))
+	assert.Equal(t, "cafe", Normalize("Café!"))
+	assert.Equal(t, "", Normalize("  -- "))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("iPhone 15", "Iphone15 "))
+	assert.InDelta(t, 0.615, Similarity("iPhone 15", "iPhone 15 Pro"), 0.001)
+	assert.Less(t, Similarity("Galaxy S23", "Galaxy S24"), 0.7)
+	assert.Equal(t, 0.0, Similarity("iPhone", "Kindle"))
+	assert.Equal(t, 0.0, Similarity("", ""))
+}
@@ -0,0 +1,60 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bhyago/crud-products-go/pkg/translit"
+)
+
+// MaxLength is the maximum length of a generated slug, suffix included.
+const MaxLength = 80
+
+// Fallback is used when a name has no character that can go in a slug.
+const Fallback = "product"
+
+// Make builds a lowercase, transliterated, hyphen-separated slug from s.
+func Make(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(translit.Fold(s)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > MaxLength {
+		slug = truncate(slug, MaxLength)
+	}
+	if slug == "" {
+		return Fallback
+	}
+	return slug
+}
+
+// WithSuffix returns the n-th candidate for base: base, base-2, base-3...
+func WithSuffix(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	suffix := "-" + strconv.Itoa(n)
+	return truncate(base, MaxLength-len(suffix)) + suffix
+}
+
+func truncate(slug string, max int) string {
+	if len(slug) <= max {
+		return slug
+	}
+	slug = slug[:max]
+	if i := strings.LastIndex(slug, "-"); i > 0 {
+		slug = slug[:i]
+	}
+	return strings.TrimSuffix(slug, "-")
+}
@@ -0,0 +1,27 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	assert.Equal(t, "tenis-de-corrida-acai", Make("Tênis de Corrida — Açaí!"))
+	assert.Equal(t, "grosse-strasse", Make("  Große Straße  "))
+	assert.Equal(t, "iphone-15", Make("iPhone 15"))
+	assert.Equal(t, Fallback, Make("日本"))
+}
+
+func TestMakeTruncatesAtWordBoundary(t *testing.T) {
+	slug := Make(strings.Repeat("word ", 30))
+	assert.LessOrEqual(t, len(slug), MaxLength)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+	assert.True(t, strings.HasSuffix(slug, "word"))
+}
+
+func TestWithSuffix(t *testing.T) {
+	assert.Equal(t, "shoe", WithSuffix("shoe", 1))
+	assert.Equal(t, "shoe-3", WithSuffix("shoe", 3))
+}
@@ -0,0 +1,179 @@
+// Package tfidf indexes short texts, such as product descriptions, as TF-IDF
+// vectors and compares them with the cosine similarity. The index is updated
+// one document at a time.
+package tfidf
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/bhyago/crud-products-go/pkg/translit"
+)
+
+// driftRatio is the change of the number of documents from which the cached
+// vectors, weighted with the old count, are all recomputed.
+const driftRatio = 0.1
+
+// stopWords are the common English words carrying no meaning of their own.
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {}, "for": {}, "from": {},
+	"in": {}, "is": {}, "it": {}, "its": {}, "of": {}, "on": {}, "or": {}, "that": {}, "the": {}, "this": {},
+	"to": {}, "with": {},
+}
+
+// Vector maps terms to their weights. The vectors of the index have a length
+// of 1.
+type Vector map[string]float64
+
+// Tokenize lowercases and transliterates s and splits it into words of
+// letters and digits, stop words and single characters left out.
+func Tokenize(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(translit.Fold(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for _, word := range words {
+		if _, ok := stopWords[word]; ok || len(word) < 2 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// Index holds the term counts of its documents. Their vectors are computed
+// when first needed and cached until a document sharing one of their terms
+// changes, or the number of documents drifts by more than a tenth. An Index
+// is not safe for concurrent use.
+type Index struct {
+	terms    map[string]map[string]int
+	postings map[string]map[string]struct{}
+	vectors  map[string]Vector
+	cachedN  int
+}
+
+func New() *Index {
+	return &Index{
+		terms:    make(map[string]map[string]int),
+		postings: make(map[string]map[string]struct{}),
+		vectors:  make(map[string]Vector),
+	}
+}
+
+// Len returns the number of documents.
+func (ix *Index) Len() int {
+	return len(ix.terms)
+}
+
+// Put adds the document id with text, or replaces its text.
+func (ix *Index) Put(id, text string) {
+	ix.Remove(id)
+
+	counts := make(map[string]int)
+	for _, token := range Tokenize(text) {
+		counts[token]++
+	}
+	ix.terms[id] = counts
+	for term := range counts {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[string]struct{})
+		}
+		ix.postings[term][id] = struct{}{}
+	}
+	ix.invalidate(counts)
+}
+
+// Remove removes the document id, if indexed.
+func (ix *Index) Remove(id string) {
+	counts, ok := ix.terms[id]
+	if !ok {
+		return
+	}
+	delete(ix.terms, id)
+	delete(ix.vectors, id)
+	for term := range counts {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	ix.invalidate(counts)
+}
+
+// invalidate drops the cached vectors whose weights changed with the
+// document frequencies of terms.
+func (ix *Index) invalidate(terms map[string]int) {
+	if math.Abs(float64(len(ix.terms)-ix.cachedN)) > driftRatio*float64(ix.cachedN) {
+		ix.vectors = make(map[string]Vector)
+		ix.cachedN = len(ix.terms)
+		return
+	}
+	for term := range terms {
+		for id := range ix.postings[term] {
+			delete(ix.vectors, id)
+		}
+	}
+}
+
+// Vector returns the vector of the document id, nil if it is not indexed.
+// Terms weigh 1 + log of their count, times their smoothed inverse document
+// frequency.
+func (ix *Index) Vector(id string) Vector {
+	if vector, ok := ix.vectors[id]; ok {
+		return vector
+	}
+	counts, ok := ix.terms[id]
+	if !ok {
+		return nil
+	}
+	if len(ix.vectors) == 0 {
+		ix.cachedN = len(ix.terms)
+	}
+
+	vector := make(Vector, len(counts))
+	var norm float64
+	for term, count := range counts {
+		idf := math.Log(float64(1+ix.cachedN)/float64(1+len(ix.postings[term]))) + 1
+		weight := (1 + math.Log(float64(count))) * idf
+		vector[term] = weight
+		norm += weight * weight
+	}
+	norm = math.Sqrt(norm)
+	for term := range vector {
+		vector[term] /= norm
+	}
+	ix.vectors[id] = vector
+	return vector
+}
+
+// Similar returns the cosine similarity of the document id with each of the
+// documents sharing a term with it.
+func (ix *Index) Similar(id string) map[string]float64 {
+	vector := ix.Vector(id)
+	scores := make(map[string]float64)
+	for term := range vector {
+		for other := range ix.postings[term] {
+			if other == id {
+				continue
+			}
+			if _, ok := scores[other]; !ok {
+				scores[other] = Cosine(vector, ix.Vector(other))
+			}
+		}
+	}
+	return scores
+}
+
+// Cosine returns the cosine similarity of vectors of length 1, from 0 for no
+// term in common to 1 for the same terms in the same proportions.
+func Cosine(a, b Vector) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return math.Min(dot, 1)
+}
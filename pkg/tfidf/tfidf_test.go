@@ -0,0 +1,39 @@
+package tfidf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"cafe", "espresso", "machine", "15", "bar"}, Tokenize("Café & Espresso machine, 15 bar"))
+	assert.Equal(t, []string{"1l", "tank"}, Tokenize("with a 1L tank"))
+	assert.Empty(t, Tokenize("the - a"))
+}
+
+func TestIndexSimilar(t *testing.T) {
+	ix := New()
+	ix.Put("espresso", "Espresso machine with milk frother")
+	ix.Put("capsule", "Capsule espresso machine")
+	ix.Put("grinder", "Coffee grinder")
+	ix.Put("kettle", "Electric kettle")
+
+	scores := ix.Similar("espresso")
+	assert.Len(t, scores, 1)
+	assert.Greater(t, scores["capsule"], 0.3)
+	assert.InDelta(t, 1.0, Cosine(ix.Vector("kettle"), ix.Vector("kettle")), 1e-9)
+	assert.Nil(t, ix.Vector("missing"))
+
+	// A grinder now mentioning espresso is found, and the vector of the
+	// capsule machine reflects that espresso is more common.
+	before := ix.Vector("capsule")["espresso"]
+	ix.Put("grinder", "Coffee grinder for espresso")
+	assert.Contains(t, ix.Similar("espresso"), "grinder")
+	assert.Less(t, ix.Vector("capsule")["espresso"], before)
+
+	ix.Remove("capsule")
+	assert.Equal(t, 3, ix.Len())
+	assert.NotContains(t, ix.Similar("espresso"), "capsule")
+	assert.Empty(t, ix.Similar("kettle"))
+}
@@ -0,0 +1,50 @@
+// Package units converts weights between kilograms and pounds, and lengths
+// between centimeters and inches.
+package units
+
+const (
+	Kilogram   = "kg"
+	Pound      = "lb"
+	Centimeter = "cm"
+	Inch       = "in"
+)
+
+// Exact definitions of the pound and the inch in metric units.
+const (
+	kilogramsPerPound  = 0.45359237
+	centimetersPerInch = 2.54
+)
+
+// IsWeight reports whether unit is a supported weight unit.
+func IsWeight(unit string) bool {
+	return unit == Kilogram || unit == Pound
+}
+
+// IsLength reports whether unit is a supported length unit.
+func IsLength(unit string) bool {
+	return unit == Centimeter || unit == Inch
+}
+
+// ToKilograms converts a weight in unit to kilograms.
+func ToKilograms(value float64, unit string) float64 {
+	if unit == Pound {
+		return value * kilogramsPerPound
+	}
+	return value
+}
+
+// FromKilograms converts a weight in kilograms to unit.
+func FromKilograms(value float64, unit string) float64 {
+	if unit == Pound {
+		return value / kilogramsPerPound
+	}
+	return value
+}
+
+// ToCentimeters converts a length in unit to centimeters.
+func ToCentimeters(value float64, unit string) float64 {
+	if unit == Inch {
+		return value * centimetersPerInch
+	}
+	return value
+}
@@ -0,0 +1,26 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeights(t *testing.T) {
+	assert.True(t, IsWeight(Kilogram))
+	assert.True(t, IsWeight(Pound))
+	assert.False(t, IsWeight(Centimeter))
+
+	assert.Equal(t, 2.0, ToKilograms(2, Kilogram))
+	assert.InDelta(t, 0.45359237, ToKilograms(1, Pound), 1e-12)
+	assert.InDelta(t, 2.20462262, FromKilograms(1, Pound), 1e-8)
+	assert.InDelta(t, 3.5, FromKilograms(ToKilograms(3.5, Pound), Pound), 1e-12)
+}
+
+func TestLengths(t *testing.T) {
+	assert.True(t, IsLength(Inch))
+	assert.False(t, IsLength(Kilogram))
+
+	assert.Equal(t, 10.0, ToCentimeters(10, Centimeter))
+	assert.InDelta(t, 25.4, ToCentimeters(10, Inch), 1e-12)
+}